@@ -26,12 +26,14 @@ import (
 )
 
 type Server struct {
-	Zap     Zap           `mapstructure:"zap"    json:"zap" yaml:"zap"`
-	Mysql   Mysql         `mapstructure:"mysql"  json:"mysql" yaml:"mysql"`
-	Casbin  models.Casbin `mapstructure:"casbin" json:"casbin" yaml:"casbin"`
-	System  System        `mapstructure:"system" json:"system" yaml:"system"`
-	Redis   Redis         `mapstructure:"redis"  json:"redis" yaml:"redis"`
-	Crontab Crontab       `mapstructure:"crontab" json:"crontab" yaml:"crontab"`
+	Zap       Zap           `mapstructure:"zap"    json:"zap" yaml:"zap"`
+	Mysql     Mysql         `mapstructure:"mysql"  json:"mysql" yaml:"mysql"`
+	Casbin    models.Casbin `mapstructure:"casbin" json:"casbin" yaml:"casbin"`
+	System    System        `mapstructure:"system" json:"system" yaml:"system"`
+	Redis     Redis         `mapstructure:"redis"  json:"redis" yaml:"redis"`
+	Crontab   Crontab       `mapstructure:"crontab" json:"crontab" yaml:"crontab"`
+	CloudSync CloudSync     `mapstructure:"cloud-sync" json:"cloudSync" yaml:"cloud-sync"`
+	Record    Record        `mapstructure:"record" json:"record" yaml:"record"`
 }
 
 type contactKey struct {
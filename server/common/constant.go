@@ -19,4 +19,6 @@ package common
 const (
 	ConfigEnv  = "GVA_CONFIG"
 	ConfigFile = "etc/config.yaml"
+	// SecretKeyEnv 加密存储凭证的密钥, 优先于配置文件中的system.secret-key
+	SecretKeyEnv = "KUBESPACE_SECRET_KEY"
 )
@@ -17,5 +17,11 @@ limitations under the License.
 package common
 
 type Crontab struct {
-	AliYun string `mapstructure:"aliyun" json:"aliyun" yaml:"aliyun"`
+	AliYun  string `mapstructure:"aliyun" json:"aliyun" yaml:"aliyun"`
+	Tencent string `mapstructure:"tencent" json:"tencent" yaml:"tencent"`
+}
+
+type CloudSync struct {
+	// DestroyedRetainHours 云上已释放的实例标记为Destroyed后保留的小时数, 超过后软删除, 0表示只标记不删除
+	DestroyedRetainHours int `mapstructure:"destroyed-retain-hours" json:"destroyedRetainHours" yaml:"destroyed-retain-hours"`
 }
@@ -30,11 +30,22 @@ func MysqlTables(db *gorm.DB) {
 		models.Role{},
 		models.Dept{},
 		models.K8SCluster{},
+		models.K8STerminalRecord{},
 		//models.ClusterVersion{},
 		cmdb.CloudPlatform{},
 		cmdb.VirtualMachine{},
+		cmdb.CloudSyncRecord{},
+		cmdb.CloudDisk{},
+		cmdb.CloudLoadBalancer{},
+		cmdb.CloudDatabase{},
+		cmdb.CloudVpc{},
+		cmdb.CloudSubnet{},
 		cmdb.TreeMenu{},
 		cmdb.SSHRecord{},
+		cmdb.SSHCommand{},
+		cmdb.SSHCommandRule{},
+		cmdb.SSHCommandBlock{},
+		cmdb.SSHCredential{},
 		cmdb.SSHGlobalConfig{},
 		//
 
@@ -0,0 +1,25 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+// Record 终端会话录像的存储配置
+type Record struct {
+	// Storage 录像存储后端, db存储在数据库longblob字段中, local存储在本地磁盘, 为空时使用db
+	Storage string `mapstructure:"storage" json:"storage" yaml:"storage"`
+	// Dir local存储的根目录
+	Dir string `mapstructure:"dir" json:"dir" yaml:"dir"`
+}
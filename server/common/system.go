@@ -17,7 +17,8 @@ limitations under the License.
 package common
 
 type System struct {
-	Env    string `mapstructure:"env" json:"env" yaml:"env"`
-	Addr   int    `mapstructure:"addr" json:"addr" yaml:"addr"`
-	DbType string `mapstructure:"db-type" json:"dbType" yaml:"db-type"`
+	Env       string `mapstructure:"env" json:"env" yaml:"env"`
+	Addr      int    `mapstructure:"addr" json:"addr" yaml:"addr"`
+	DbType    string `mapstructure:"db-type" json:"dbType" yaml:"db-type"`
+	SecretKey string `mapstructure:"secret-key" json:"secretKey" yaml:"secret-key"` // 加密存储凭证的密钥, 环境变量KUBESPACE_SECRET_KEY优先
 }
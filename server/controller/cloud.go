@@ -19,6 +19,7 @@ package controller
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 	"kubespace/server/common"
 	"kubespace/server/controller/response"
@@ -27,14 +28,11 @@ import (
 	"kubespace/server/models/cmdb"
 	"kubespace/server/models/request"
 	"kubespace/server/services"
-)
-
-var (
-	pageInfo request.PageInfo
-	account  cmdb.CloudPlatform
+	"kubespace/server/tasks"
 )
 
 func ListPlatform(c *gin.Context) {
+	var pageInfo request.PageInfo
 	_ = c.ShouldBindJSON(&pageInfo)
 	err, list, total := services.ListPlatform(pageInfo)
 	if err != nil {
@@ -53,48 +51,294 @@ func ListPlatform(c *gin.Context) {
 	}
 }
 
-// CloudPlatformAccount 云平台账号
-func CloudPlatformAccount(c *gin.Context) {
-	_ = c.ShouldBindJSON(&account)
+// ListCloudSyncRecord 云资产同步记录
+func ListCloudSyncRecord(c *gin.Context) {
+	var query request.CloudSyncRecordQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	err, list, total := services.ListCloudSyncRecord(query)
+	if err != nil {
+		common.LOG.Error("获取云资产同步记录失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("获取云资产同步记录失败，%v", err), c)
+		return
+	}
+	response.OkWithData(response.PageResult{
+		Data:  list,
+		Total: total,
+		Page:  query.Page,
+		Size:  query.PageSize,
+	}, c)
+}
 
-	// 校验云厂商客户端
-	_, err := cloudvendor.GetVendorClient(&account)
+// listCloudResource 云资源列表接口的公共处理
+func listCloudResource(c *gin.Context, kind string, list func(request.CloudResourceQuery) (error, interface{}, int64)) {
+	var query request.CloudResourceQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	err, data, total := list(query)
 	if err != nil {
-		response.FailWithMessage(500, fmt.Sprintf("AccountVerify GetVendorClient failed，%v", err), c)
+		common.LOG.Error(fmt.Sprintf("获取%s列表失败", kind), zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("获取%s列表失败，%v", kind, err), c)
+		return
+	}
+	response.OkWithData(response.PageResult{
+		Data:  data,
+		Total: total,
+		Page:  query.Page,
+		Size:  query.PageSize,
+	}, c)
+}
+
+// ListCloudDisk 云硬盘列表
+func ListCloudDisk(c *gin.Context) {
+	listCloudResource(c, "云硬盘", services.ListCloudDisk)
+}
+
+// ListCloudLoadBalancer 负载均衡列表
+func ListCloudLoadBalancer(c *gin.Context) {
+	listCloudResource(c, "负载均衡", services.ListCloudLoadBalancer)
+}
+
+// ListCloudDatabase 云数据库列表
+func ListCloudDatabase(c *gin.Context) {
+	listCloudResource(c, "云数据库", services.ListCloudDatabase)
+}
+
+// ListCloudVpc VPC列表
+func ListCloudVpc(c *gin.Context) {
+	listCloudResource(c, "VPC", services.ListCloudVpc)
+}
+
+// ListCloudSubnet 子网列表
+func ListCloudSubnet(c *gin.Context) {
+	listCloudResource(c, "子网", services.ListCloudSubnet)
+}
+
+// verifyCloudAccount 调用GetRegions校验云账号凭证
+func verifyCloudAccount(conf *cmdb.CloudPlatform) ([]*cmdb.Region, error) {
+	client, err := cloudvendor.GetVendorClient(conf)
+	if err != nil {
+		return nil, err
+	}
+	regions, err := client.GetRegions()
+	if err != nil {
+		return nil, fmt.Errorf("AccessKey校验失败: %v", err)
+	}
+	return regions, nil
+}
+
+// checkSyncCron 校验同步周期, 为空时使用默认周期
+func checkSyncCron(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	if _, err := cron.ParseStandard(spec); err != nil {
+		return fmt.Errorf("同步周期格式错误: %v", err)
+	}
+	return nil
+}
+
+// CloudPlatformAccount 创建云平台账号
+func CloudPlatformAccount(c *gin.Context) {
+	var req request.CloudAccountCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	account := services.NewCloudAccount(&req)
+	if account.Name == "" || account.AccessKey == "" || account.SecretKey == "" {
+		response.FailWithMessage(response.ParamError, "名称、AccessKey、SecretKey不能为空", c)
+		return
+	}
+	if err := checkSyncCron(account.SyncCron); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	// 校验云厂商客户端和AccessKey
+	if _, err := verifyCloudAccount(account); err != nil {
+		response.FailWithMessage(500, fmt.Sprintf("AccountVerify failed，%v", err), c)
 		return
 	}
-	// TODO 校验AccessKey
 
 	// 创建云账号
-	err1 := services.CreateCloudAccount(&account)
+	err1 := services.CreateCloudAccount(account)
 	if err1 != nil {
 		response.FailWithMessage(500, fmt.Sprintf("创建云平台账号异常，%v", err1), c)
 		return
 	}
 
-	// 开启协程，后台同步ecs
-	taskChan := make(chan *cmdb.CloudPlatform)
-	SyncCloudResource(taskChan)
+	// 投递同步任务，后台同步ecs，并刷新账号的同步调度
+	tasks.NotifyCloudAccountChanged()
+	if err := tasks.EnqueueCloudSync(account.ID, cloudsync.TriggerManual); err != nil {
+		common.LOG.Error("投递云资产同步任务失败", zap.Any("err", err))
+	}
 
 	response.OkWithDetailed("null", "添加成功, 任务正在后台同步云资源", c)
 	return
 }
 
-// SyncCloudResource 同步云资源
-func SyncCloudResource(taskChan chan *cmdb.CloudPlatform) {
-	go func() {
-		if task, ok := <-taskChan; ok {
-			switch task.Type {
-			case "aliyun":
-				cloudsync.SyncAliYunHost(task)
-			case "tencent":
-				return
-			case "aws":
-				return
-			default:
-				common.LOG.Error(fmt.Sprintf("unknown resource type:%v, ignore it!", task.Type))
-			}
+// GetCloudAccount 云平台账号详情, 凭证脱敏
+func GetCloudAccount(c *gin.Context) {
+	var req request.CloudAccountId
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	account, err := services.GetCloudAccountDetail(req.ID)
+	if err != nil {
+		response.FailWithMessage(500, err.Error(), c)
+		return
+	}
+	response.OkWithData(account, c)
+}
+
+// UpdateCloudAccount 更新云平台账号
+func UpdateCloudAccount(c *gin.Context) {
+	var req request.CloudAccountUpdate
+	if err := CheckParams(c, &req); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if err := checkSyncCron(req.SyncCron); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	account, err := services.GetCloudAccount(req.ID)
+	if err != nil {
+		response.FailWithMessage(500, err.Error(), c)
+		return
+	}
+
+	// 修改凭证时需要重新校验
+	if req.AccessKey != "" || req.SecretKey != "" {
+		if req.AccessKey == "" || req.SecretKey == "" {
+			response.FailWithMessage(response.ParamError, "修改凭证时AccessKey、SecretKey都不能为空", c)
+			return
+		}
+		conf := cmdb.CloudPlatform{Type: account.Type, AccessKey: req.AccessKey, SecretKey: req.SecretKey}
+		if _, err := verifyCloudAccount(&conf); err != nil {
+			response.FailWithMessage(500, fmt.Sprintf("AccountVerify failed，%v", err), c)
+			return
+		}
+	}
+
+	if err := services.UpdateCloudAccount(&req); err != nil {
+		response.FailWithMessage(500, fmt.Sprintf("更新云平台账号异常，%v", err), c)
+		return
+	}
+	tasks.NotifyCloudAccountChanged()
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteCloudAccount 删除云平台账号
+func DeleteCloudAccount(c *gin.Context) {
+	var req request.CloudAccountId
+	if err := CheckParams(c, &req); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if err := services.DeleteCloudAccount(req.ID); err != nil {
+		response.FailWithMessage(500, fmt.Sprintf("删除云平台账号异常，%v", err), c)
+		return
+	}
+	tasks.NotifyCloudAccountChanged()
+	response.OkWithMessage("删除成功", c)
+}
+
+// EnableCloudAccount 启用云平台账号
+func EnableCloudAccount(c *gin.Context) {
+	setCloudAccountEnable(c, true)
+}
+
+// DisableCloudAccount 禁用云平台账号, 禁用后不再同步
+func DisableCloudAccount(c *gin.Context) {
+	setCloudAccountEnable(c, false)
+}
+
+func setCloudAccountEnable(c *gin.Context, enable bool) {
+	var req request.CloudAccountId
+	if err := CheckParams(c, &req); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if _, err := services.GetCloudAccount(req.ID); err != nil {
+		response.FailWithMessage(500, err.Error(), c)
+		return
+	}
+	if err := services.SetCloudAccountEnable(req.ID, enable); err != nil {
+		response.FailWithMessage(500, fmt.Sprintf("更新云平台账号状态异常，%v", err), c)
+		return
+	}
+	tasks.NotifyCloudAccountChanged()
+	response.OkWithMessage("操作成功", c)
+}
+
+// VerifyCloudAccount 校验云账号凭证, 返回账号可用的地域
+func VerifyCloudAccount(c *gin.Context) {
+	var req request.CloudAccountVerify
+	if err := CheckParams(c, &req); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+
+	conf := &cmdb.CloudPlatform{Type: req.Type, AccessKey: req.AccessKey, SecretKey: req.SecretKey}
+	if req.ID != 0 {
+		account, err := services.GetCloudAccount(req.ID)
+		if err != nil {
+			response.FailWithMessage(500, err.Error(), c)
+			return
 		}
-	}()
-	taskChan <- &account
+		conf = account
+	} else if req.Type == "" || req.AccessKey == "" || req.SecretKey == "" {
+		response.FailWithMessage(response.ParamError, "类型、AccessKey、SecretKey不能为空", c)
+		return
+	}
+
+	regions, err := verifyCloudAccount(conf)
+	if err != nil {
+		response.FailWithMessage(500, err.Error(), c)
+		return
+	}
+	response.OkWithData(regions, c)
+}
+
+// SyncCloudAccount 立即同步云账号
+func SyncCloudAccount(c *gin.Context) {
+	var req request.CloudAccountId
+	if err := CheckParams(c, &req); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+
+	platform, err := services.GetCloudAccount(req.ID)
+	if err != nil {
+		response.FailWithMessage(500, err.Error(), c)
+		return
+	}
+	if !platform.Enable {
+		response.FailWithMessage(500, "云账号未启用", c)
+		return
+	}
+
+	if err := tasks.EnqueueCloudSync(platform.ID, cloudsync.TriggerManual); err != nil {
+		response.FailWithMessage(500, fmt.Sprintf("投递云资产同步任务失败，%v", err), c)
+		return
+	}
+	response.OkWithDetailed("null", "任务正在后台同步云资源", c)
 }
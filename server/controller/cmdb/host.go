@@ -17,11 +17,17 @@ limitations under the License.
 package cmdb
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"kubespace/server/common"
+	"kubespace/server/controller"
 	"kubespace/server/controller/response"
 	"kubespace/server/models"
+	"kubespace/server/models/request"
 	"kubespace/server/services/cmdb"
 	"strconv"
+	"time"
 )
 
 // ListHostGroup 列出主机分组
@@ -65,3 +71,105 @@ func ListHost(c *gin.Context) {
 
 	return
 }
+
+// CreateHost 手动添加主机
+func CreateHost(c *gin.Context) {
+	var form request.HostForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	host, err := cmdb.CreateHost(&form)
+	if err != nil {
+		common.LOG.Error("添加主机失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("添加主机失败，%v", err), c)
+		return
+	}
+	response.OkWithDetailed(host, "添加成功", c)
+}
+
+// UpdateHost 更新主机, 云主机只能修改SSH配置、分组和备注
+func UpdateHost(c *gin.Context) {
+	var form request.HostForm
+	if err := controller.CheckParams(c, &form); err != nil || form.ID == 0 {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if err := cmdb.UpdateHost(&form); err != nil {
+		common.LOG.Error("更新主机失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("更新主机失败，%v", err), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteHost 删除手动维护的主机
+func DeleteHost(c *gin.Context) {
+	var ids request.HostIds
+	if err := controller.CheckParams(c, &ids); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if err := cmdb.DeleteHosts(ids.Ids); err != nil {
+		common.LOG.Error("删除主机失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("删除主机失败，%v", err), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// ImportHost 从csv、xlsx文件批量导入主机, 表单字段为file
+func ImportHost(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.FailWithMessage(response.ParamError, "请上传导入文件", c)
+		return
+	}
+	format, err := cmdb.HostFileFormat(file.Filename)
+	if err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		response.FailWithMessage(500, fmt.Sprintf("读取导入文件失败，%v", err), c)
+		return
+	}
+	defer f.Close()
+
+	forms, err := cmdb.ParseHostFile(format, f)
+	if err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	result := cmdb.ImportHosts(forms)
+	response.OkWithDetailed(result, fmt.Sprintf("新增%d, 更新%d, 失败%d", result.Added, result.Updated, len(result.Errors)), c)
+}
+
+// ExportHost 导出主机, format为csv或xlsx, 可按分组treeId导出
+func ExportHost(c *gin.Context) {
+	format := c.DefaultQuery("format", cmdb.HostFileExcel)
+	if format != cmdb.HostFileCSV && format != cmdb.HostFileExcel {
+		response.FailWithMessage(response.ParamError, "仅支持导出csv、xlsx格式的文件", c)
+		return
+	}
+	hosts, err := cmdb.ExportHosts(c.Query("treeId"))
+	if err != nil {
+		common.LOG.Error("导出主机失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("导出主机失败，%v", err), c)
+		return
+	}
+
+	filename := fmt.Sprintf("hosts-%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	if format == cmdb.HostFileCSV {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		// 写入BOM, 避免excel打开中文乱码
+		_, _ = c.Writer.Write([]byte("\xEF\xBB\xBF"))
+	} else {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	}
+	if err := cmdb.WriteHostFile(format, c.Writer, hosts); err != nil {
+		common.LOG.Error("导出主机失败", zap.Any("err", err))
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"kubespace/server/models/cmdb"
 	"kubespace/server/pkg/utils"
 	WsSession "kubespace/server/pkg/websocket"
+	cmdbService "kubespace/server/services/cmdb"
 	"net/http"
 	"strconv"
 	"sync"
@@ -76,16 +77,27 @@ func (sm *streamMap) Get(key string) (*WsSession.WebSocketStream, error) {
 }
 
 func (sm *streamMap) Remove(key string) {
-	sm.RLock()
+	sm.Lock()
 	delete(sm.innerMap, key)
-	sm.RUnlock()
+	sm.Unlock()
 	return
 }
 
+// List 返回全部在线会话
+func (sm *streamMap) List() []*WsSession.WebSocketStream {
+	sm.RLock()
+	defer sm.RUnlock()
+	list := make([]*WsSession.WebSocketStream, 0, len(sm.innerMap))
+	for _, v := range sm.innerMap {
+		list = append(list, v)
+	}
+	return list
+}
+
 func WebSocketConnect(c *gin.Context) {
 	instanceId := c.Query("instanceId")
 	var host cmdb.VirtualMachine
-	err := common.DB.Table(host.TableName()).Where("uuid = ?", instanceId).First(&host).Error
+	err := common.DB.Table(host.TableName()).Preload("Groups").Where("uuid = ?", instanceId).First(&host).Error
 	if err != nil {
 		common.LOG.Error(err.Error())
 		return
@@ -96,25 +108,27 @@ func WebSocketConnect(c *gin.Context) {
 
 	uid := uuid.NewV4().String()
 
-	// 获取SSH配置
-	if host.Password == "" {
-		var globalConfig cmdb.SSHGlobalConfig
-		common.DB.Table(globalConfig.TableName()).First(&globalConfig)
-		host.Password = globalConfig.Password
-		if host.Port == "" {
-			host.Port = globalConfig.Port
+	var operator, role string
+	if claims, ok := c.Get("claims"); ok {
+		if cc, ok := claims.(*common.CustomClaims); ok {
+			operator, role = cc.Username, cc.Role
 		}
 	}
-	terminalConfig := WsSession.Config{
-		IpAddress:     host.PrivateAddr,
-		Port:          host.Port,
-		UserName:      host.UserName,
-		Password:      host.Password,
-		PrivateKey:    "",
-		KeyPassphrase: "",
-		Width:         cols,
-		Height:        rows,
+	// 加载对当前角色和主机分组生效的命令黑名单
+	rules, err := cmdbService.GetSessionCommandRules(role, host.Groups)
+	if err != nil {
+		common.LOG.Error(fmt.Sprintf("加载命令黑名单失败: %v", err))
+		return
+	}
+
+	// 获取SSH配置, 凭证解密后只在内存中使用
+	terminalConfig, err := cmdbService.GetHostSSHConfig(&host)
+	if err != nil {
+		common.LOG.Error(fmt.Sprintf("获取主机SSH配置失败: %v", err))
+		return
 	}
+	terminalConfig.Width = cols
+	terminalConfig.Height = rows
 
 	// 获取ws连接
 	ws, err := UpGrader.Upgrade(c.Writer, c.Request, nil)
@@ -132,14 +146,17 @@ func WebSocketConnect(c *gin.Context) {
 
 	wsConn := WsSession.NewWsConn(ws)
 	stream := WsSession.NewWebSocketSteam(terminal, wsConn, WsSession.Meta{
-		TERM:      terminal.TERM,
-		Width:     terminalConfig.Width,
-		Height:    terminalConfig.Height,
-		ConnectId: uid,
-		UserName:  host.UserName,
-		HostName:  host.HostName,
-		HostId:    uint(host.ID),
+		TERM:       terminal.TERM,
+		Width:      terminalConfig.Width,
+		Height:     terminalConfig.Height,
+		ConnectId:  uid,
+		UserName:   host.UserName,
+		HostName:   host.HostName,
+		HostId:     uint(host.ID),
+		Operator:   operator,
+		RemoteAddr: c.ClientIP(),
 	})
+	stream.SetCommandRules(rules)
 
 	err = stream.Terminal.Connect(stream, stream, stream)
 
@@ -150,11 +167,12 @@ func WebSocketConnect(c *gin.Context) {
 	}
 	// 断开ws和ssh的操作
 	stream.Terminal.SetCloseHandler(func() error {
-		// 记录用户的操作
+		// 记录用户的操作, 保存失败不影响连接的释放
 		if err := stream.Write2Log(); err != nil {
-			return err
+			common.LOG.Error(fmt.Sprintf("保存SSH会话录像失败: %v", err))
 		}
 		SteamMap.Remove(uid)
+		stream.CloseShadows()
 		return stream.Conn.Ws.Close()
 	})
 
@@ -0,0 +1,116 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"kubespace/server/common"
+	"kubespace/server/controller"
+	"kubespace/server/controller/response"
+	"kubespace/server/models/request"
+	"kubespace/server/services/cmdb"
+)
+
+// ListSSHCredential SSH凭证列表, 不返回密码和私钥
+func ListSSHCredential(c *gin.Context) {
+	var query request.PageInfo
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	err, list, total := cmdb.ListSSHCredential(query)
+	if err != nil {
+		common.LOG.Error("获取SSH凭证失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("获取SSH凭证失败，%v", err), c)
+		return
+	}
+	response.OkWithData(response.PageResult{
+		Data:  list,
+		Total: total,
+		Page:  query.Page,
+		Size:  query.PageSize,
+	}, c)
+}
+
+// CreateSSHCredential 创建SSH凭证
+func CreateSSHCredential(c *gin.Context) {
+	var form request.SSHCredentialForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	cred, err := cmdb.CreateSSHCredential(&form)
+	if err != nil {
+		common.LOG.Error("添加SSH凭证失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("添加SSH凭证失败，%v", err), c)
+		return
+	}
+	response.OkWithDetailed(cred, "添加成功", c)
+}
+
+// UpdateSSHCredential 更新SSH凭证, 密码、私钥、私钥密码为空表示不修改
+func UpdateSSHCredential(c *gin.Context) {
+	var form request.SSHCredentialForm
+	if err := controller.CheckParams(c, &form); err != nil || form.ID == 0 {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if err := cmdb.UpdateSSHCredential(&form); err != nil {
+		common.LOG.Error("更新SSH凭证失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("更新SSH凭证失败，%v", err), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteSSHCredential 批量删除SSH凭证
+func DeleteSSHCredential(c *gin.Context) {
+	var ids request.SSHCredentialIds
+	if err := controller.CheckParams(c, &ids); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if err := cmdb.DeleteSSHCredentials(ids.Ids); err != nil {
+		common.LOG.Error("删除SSH凭证失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("删除SSH凭证失败，%v", err), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// BindSSHCredential 为主机或主机分组分配SSH凭证
+func BindSSHCredential(c *gin.Context) {
+	var form request.SSHCredentialBind
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if err := cmdb.BindSSHCredential(&form); err != nil {
+		common.LOG.Error("分配SSH凭证失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("分配SSH凭证失败，%v", err), c)
+		return
+	}
+	response.OkWithMessage("分配成功", c)
+}
@@ -0,0 +1,175 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	"kubespace/server/common"
+	"kubespace/server/controller/response"
+	"kubespace/server/models/request"
+	"kubespace/server/pkg/asciicast2"
+	"kubespace/server/pkg/utils"
+	"kubespace/server/services/cmdb"
+	"net/http"
+	"strconv"
+)
+
+// ListSSHRecord SSH会话录像列表, 可按用户、主机、接入时间过滤
+func ListSSHRecord(c *gin.Context) {
+	var query request.SSHRecordQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	err, list, total := cmdb.ListSSHRecord(query)
+	if err != nil {
+		common.LOG.Error("获取SSH会话录像失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("获取SSH会话录像失败，%v", err), c)
+		return
+	}
+	response.OkWithData(response.PageResult{
+		Data:  list,
+		Total: total,
+		Page:  query.Page,
+		Size:  query.PageSize,
+	}, c)
+}
+
+// ListSSHCommand SSH命令审计, 可按会话、用户、主机、命令内容、执行时间过滤
+func ListSSHCommand(c *gin.Context) {
+	var query request.SSHCommandQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	err, list, total := cmdb.ListSSHCommand(query)
+	if err != nil {
+		common.LOG.Error("获取SSH命令记录失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("获取SSH命令记录失败，%v", err), c)
+		return
+	}
+	response.OkWithData(response.PageResult{
+		Data:  list,
+		Total: total,
+		Page:  query.Page,
+		Size:  query.PageSize,
+	}, c)
+}
+
+// DownloadSSHRecord 下载SSH会话录像, 文件为asciicast v2格式, 可用asciinema play播放
+func DownloadSSHRecord(c *gin.Context) {
+	id := utils.Str2Uint(c.Query("id"))
+	if id == 0 {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	record, cast, err := cmdb.GetSSHRecordCast(id)
+	if err != nil {
+		common.LOG.Error("读取SSH会话录像失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("读取SSH会话录像失败，%v", err), c)
+		return
+	}
+	filename := fmt.Sprintf("%s-%s.cast", record.HostName, record.ConnectTime.Format("20060102150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/x-asciicast", cast)
+}
+
+// ReplaySSHRecord 通过websocket按原始时间间隔回放SSH会话录像
+// 首条消息为asciicast头部, 之后每条消息为一个[time, "o", data]输出事件, 输入事件不回放;
+// speed为播放倍速, idle大于0时将超过该秒数的停顿压缩为idle秒
+func ReplaySSHRecord(c *gin.Context) {
+	id := utils.Str2Uint(c.Query("id"))
+	if id == 0 {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	speed, _ := strconv.ParseFloat(c.DefaultQuery("speed", "1"), 64)
+	idle, _ := strconv.ParseFloat(c.DefaultQuery("idle", "0"), 64)
+	_, cast, err := cmdb.GetSSHRecordCast(id)
+	if err != nil {
+		common.LOG.Error("读取SSH会话录像失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("读取SSH会话录像失败，%v", err), c)
+		return
+	}
+	header, events, err := asciicast2.Decode(bytes.NewReader(cast))
+	if err != nil {
+		response.FailWithMessage(500, err.Error(), c)
+		return
+	}
+
+	ws, err := UpGrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		common.LOG.Error(fmt.Sprintf("创建消息连接失败: %v", err))
+		return
+	}
+	defer ws.Close()
+
+	// 前端关闭连接时停止回放
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return ws.WriteMessage(websocket.TextMessage, data)
+	}
+	if err := send(header); err != nil {
+		return
+	}
+	output := make([]asciicast2.Event, 0, len(events))
+	for _, e := range events {
+		if e.Type == "o" {
+			output = append(output, e)
+		}
+	}
+	err = asciicast2.Replay(ctx, output, speed, idle, func(e asciicast2.Event) error {
+		return send(e)
+	})
+	if err != nil && err != context.Canceled {
+		common.LOG.Warn(fmt.Sprintf("回放SSH会话录像中断: %v", err))
+		return
+	}
+	_ = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
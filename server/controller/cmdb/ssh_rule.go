@@ -0,0 +1,128 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"kubespace/server/common"
+	"kubespace/server/controller"
+	"kubespace/server/controller/response"
+	"kubespace/server/models/request"
+	"kubespace/server/services/cmdb"
+)
+
+// ListSSHCommandRule 命令黑名单规则列表
+func ListSSHCommandRule(c *gin.Context) {
+	var query request.PageInfo
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	err, list, total := cmdb.ListSSHCommandRule(query)
+	if err != nil {
+		common.LOG.Error("获取命令黑名单规则失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("获取命令黑名单规则失败，%v", err), c)
+		return
+	}
+	response.OkWithData(response.PageResult{
+		Data:  list,
+		Total: total,
+		Page:  query.Page,
+		Size:  query.PageSize,
+	}, c)
+}
+
+// CreateSSHCommandRule 创建命令黑名单规则
+func CreateSSHCommandRule(c *gin.Context) {
+	var form request.SSHCommandRuleForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	rule, err := cmdb.CreateSSHCommandRule(&form)
+	if err != nil {
+		common.LOG.Error("添加命令黑名单规则失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("添加命令黑名单规则失败，%v", err), c)
+		return
+	}
+	response.OkWithDetailed(rule, "添加成功", c)
+}
+
+// UpdateSSHCommandRule 更新命令黑名单规则, 已建立的会话不受影响
+func UpdateSSHCommandRule(c *gin.Context) {
+	var form request.SSHCommandRuleForm
+	if err := controller.CheckParams(c, &form); err != nil || form.ID == 0 {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if err := cmdb.UpdateSSHCommandRule(&form); err != nil {
+		common.LOG.Error("更新命令黑名单规则失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("更新命令黑名单规则失败，%v", err), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteSSHCommandRule 批量删除命令黑名单规则
+func DeleteSSHCommandRule(c *gin.Context) {
+	var ids request.SSHCommandRuleIds
+	if err := controller.CheckParams(c, &ids); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if err := cmdb.DeleteSSHCommandRules(ids.Ids); err != nil {
+		common.LOG.Error("删除命令黑名单规则失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("删除命令黑名单规则失败，%v", err), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// ListSSHCommandBlock 命令拦截记录, 可按平台用户、主机、规则、拦截时间过滤
+func ListSSHCommandBlock(c *gin.Context) {
+	var query request.SSHCommandBlockQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	err, list, total := cmdb.ListSSHCommandBlock(query)
+	if err != nil {
+		common.LOG.Error("获取命令拦截记录失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("获取命令拦截记录失败，%v", err), c)
+		return
+	}
+	response.OkWithData(response.PageResult{
+		Data:  list,
+		Total: total,
+		Page:  query.Page,
+		Size:  query.PageSize,
+	}, c)
+}
@@ -0,0 +1,106 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"kubespace/server/common"
+	"kubespace/server/controller"
+	"kubespace/server/controller/response"
+	"kubespace/server/models/request"
+	WsSession "kubespace/server/pkg/websocket"
+	"sort"
+	"strings"
+)
+
+// ListSSHSession 在线SSH会话列表, 按接入时间倒序, 可按平台用户、主机名过滤
+func ListSSHSession(c *gin.Context) {
+	operator := c.Query("operator")
+	hostName := c.Query("host_name")
+	list := make([]WsSession.SessionInfo, 0)
+	for _, stream := range SteamMap.List() {
+		info := stream.Info()
+		if operator != "" && info.Operator != operator {
+			continue
+		}
+		if hostName != "" && !strings.Contains(info.HostName, hostName) {
+			continue
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt.Time)
+	})
+	response.OkWithData(list, c)
+}
+
+// ShadowSSHSession 通过websocket只读旁观在线会话, 旁观端的输入全部丢弃
+func ShadowSSHSession(c *gin.Context) {
+	stream, err := SteamMap.Get(c.Query("connectId"))
+	if err != nil {
+		response.FailWithMessage(response.ParamError, "会话不存在或已断开", c)
+		return
+	}
+	ws, err := UpGrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		common.LOG.Error(fmt.Sprintf("创建消息连接失败: %v", err))
+		return
+	}
+	defer ws.Close()
+
+	info := stream.Info()
+	common.LOG.Info(fmt.Sprintf("旁观SSH会话, 旁观者: %v, 用户: %v, 主机: %v", claimsUsername(c), info.Operator, info.HostName))
+	stream.Shadow(ws)
+}
+
+// KillSSHSession 强制断开在线会话, 会话录像照常保存
+func KillSSHSession(c *gin.Context) {
+	var form request.SSHSessionKill
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, response.ParamErrorMsg, c)
+		return
+	}
+	stream, err := SteamMap.Get(form.ConnectId)
+	if err != nil {
+		response.FailWithMessage(response.ParamError, "会话不存在或已断开", c)
+		return
+	}
+	info := stream.Info()
+	common.LOG.Warn(fmt.Sprintf("强制断开SSH会话, 操作人: %v, 用户: %v, 主机: %v", claimsUsername(c), info.Operator, info.HostName))
+	reason := "会话已被管理员强制断开"
+	if form.Reason != "" {
+		reason = fmt.Sprintf("%s: %s", reason, form.Reason)
+	}
+	if err := stream.Kill(reason); err != nil {
+		common.LOG.Error("强制断开SSH会话失败", zap.Any("err", err))
+		response.FailWithMessage(500, fmt.Sprintf("强制断开SSH会话失败，%v", err), c)
+		return
+	}
+	response.OkWithMessage("会话已断开", c)
+}
+
+// claimsUsername 当前登录的平台用户
+func claimsUsername(c *gin.Context) string {
+	if claims, ok := c.Get("claims"); ok {
+		if cc, ok := claims.(*common.CustomClaims); ok {
+			return cc.Username
+		}
+	}
+	return ""
+}
@@ -101,10 +101,32 @@ func DelK8SCluster(c *gin.Context) {
 		response.FailWithMessage(response.InternalServerError, "删除失败！", c)
 		return
 	}
+	// 释放已删除集群的Client和Informer缓存
+	for _, clusterId := range toClusterIds(id.Data) {
+		Init.Manager.Remove(clusterId)
+	}
 	response.Ok(c)
 	return
 }
 
+// toClusterIds 将请求中的集群ID(单个或数组)转换为uint列表
+func toClusterIds(data interface{}) []uint {
+	var ids []uint
+	switch v := data.(type) {
+	case []interface{}:
+		for _, i := range v {
+			ids = append(ids, toClusterIds(i)...)
+		}
+	case float64:
+		ids = append(ids, uint(v))
+	case string:
+		if id, err := strconv.ParseUint(v, 10, 32); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
 func ClusterSecret(c *gin.Context) {
 	clusterId := c.DefaultQuery("clusterId", "1")
 	clusterIdUint, err := strconv.ParseUint(clusterId, 10, 32)
@@ -17,7 +17,9 @@ limitations under the License.
 package k8s
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"io/ioutil"
 	"kubespace/server/controller"
 	"kubespace/server/controller/response"
 	"kubespace/server/models/k8s"
@@ -180,3 +182,158 @@ func DeleteCollectionSecretsController(c *gin.Context) {
 	response.Ok(c)
 	return
 }
+
+// CreateConfigMapController 按表单创建ConfigMap
+func CreateConfigMapController(c *gin.Context) {
+	var form k8s.ConfigMapForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	if err := configmap.ValidateConfigMapForm(form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := configmap.CreateConfigMap(client, form)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
+
+// UpdateConfigMapController 按表单更新ConfigMap
+func UpdateConfigMapController(c *gin.Context) {
+	var form k8s.ConfigMapForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	if err := configmap.ValidateConfigMapForm(form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := configmap.UpdateConfigMap(client, form)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
+
+// UploadConfigMapFileController 上传文件写入ConfigMap, 表单字段为namespace、name、file, key为空时使用文件名
+func UploadConfigMapFileController(c *gin.Context) {
+	namespace := c.PostForm("namespace")
+	name := c.PostForm("name")
+	if namespace == "" || name == "" {
+		response.FailWithMessage(response.ParamError, "namespace和name不能为空", c)
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.FailWithMessage(response.ParamError, "请上传文件", c)
+		return
+	}
+	if file.Size > configmap.MaxConfigMapSize {
+		response.FailWithMessage(response.ParamError, fmt.Sprintf("文件大小不能超过%d字节", configmap.MaxConfigMapSize), c)
+		return
+	}
+	key := c.DefaultPostForm("key", file.Filename)
+	f, err := file.Open()
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, fmt.Sprintf("读取上传文件失败，%v", err), c)
+		return
+	}
+	defer f.Close()
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, fmt.Sprintf("读取上传文件失败，%v", err), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := configmap.UploadConfigMapFile(client, namespace, name, key, content)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
+
+// CreateSecretController 按表单创建Opaque、TLS或dockerconfigjson类型的Secret
+func CreateSecretController(c *gin.Context) {
+	var form k8s.SecretForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	if _, err := secret.NewSecretSpec(form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := secret.CreateSecretFromForm(client, form)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
+
+// UpdateSecretController 按表单更新Secret
+func UpdateSecretController(c *gin.Context) {
+	var form k8s.SecretForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	if _, err := secret.NewSecretSpec(form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := secret.UpdateSecretFromForm(client, form)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
+
+// RevealSecretController 返回Secret的明文数据, 通过casbin为该路由单独授权, 只有授权的角色可以查看
+func RevealSecretController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	data, err := secret.RevealSecretDetail(client, namespace, name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
@@ -107,3 +107,46 @@ func DetailCronJobController(c *gin.Context) {
 	response.OkWithData(result, c)
 	return
 }
+
+// SuspendCronJobController 暂停或恢复CronJob调度
+func SuspendCronJobController(c *gin.Context) {
+	var data k8s.CronJobSuspend
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	if err = cronjob.SuspendCronJob(client, data.Namespace, data.Name, *data.Suspend); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+
+	response.Ok(c)
+	return
+}
+
+// TriggerCronJobController 立即按CronJob模板运行一次Job
+func TriggerCronJobController(c *gin.Context) {
+	var data k8s.JobData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	job, err := cronjob.TriggerCronJob(client, data.Namespace, data.Name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+
+	response.OkWithData(k8s.JobData{Namespace: job.Namespace, Name: job.Name}, c)
+	return
+}
@@ -0,0 +1,138 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/gin-gonic/gin"
+	"kubespace/server/controller/response"
+	"kubespace/server/pkg/k8s/Init"
+	"kubespace/server/pkg/k8s/customresource"
+	"kubespace/server/pkg/k8s/event"
+	"kubespace/server/pkg/k8s/parser"
+)
+
+func GetCustomResourceDefinitionController(c *gin.Context) {
+	client, _, err := Init.ClusterDynamic(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dataSelect := parser.ParseDataSelectPathParameter(c)
+	data, err := customresource.GetCustomResourceDefinitionList(client, dataSelect)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func DetailCustomResourceDefinitionController(c *gin.Context) {
+	client, _, err := Init.ClusterDynamic(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	data, err := customresource.GetCustomResourceDefinitionDetail(client, name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+// GetCustomResourceObjectController 列出CRD的实例, 参数crd为CRD名称, 如 certificates.cert-manager.io
+func GetCustomResourceObjectController(c *gin.Context) {
+	client, _, err := Init.ClusterDynamic(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dataSelect := parser.ParseDataSelectPathParameter(c)
+	nsQuery := parser.ParseNamespacePathParameter(c)
+	data, err := customresource.GetCustomResourceObjectList(client, c.Query("crd"), nsQuery, dataSelect)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func GetCustomResourceObjectYAMLController(c *gin.Context) {
+	client, _, err := Init.ClusterDynamic(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	data, err := customresource.GetCustomResourceObjectYAML(client, c.Query("crd"), namespace, name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(gin.H{"yaml": data}, c)
+	return
+}
+
+func DeleteCustomResourceObjectController(c *gin.Context) {
+	client, _, err := Init.ClusterDynamic(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	if err := customresource.DeleteCustomResourceObject(client, c.Query("crd"), namespace, name); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
+
+// GetCustomResourceObjectEventController 实例的事件, 按CRD的kind和API组过滤, 不包含同名的其他资源的事件
+func GetCustomResourceObjectEventController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dynamicClient, _, err := Init.ClusterDynamic(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	crd, err := customresource.GetCustomResourceDefinitionDetail(dynamicClient, c.Query("crd"))
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dataSelect := parser.ParseDataSelectPathParameter(c)
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	data, err := event.GetObjectEvents(client, dataSelect, namespace, name, crd.Kind, crd.Group)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
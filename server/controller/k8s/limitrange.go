@@ -0,0 +1,118 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/gin-gonic/gin"
+	"kubespace/server/controller"
+	"kubespace/server/controller/response"
+	"kubespace/server/models/k8s"
+	"kubespace/server/pkg/k8s/Init"
+	"kubespace/server/pkg/k8s/limitrange"
+	"kubespace/server/pkg/k8s/parser"
+)
+
+func GetLimitRangeController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dataSelect := parser.ParseDataSelectPathParameter(c)
+	nsQuery := parser.ParseNamespacePathParameter(c)
+
+	data, err := limitrange.GetLimitRangeList(client, nsQuery, dataSelect)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func DetailLimitRangeController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	data, err := limitrange.GetLimitRangeDetail(client, namespace, name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func CreateLimitRangeController(c *gin.Context) {
+	var data k8s.LimitRangeData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := limitrange.CreateLimitRange(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+func UpdateLimitRangeController(c *gin.Context) {
+	var data k8s.LimitRangeData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := limitrange.UpdateLimitRange(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+func DeleteLimitRangeController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	if err := limitrange.DeleteLimitRange(client, namespace, name); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
@@ -17,16 +17,32 @@ limitations under the License.
 package k8s
 
 import (
+	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"io"
 	v1 "k8s.io/api/core/v1"
+	"kubespace/server/common"
 	"kubespace/server/controller/response"
 	"kubespace/server/pkg/k8s/Init"
 	"kubespace/server/pkg/k8s/logs"
 	"kubespace/server/pkg/k8s/pods"
+	"net/http"
+	"regexp"
 	"strconv"
+	"time"
 )
 
+var logUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024 * 64,
+	// 允许跨域
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
 func GetLogSourcesController(c *gin.Context) {
 	client, err := Init.ClusterID(c)
 	if err != nil {
@@ -124,3 +140,60 @@ func handleDownload(c *gin.Context, result io.ReadCloser) {
 		return
 	}
 }
+
+// StreamLogController 通过WebSocket实时跟踪Pod或控制器下所有Pod的日志
+// 参数: container 只跟踪指定容器, grep 服务端过滤(正则), sinceSeconds、tailLines 起始位置
+func StreamLogController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	namespace := c.Param("namespace")
+	resourceName := c.Param("resourceName")
+	resourceType := c.Param("resourceType")
+
+	opts := logs.StreamOptions{Container: c.Query("container")}
+	if grep := c.Query("grep"); grep != "" {
+		if opts.Filter, err = regexp.Compile(grep); err != nil {
+			response.FailWithMessage(response.ParamError, fmt.Sprintf("过滤条件不正确: %v", err), c)
+			return
+		}
+	}
+	if since, err := strconv.ParseInt(c.Query("sinceSeconds"), 10, 64); err == nil && since > 0 {
+		opts.SinceSeconds = &since
+	}
+	if tail, err := strconv.ParseInt(c.Query("tailLines"), 10, 64); err == nil && tail >= 0 {
+		opts.TailLines = &tail
+	}
+
+	ws, err := logUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		common.LOG.Error(fmt.Sprintf("创建日志websocket连接失败: %v", err))
+		return
+	}
+	defer ws.Close()
+
+	// 客户端断开时取消所有日志流
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = logs.StreamLogs(ctx, client, namespace, resourceName, resourceType, opts, func(lines []logs.StreamLine) error {
+		_ = ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return ws.WriteJSON(lines)
+	})
+	if err != nil && ctx.Err() == nil {
+		_ = ws.SetWriteDeadline(time.Now().Add(time.Second))
+		_ = ws.WriteJSON([]logs.StreamLine{{Error: err.Error()}})
+	}
+	_ = ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+}
@@ -18,9 +18,12 @@ package k8s
 
 import (
 	"github.com/gin-gonic/gin"
+	"kubespace/server/controller"
 	"kubespace/server/controller/response"
+	"kubespace/server/models/k8s"
 	"kubespace/server/pkg/k8s/Init"
 	"kubespace/server/pkg/k8s/namespace"
+	"kubespace/server/pkg/k8s/parser"
 )
 
 func GetNamespaceList(c *gin.Context) {
@@ -38,3 +41,115 @@ func GetNamespaceList(c *gin.Context) {
 	response.OkWithData(namespaces, c)
 	return
 }
+
+func GetNamespaceListController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dataSelect := parser.ParseDataSelectPathParameter(c)
+	data, err := namespace.GetNamespaceListWithSelect(client, dataSelect)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func DetailNamespaceController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	data, err := namespace.GetNamespaceDetail(client, name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func CreateNamespaceController(c *gin.Context) {
+	var data k8s.NamespaceData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	ns, err := namespace.CreateNamespace(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(ns, c)
+	return
+}
+
+func UpdateNamespaceController(c *gin.Context) {
+	var data k8s.NamespaceData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	ns, err := namespace.UpdateNamespaceMeta(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(ns, c)
+	return
+}
+
+// NamespaceDeleteTokenController 获取删除命名空间的确认Token
+func NamespaceDeleteTokenController(c *gin.Context) {
+	name := parser.ParseNameParameter(c)
+	if name == "" {
+		response.FailWithMessage(response.ParamError, "命名空间名称不能为空", c)
+		return
+	}
+	token, err := namespace.IssueDeleteToken(namespaceTokenKey(c, name), name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(gin.H{"token": token}, c)
+	return
+}
+
+func DeleteNamespaceController(c *gin.Context) {
+	var data k8s.NamespaceDelete
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	if err := namespace.DeleteNamespace(client, namespaceTokenKey(c, data.Name), data); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
+
+// namespaceTokenKey 区分不同集群中的同名命名空间
+func namespaceTokenKey(c *gin.Context, name string) string {
+	return c.DefaultQuery("clusterId", "1") + "/" + name
+}
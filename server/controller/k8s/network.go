@@ -179,3 +179,99 @@ func DeleteCollectionIngressController(c *gin.Context) {
 	response.Ok(c)
 	return
 }
+
+// CreateServiceController 按表单创建Service
+func CreateServiceController(c *gin.Context) {
+	var form k8s.ServiceForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	if err := service.ValidateServiceForm(form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := service.CreateService(client, form)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
+
+// UpdateServiceController 按表单更新Service
+func UpdateServiceController(c *gin.Context) {
+	var form k8s.ServiceForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	if err := service.ValidateServiceForm(form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := service.UpdateService(client, form)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
+
+// CreateIngressController 按表单创建Ingress, 按集群版本使用networking/v1或v1beta1
+func CreateIngressController(c *gin.Context) {
+	var form k8s.IngressForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	if err := ingress.ValidateIngressForm(form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := ingress.CreateIngress(client, form)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
+
+// UpdateIngressController 按表单更新Ingress
+func UpdateIngressController(c *gin.Context) {
+	var form k8s.IngressForm
+	if err := controller.CheckParams(c, &form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	if err := ingress.ValidateIngressForm(form); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := ingress.UpdateIngress(client, form)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
@@ -20,7 +20,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"kubespace/server/controller"
 	"kubespace/server/controller/response"
+	"kubespace/server/models/k8s"
 	"kubespace/server/pkg/k8s/Init"
+	"kubespace/server/pkg/k8s/evict"
 	"kubespace/server/pkg/k8s/node"
 	"net/http"
 )
@@ -112,13 +114,48 @@ func RemoveNode(c *gin.Context) {
 		response.FailWithMessage(response.InternalServerError, err.Error(), c)
 		return
 	}
-	go node.RemoveNode(client, nodeName)
-	//if ok, err := node.RemoveNode(client, nodeName); !ok {
-	//	response.FailWithMessage(response.InternalServerError, err.Error(), c)
-	//	return
-	//}
-	response.Ok(c)
+	// 排空成功后才移除节点, 通过GET node/drain?taskId=查询进度
+	task, err := evict.StartDrain(client, drainTaskKey(c, nodeName), k8s.NodeDrain{NodeName: nodeName, DeleteNode: true})
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(task, c)
+}
+
+// drainTaskKey 区分不同集群中的同名节点
+func drainTaskKey(c *gin.Context, nodeName string) string {
+	return c.DefaultQuery("clusterId", "1") + "/" + nodeName
+}
+
+// DrainNode 异步排空节点, 返回任务ID供前端轮询进度
+func DrainNode(c *gin.Context) {
+	var opts k8s.NodeDrain
+	if err := controller.CheckParams(c, &opts); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	task, err := evict.StartDrain(client, drainTaskKey(c, opts.NodeName), opts)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(task, c)
+}
 
+// GetDrainTask 查询排空任务进度
+func GetDrainTask(c *gin.Context) {
+	task, err := evict.GetDrainTask(c.Query("taskId"))
+	if err != nil {
+		response.FailWithMessage(http.StatusNotFound, err.Error(), c)
+		return
+	}
+	response.OkWithData(task, c)
 }
 
 type collectionNode struct {
@@ -0,0 +1,431 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/gin-gonic/gin"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"kubespace/server/controller"
+	"kubespace/server/controller/response"
+	"kubespace/server/models/k8s"
+	"kubespace/server/pkg/k8s/Init"
+	"kubespace/server/pkg/k8s/parser"
+	"kubespace/server/pkg/k8s/rbac"
+)
+
+func GetRoleController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dataSelect := parser.ParseDataSelectPathParameter(c)
+	nsQuery := parser.ParseNamespacePathParameter(c)
+	data, err := rbac.GetRoleList(client, nsQuery, dataSelect)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func DetailRoleController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	data, err := rbac.GetRoleDetail(client, namespace, name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+// CreateRoleController 创建Role
+func CreateRoleController(c *gin.Context) {
+	var data k8s.RoleData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := rbac.CreateRole(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+// UpdateRoleController 更新Role的规则
+func UpdateRoleController(c *gin.Context) {
+	var data k8s.RoleData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := rbac.UpdateRole(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+func DeleteRoleController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	if err := rbac.DeleteRole(client, namespace, name); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
+
+func GetClusterRoleController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dataSelect := parser.ParseDataSelectPathParameter(c)
+	data, err := rbac.GetClusterRoleList(client, dataSelect)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func DetailClusterRoleController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	data, err := rbac.GetClusterRoleDetail(client, name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+// CreateClusterRoleController 创建ClusterRole
+func CreateClusterRoleController(c *gin.Context) {
+	var data k8s.RoleData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := rbac.CreateClusterRole(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+// UpdateClusterRoleController 更新ClusterRole的规则
+func UpdateClusterRoleController(c *gin.Context) {
+	var data k8s.RoleData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := rbac.UpdateClusterRole(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+func DeleteClusterRoleController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	if err := rbac.DeleteClusterRole(client, name); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
+
+func GetRoleBindingController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dataSelect := parser.ParseDataSelectPathParameter(c)
+	nsQuery := parser.ParseNamespacePathParameter(c)
+	data, err := rbac.GetRoleBindingList(client, nsQuery, dataSelect)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func DetailRoleBindingController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	data, err := rbac.GetRoleBindingDetail(client, namespace, name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+// CreateRoleBindingController 创建RoleBinding
+func CreateRoleBindingController(c *gin.Context) {
+	var data k8s.RoleBindingData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := rbac.CreateRoleBinding(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+// UpdateRoleBindingController 更新RoleBinding的主体
+func UpdateRoleBindingController(c *gin.Context) {
+	var data k8s.RoleBindingData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := rbac.UpdateRoleBinding(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+func DeleteRoleBindingController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	namespace := parser.ParseNamespaceParameter(c)
+	if err := rbac.DeleteRoleBinding(client, namespace, name); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
+
+func GetClusterRoleBindingController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	dataSelect := parser.ParseDataSelectPathParameter(c)
+	data, err := rbac.GetClusterRoleBindingList(client, dataSelect)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+func DetailClusterRoleBindingController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	data, err := rbac.GetClusterRoleBindingDetail(client, name)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+// CreateClusterRoleBindingController 创建ClusterRoleBinding
+func CreateClusterRoleBindingController(c *gin.Context) {
+	var data k8s.RoleBindingData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := rbac.CreateClusterRoleBinding(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+// UpdateClusterRoleBindingController 更新ClusterRoleBinding的主体
+func UpdateClusterRoleBindingController(c *gin.Context) {
+	var data k8s.RoleBindingData
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	result, err := rbac.UpdateClusterRoleBinding(client, data)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(result, c)
+	return
+}
+
+func DeleteClusterRoleBindingController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	name := parser.ParseNameParameter(c)
+	if err := rbac.DeleteClusterRoleBinding(client, name); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
+
+// WhoCanController 查询能对资源执行指定动作的主体, 参数: verb, resource, apiGroup, name, namespace
+func WhoCanController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := rbac.WhoCan(client, c.Query("verb"), c.Query("apiGroup"), c.Query("resource"), c.Query("name"), c.Query("namespace"))
+	if err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+// SubjectPermissionsController 查询主体的有效权限, 参数: kind(User/Group/ServiceAccount), name, namespace
+func SubjectPermissionsController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	subject := rbacv1.Subject{
+		Kind:      c.Query("kind"),
+		Name:      c.Query("name"),
+		Namespace: c.Query("namespace"),
+	}
+	if subject.Kind == "" || subject.Name == "" {
+		response.FailWithMessage(response.ParamError, "主体类型和名称不能为空", c)
+		return
+	}
+	data, err := rbac.GetSubjectPermissions(client, subject, c.Query("scope"))
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
@@ -0,0 +1,76 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/gin-gonic/gin"
+	"kubespace/server/controller"
+	"kubespace/server/controller/response"
+	"kubespace/server/models/k8s"
+	"kubespace/server/pkg/k8s/Init"
+	"kubespace/server/pkg/k8s/parser"
+	"kubespace/server/pkg/k8s/resource"
+)
+
+// ApplyResourceController 以server-side apply的方式创建或更新任意资源, 支持多文档YAML/JSON, dryRun=All时只校验不落盘
+func ApplyResourceController(c *gin.Context) {
+	client, mapper, err := Init.ClusterDynamic(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	var applyData k8s.ApplyResource
+	err = controller.CheckParams(c, &applyData)
+	if err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+
+	data, err := resource.ApplyResources(client, mapper, []byte(applyData.Content), resource.ApplyOptions{
+		Namespace: applyData.Namespace,
+		Force:     applyData.Force,
+		DryRun:    c.Query("dryRun") == resource.DryRunAll,
+	})
+	if err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+}
+
+// GetResourceYAMLController 获取任意资源的YAML, 供编辑器使用
+func GetResourceYAMLController(c *gin.Context) {
+	client, mapper, err := Init.ClusterDynamic(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	apiVersion := c.Query("apiVersion")
+	kind := c.Query("kind")
+	name := parser.ParseNameParameter(c)
+	if apiVersion == "" || kind == "" || name == "" {
+		response.FailWithMessage(response.ParamError, "apiVersion、kind和name不能为空", c)
+		return
+	}
+
+	data, err := resource.GetResourceYAML(client, mapper, apiVersion, kind, parser.ParseNamespaceParameter(c), name)
+	if err != nil {
+		response.FailWithMessage(response.ERROR, err.Error(), c)
+		return
+	}
+	response.OkWithData(map[string]interface{}{"yaml": data}, c)
+}
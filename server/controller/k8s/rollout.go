@@ -0,0 +1,210 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"kubespace/server/common"
+	"kubespace/server/controller"
+	"kubespace/server/controller/response"
+	"kubespace/server/models/k8s"
+	"kubespace/server/pkg/k8s/Init"
+	"kubespace/server/pkg/k8s/rollout"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var rolloutUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024 * 4,
+	// 允许跨域
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// SetImageController 修改Deployment、StatefulSet或DaemonSet的容器镜像
+func SetImageController(c *gin.Context) {
+	var data k8s.RolloutImage
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	if err := rollout.SetImages(client, data); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
+
+func PauseRolloutController(c *gin.Context) {
+	var data k8s.RolloutTarget
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	if err := rollout.PauseRollout(client, data.Kind, data.Namespace, data.Name); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
+
+func ResumeRolloutController(c *gin.Context) {
+	var data k8s.RolloutTarget
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	if err := rollout.ResumeRollout(client, data.Kind, data.Namespace, data.Name); err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.Ok(c)
+	return
+}
+
+// RolloutStatusController 通过WebSocket推送发布进度, 发布完成、失败或超时后关闭连接
+// 参数: timeoutSeconds 最长跟踪时间, 未指定时Deployment在progressDeadlineSeconds内没有进展后结束, 其他类型最长跟踪10分钟
+func RolloutStatusController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	kind := c.Param("kind")
+	var timeout time.Duration
+	if seconds, err := strconv.ParseInt(c.Query("timeoutSeconds"), 10, 64); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	ws, err := rolloutUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		common.LOG.Error(fmt.Sprintf("创建发布状态websocket连接失败: %v", err))
+		return
+	}
+	defer ws.Close()
+
+	// 客户端断开时停止跟踪
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = rollout.WatchStatus(ctx, client, kind, namespace, name, timeout, func(status rollout.RolloutStatus) error {
+		_ = ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return ws.WriteJSON(status)
+	})
+	if err != nil && ctx.Err() == nil {
+		_ = ws.SetWriteDeadline(time.Now().Add(time.Second))
+		_ = ws.WriteJSON(rollout.RolloutStatus{Error: err.Error()})
+	}
+	_ = ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+}
+
+// GetRevisionsController 获取工作负载的历史版本, 参数: kind, namespace, name
+func GetRevisionsController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := rollout.GetRevisions(client, c.Query("kind"), c.Query("namespace"), c.Query("name"))
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+// DiffRevisionsController 比较两个历史版本的Pod模板, 参数: kind, namespace, name, from, to
+func DiffRevisionsController(c *gin.Context) {
+	from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		response.FailWithMessage(response.ParamError, "版本号不正确", c)
+		return
+	}
+	to, err := strconv.ParseInt(c.Query("to"), 10, 64)
+	if err != nil {
+		response.FailWithMessage(response.ParamError, "版本号不正确", c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	data, err := rollout.DiffRevisions(client, c.Query("kind"), c.Query("namespace"), c.Query("name"), from, to)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithData(data, c)
+	return
+}
+
+// RollbackRevisionController StatefulSet或DaemonSet回滚到指定版本
+func RollbackRevisionController(c *gin.Context) {
+	var data k8s.RolloutRollback
+	if err := controller.CheckParams(c, &data); err != nil {
+		response.FailWithMessage(response.ParamError, err.Error(), c)
+		return
+	}
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	message, err := rollout.RollbackRevision(client, data.Kind, data.Namespace, data.Name, *data.Revision)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	response.OkWithMessage(message, c)
+	return
+}
@@ -0,0 +1,73 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/gin-gonic/gin"
+	"kubespace/server/common"
+	"kubespace/server/controller/response"
+	"kubespace/server/pkg/k8s/Init"
+	"kubespace/server/pkg/k8s/terminal"
+	"strconv"
+)
+
+// ExecShellController 创建容器终端会话, 前端拿到会话ID后通过SockJS连接发送bind消息绑定
+func ExecShellController(c *gin.Context) {
+	client, err := Init.ClusterID(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	cfg, err := Init.ClusterRestConfig(c)
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+	namespace := c.Query("namespace")
+	pod := c.Query("pod")
+	if namespace == "" || pod == "" {
+		response.FailWithMessage(response.ParamError, "namespace和pod不能为空", c)
+		return
+	}
+	clusterId, _ := strconv.ParseUint(c.DefaultQuery("clusterId", "1"), 10, 32)
+
+	var (
+		userId   uint
+		userName string
+	)
+	if claims, ok := c.Get("claims"); ok {
+		userId = claims.(*common.CustomClaims).ID
+		userName = claims.(*common.CustomClaims).Username
+	}
+	sessionId, err := terminal.NewTerminalSession(terminal.TerminalMeta{
+		ClusterId: uint(clusterId),
+		Namespace: namespace,
+		Pod:       pod,
+		Container: c.Query("container"),
+		Shell:     c.Query("shell"),
+		UserId:    userId,
+		UserName:  userName,
+	})
+	if err != nil {
+		response.FailWithMessage(response.InternalServerError, err.Error(), c)
+		return
+	}
+
+	// 请求结束后gin会复用Context, 异步使用时需要拷贝
+	go terminal.WaitForTerminal(client, cfg, c.Copy(), sessionId)
+	response.OkWithData(gin.H{"id": sessionId}, c)
+}
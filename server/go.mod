@@ -4,6 +4,7 @@ go 1.15
 
 require (
 	github.com/aliyun/alibaba-cloud-sdk-go v1.61.1304
+	github.com/aws/aws-sdk-go v1.41.19
 	github.com/casbin/casbin v1.9.1
 	github.com/casbin/casbin/v2 v2.37.0
 	github.com/casbin/gorm-adapter/v3 v3.4.2
@@ -13,20 +14,25 @@ require (
 	github.com/go-ldap/ldap/v3 v3.4.1
 	github.com/go-sql-driver/mysql v1.6.0
 	github.com/gookit/color v1.4.2
-	github.com/gorilla/websocket v1.4.2 // indirect
+	github.com/gorilla/websocket v1.4.2
 	github.com/hibiken/asynq v0.19.0
 	github.com/hibiken/asynqmon v0.4.0
+	github.com/huaweicloud/huaweicloud-sdk-go-v3 v0.0.74
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/lestrrat-go/file-rotatelogs v2.4.0+incompatible
 	github.com/lestrrat-go/strftime v1.0.5 // indirect
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/pelletier/go-toml v1.9.4 // indirect
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/prometheus/common v0.31.1
-	github.com/satori/go.uuid v1.2.0 // indirect
+	github.com/robfig/cron/v3 v3.0.1
+	github.com/satori/go.uuid v1.2.0
 	github.com/spf13/cast v1.4.1 // indirect
 	github.com/spf13/cobra v1.2.1
 	github.com/spf13/viper v1.8.1
+	github.com/tencentcloud/tencentcloud-sdk-go v1.0.162
 	github.com/toolkits/pkg v1.2.6
+	github.com/xuri/excelize/v2 v2.4.1
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/automaxprocs v1.4.0 // indirect
 	go.uber.org/multierr v1.7.0 // indirect
@@ -34,6 +40,7 @@ require (
 	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519
 	golang.org/x/sys v0.0.0-20210927094055-39ccf1dd6fa6 // indirect
 	google.golang.org/protobuf v1.27.1 // indirect
+	gopkg.in/igm/sockjs-go.v2 v2.1.0
 	gopkg.in/ini.v1 v1.63.0 // indirect
 	gorm.io/driver/mysql v1.1.2
 	gorm.io/driver/postgres v1.1.1 // indirect
@@ -43,4 +50,5 @@ require (
 	k8s.io/apimachinery v0.22.3
 	k8s.io/client-go v0.22.3
 	k8s.io/kubectl v0.22.3
-)
\ No newline at end of file
+	sigs.k8s.io/yaml v1.2.0
+)
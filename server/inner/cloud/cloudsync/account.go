@@ -0,0 +1,82 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudsync
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"kubespace/server/common"
+	"kubespace/server/models/cmdb"
+	"sync"
+	"time"
+)
+
+// 同步触发方式
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var ErrSyncInProgress = errors.New("该云账号正在同步中")
+
+var (
+	runningLock sync.Mutex
+	running     = make(map[int]bool)
+)
+
+// SyncAccount 同步云账号下的资产, 同一账号同时只允许一个同步, 并维护账号的Status、Msg、SyncTime
+func SyncAccount(account *cmdb.CloudPlatform, trigger string) (*cmdb.CloudSyncRecord, error) {
+	runningLock.Lock()
+	if running[account.ID] {
+		runningLock.Unlock()
+		return nil, ErrSyncInProgress
+	}
+	running[account.ID] = true
+	runningLock.Unlock()
+
+	defer func() {
+		runningLock.Lock()
+		delete(running, account.ID)
+		runningLock.Unlock()
+	}()
+
+	updatePlatformStatus(account.ID, map[string]interface{}{
+		"status": cmdb.PlatformSyncInProgress,
+		"msg":    "",
+	})
+
+	record := syncHost(account, trigger)
+
+	now := time.Now()
+	status, msg := cmdb.PlatformSyncSuccess, fmt.Sprintf("新增%d, 更新%d, 销毁%d", record.Added, record.Updated, record.Removed)
+	if record.Errors != 0 {
+		status, msg = cmdb.PlatformSyncFail, record.ErrMsg
+	}
+	updatePlatformStatus(account.ID, map[string]interface{}{
+		"status":    status,
+		"msg":       msg,
+		"sync_time": &now,
+	})
+	return record, nil
+}
+
+func updatePlatformStatus(id int, values map[string]interface{}) {
+	if err := common.DB.Model(&cmdb.CloudPlatform{}).Where("id = ?", id).Updates(values).Error; err != nil {
+		common.LOG.Error("更新云账号同步状态失败", zap.Any("err", err))
+	}
+}
@@ -19,64 +19,103 @@ package cloudsync
 import (
 	"fmt"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 	"kubespace/server/common"
 	"kubespace/server/inner/cloud/cloudvendor"
+	"kubespace/server/models"
 	"kubespace/server/models/cmdb"
+	"strings"
+	"time"
 )
 
-var (
-	aliClient cloudvendor.VendorClient
+// 主机差异类型
+const (
+	diffAdd    = "add"
+	diffUpdate = "update"
+	diffRemove = "remove" // 云上已释放, 标记为Destroyed
+	diffDelete = "delete" // 已销毁超过保留期, 软删除
 )
 
-// SyncAliYunHost 同步阿里云主机
-func SyncAliYunHost(task *cmdb.CloudPlatform) {
+// syncHost 使用账号对应云厂商的客户端按地域同步主机及其他云资源, 同步结束后写入同步记录
+func syncHost(task *cmdb.CloudPlatform, trigger string) *cmdb.CloudSyncRecord {
+	record := &cmdb.CloudSyncRecord{
+		PlatformId: task.ID,
+		Trigger:    trigger,
+		StartTime:  models.LocalTime{Time: time.Now()},
+	}
+	var errMsgs []string
+	addError := func(msg string) {
+		common.LOG.Error(msg)
+		record.Errors++
+		errMsgs = append(errMsgs, msg)
+	}
+
 	defer func() {
 		if err := recover(); err != nil {
-			common.LOG.Error(fmt.Sprintf("sync panic err: %v", err))
+			addError(fmt.Sprintf("sync panic err: %v", err))
+		}
+		record.EndTime = models.LocalTime{Time: time.Now()}
+		record.Duration = record.EndTime.Sub(record.StartTime.Time).Seconds()
+		record.ErrMsg = strings.Join(errMsgs, "\n")
+		record.Status = cmdb.CloudSyncSuccess
+		if record.Errors != 0 {
+			record.Status = cmdb.CloudSyncFail
+		}
+		if err := common.DB.Create(record).Error; err != nil {
+			common.LOG.Error("写入云资产同步记录失败", zap.Any("err", err))
 		}
 	}()
 
 	// 获取cloud账户
 	conf := cmdb.CloudPlatform{
-		Type:      cmdb.AliYun,
+		Type:      task.Type,
 		AccessKey: task.AccessKey,
 		SecretKey: task.SecretKey,
+		Encrypted: task.Encrypted,
 	}
 
-	aliClient, _ = cloudvendor.GetVendorClient(&conf)
+	client, err := cloudvendor.GetVendorClient(&conf)
+	if err != nil {
+		addError(fmt.Sprintf("获取云厂商客户端失败, err: %v", err))
+		return record
+	}
 
 	// 获取所有可用区
-	regionSet, _ := aliClient.GetRegions()
+	regionSet, err := client.GetRegions()
+	if err != nil {
+		addError(fmt.Sprintf("获取地域列表失败, err: %v", err))
+		return record
+	}
+	retain := time.Duration(common.CONFIG.CloudSync.DestroyedRetainHours) * time.Hour
 	for _, region := range regionSet {
-		// 获取所有区域下的ecs主机
-		instancesInfo, err := aliClient.GetInstances(region.RegionId)
+		// 同步云硬盘、负载均衡、云数据库、VPC等资源
+		syncResources(client, task.ID, region.RegionId, addError)
+
+		// 获取所有区域下的主机
+		instancesInfo, err := client.GetInstances(region.RegionId)
 		if err != nil {
-			common.LOG.Error(fmt.Sprintf("同步资产发生错误, err: %v", err))
+			// 拉取失败时不能判断哪些主机已释放, 跳过该地域
+			addError(fmt.Sprintf("同步地域%s资产发生错误, err: %v", region.RegionId, err))
+			continue
 		}
-		// 判断区域下是否有ecs
-		if len(instancesInfo) != 0 {
-			for _, i := range instancesInfo {
-				// 根据主机实例id获取db中的主机信息,并获取有差异的主机
-				diffHosts, _ := getDiffHosts(&i)
-				if len(diffHosts) != 0 {
-					// 有差异的更新任务状态为同步中
-					//updateTaskState(diffHosts)
-
-					// 同步有差异的主机数据
-					syncDiffHosts(diffHosts)
-				}
-			}
+		for i := range instancesInfo {
+			instancesInfo[i].PlatformId = task.ID
+			instancesInfo[i].RegionId = region.RegionId
+		}
+
+		localHosts, err := getLocalHosts(task.ID, region.RegionId, instancesInfo)
+		if err != nil {
+			addError(fmt.Sprintf("查询地域%s本地主机失败, err: %v", region.RegionId, err))
+			continue
 		}
+		// 对比云上和本地主机, 同步有差异的主机数据
+		diffHosts := getDiffHosts(instancesInfo, localHosts, time.Now(), retain)
+		syncDiffHosts(diffHosts, record, addError)
 	}
-	return
+	return record
 }
 
-// getDiffHosts 根据主机实例id获取db中的主机信息,并获取有差异的主机
-func getDiffHosts(remoteHosts *cmdb.VirtualMachine) (map[string][]*cmdb.VirtualMachine, error) {
-	// 本地已有主机
-	localHosts, _ := getLocalHosts(remoteHosts)
-
+// getDiffHosts 对比账号某个地域下云上和本地的主机, 按新增、更新、销毁、删除分组
+func getDiffHosts(remoteHosts []cmdb.VirtualMachine, localHosts []*cmdb.VirtualMachine, now time.Time, retain time.Duration) map[string][]*cmdb.VirtualMachine {
 	localIdHostsMap := make(map[string]*cmdb.VirtualMachine)
 	for _, h := range localHosts {
 		localIdHostsMap[h.UUID] = h
@@ -86,34 +125,57 @@ func getDiffHosts(remoteHosts *cmdb.VirtualMachine) (map[string][]*cmdb.VirtualM
 	diffHosts := make(map[string][]*cmdb.VirtualMachine)
 
 	// 本地需要同步新增和更新的主机
-	if _, ok := localIdHostsMap[remoteHosts.UUID]; ok {
-		lh := localIdHostsMap[remoteHosts.UUID]
-		// 判断云主机和本地主机是否有差异，有则需要更新
-		if remoteHosts.HostName != lh.HostName || remoteHosts.PublicAddr != lh.PublicAddr ||
-			remoteHosts.PrivateAddr != lh.PrivateAddr || remoteHosts.VmExpiredTime != lh.VmExpiredTime ||
-			remoteHosts.Status != lh.Status || remoteHosts.Mem != lh.Mem || remoteHosts.CPU != lh.CPU ||
-			remoteHosts.BandWidth != lh.BandWidth {
-			diffHosts["update"] = append(diffHosts["update"], remoteHosts)
+	remoteIds := make(map[string]bool)
+	for i := range remoteHosts {
+		remoteHost := &remoteHosts[i]
+		remoteIds[remoteHost.UUID] = true
+		lh, ok := localIdHostsMap[remoteHost.UUID]
+		if !ok {
+			diffHosts[diffAdd] = append(diffHosts[diffAdd], remoteHost)
+			continue
+		}
+		// 判断云主机和本地主机是否有差异，有则需要更新; 曾被标记销毁的主机重新出现时也需要更新
+		if remoteHost.HostName != lh.HostName || remoteHost.PublicAddr != lh.PublicAddr ||
+			remoteHost.PrivateAddr != lh.PrivateAddr || remoteHost.VmExpiredTime != lh.VmExpiredTime ||
+			remoteHost.Status != lh.Status || remoteHost.Mem != lh.Mem || remoteHost.CPU != lh.CPU ||
+			remoteHost.BandWidth != lh.BandWidth || remoteHost.PlatformId != lh.PlatformId ||
+			remoteHost.RegionId != lh.RegionId || lh.DestroyedTime != nil {
+			diffHosts[diffUpdate] = append(diffHosts[diffUpdate], remoteHost)
+		}
+	}
+
+	// 本地存在但云上已不存在的主机
+	for _, lh := range localHosts {
+		if remoteIds[lh.UUID] {
+			continue
+		}
+		if lh.Status != cmdb.VmStatusDestroyed || lh.DestroyedTime == nil {
+			diffHosts[diffRemove] = append(diffHosts[diffRemove], lh)
+		} else if retain > 0 && now.Sub(*lh.DestroyedTime) >= retain {
+			diffHosts[diffDelete] = append(diffHosts[diffDelete], lh)
 		}
-	} else {
-		diffHosts["add"] = append(diffHosts["add"], remoteHosts)
 	}
 
-	return diffHosts, nil
+	return diffHosts
 }
 
-// getLocalHosts 获取本地主机
-func getLocalHosts(hostResource *cmdb.VirtualMachine) ([]*cmdb.VirtualMachine, error) {
+// getLocalHosts 获取本地属于该账号地域的主机, 以及云上实例对应的历史主机(未关联账号的旧数据)
+func getLocalHosts(platformId int, regionId string, remoteHosts []cmdb.VirtualMachine) ([]*cmdb.VirtualMachine, error) {
 	result := make([]*cmdb.VirtualMachine, 0)
-	var c cmdb.VirtualMachine
 
-	results := common.DB.Table("cloud_virtual_machine").Where("uuid = ?", hostResource.UUID).First(&c)
-	if results.Error != nil {
-		if results.Error == gorm.ErrRecordNotFound {
-			return nil, gorm.ErrRecordNotFound
-		}
+	uuids := make([]string, 0, len(remoteHosts))
+	for _, h := range remoteHosts {
+		uuids = append(uuids, h.UUID)
+	}
+
+	cond := common.DB.Where("platform_id = ? AND region_id = ?", platformId, regionId)
+	if len(uuids) != 0 {
+		cond = cond.Or("uuid IN ?", uuids)
+	}
+	// 分组条件, 避免OR与软删除条件混在一起
+	if err := common.DB.Table("cloud_virtual_machine").Where(cond).Find(&result).Error; err != nil {
+		return nil, err
 	}
-	result = append(result, &c)
 	return result, nil
 }
 
@@ -125,32 +187,61 @@ func addHost(hostResource []*cmdb.VirtualMachine) error {
 	return nil
 }
 
-// syncDiffHosts 更新变化的主机
-func syncDiffHosts(diff map[string][]*cmdb.VirtualMachine) {
+// syncDiffHosts 更新变化的主机, 并将数量计入同步记录
+func syncDiffHosts(diff map[string][]*cmdb.VirtualMachine, record *cmdb.CloudSyncRecord, addError func(string)) {
 
 	for k, v := range diff {
 		switch k {
-		case "update":
+		case diffUpdate:
 			for _, host := range v {
 				// HostName、PublicAddr、PrivateAddr、VmExpiredTime、Status、Mem、CPU、BandWidth
 				results := common.DB.Table("cloud_virtual_machine").
-					Where("uuid = ?", &host.UUID).Updates(map[string]interface{}{
-					"hostname":        &host.HostName,
-					"public_addr":     &host.PublicAddr,
-					"private_addr":    &host.PrivateAddr,
-					"vm_expired_time": &host.VmExpiredTime,
-					"status":          &host.Status,
-					"mem":             &host.Mem,
-					"cpu":             &host.CPU,
-					"bandwidth":       &host.BandWidth,
+					Where("uuid = ?", host.UUID).Updates(map[string]interface{}{
+					"hostname":        host.HostName,
+					"public_addr":     host.PublicAddr,
+					"private_addr":    host.PrivateAddr,
+					"vm_expired_time": host.VmExpiredTime,
+					"status":          host.Status,
+					"mem":             host.Mem,
+					"cpu":             host.CPU,
+					"bandwidth":       host.BandWidth,
+					"platform_id":     host.PlatformId,
+					"region_id":       host.RegionId,
+					"destroyed_time":  nil,
 				})
 				if results.Error != nil {
-					common.LOG.Error("更新主机资源失败", zap.Any("err", results.Error))
+					addError(fmt.Sprintf("更新主机资源%s失败, err: %v", host.UUID, results.Error))
+					continue
 				}
+				record.Updated++
 			}
-		case "add":
+		case diffAdd:
 			if err := addHost(v); err != nil {
-				common.LOG.Error("同步新增主机失败", zap.Any("err", err.Error()))
+				addError(fmt.Sprintf("同步新增主机失败, err: %v", err))
+				continue
+			}
+			record.Added += len(v)
+		case diffRemove:
+			now := time.Now()
+			for _, host := range v {
+				results := common.DB.Table("cloud_virtual_machine").
+					Where("id = ?", host.ID).Updates(map[string]interface{}{
+					"status":         cmdb.VmStatusDestroyed,
+					"destroyed_time": &now,
+				})
+				if results.Error != nil {
+					addError(fmt.Sprintf("标记主机%s销毁失败, err: %v", host.UUID, results.Error))
+					continue
+				}
+				record.Removed++
+			}
+		case diffDelete:
+			for _, host := range v {
+				if err := common.DB.Delete(&cmdb.VirtualMachine{}, host.ID).Error; err != nil {
+					addError(fmt.Sprintf("删除已销毁主机%s失败, err: %v", host.UUID, err))
+					continue
+				}
+				record.Deleted++
 			}
 		}
 	}
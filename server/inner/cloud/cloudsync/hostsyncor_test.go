@@ -0,0 +1,79 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudsync
+
+import (
+	"kubespace/server/models/cmdb"
+	"testing"
+	"time"
+)
+
+func TestGetDiffHosts(t *testing.T) {
+	now := time.Now()
+	recently := now.Add(-time.Hour)
+	longAgo := now.Add(-100 * time.Hour)
+
+	remote := []cmdb.VirtualMachine{
+		{UUID: "i-new", Status: "Running", PlatformId: 1, RegionId: "cn-hangzhou"},
+		{UUID: "i-same", Status: "Running", PlatformId: 1, RegionId: "cn-hangzhou"},
+		{UUID: "i-changed", Status: "Stopped", PlatformId: 1, RegionId: "cn-hangzhou"},
+		// 旧数据未关联账号
+		{UUID: "i-legacy", Status: "Running", PlatformId: 1, RegionId: "cn-hangzhou"},
+		// 曾被标记销毁后重新出现
+		{UUID: "i-back", Status: "Running", PlatformId: 1, RegionId: "cn-hangzhou"},
+	}
+	local := []*cmdb.VirtualMachine{
+		{ID: 1, UUID: "i-same", Status: "Running", PlatformId: 1, RegionId: "cn-hangzhou"},
+		{ID: 2, UUID: "i-changed", Status: "Running", PlatformId: 1, RegionId: "cn-hangzhou"},
+		{ID: 3, UUID: "i-legacy", Status: "Running"},
+		{ID: 4, UUID: "i-back", Status: cmdb.VmStatusDestroyed, PlatformId: 1, RegionId: "cn-hangzhou", DestroyedTime: &recently},
+		{ID: 5, UUID: "i-released", Status: "Running", PlatformId: 1, RegionId: "cn-hangzhou"},
+		{ID: 6, UUID: "i-destroyed", Status: cmdb.VmStatusDestroyed, PlatformId: 1, RegionId: "cn-hangzhou", DestroyedTime: &recently},
+		{ID: 7, UUID: "i-expired", Status: cmdb.VmStatusDestroyed, PlatformId: 1, RegionId: "cn-hangzhou", DestroyedTime: &longAgo},
+	}
+
+	uuids := func(hosts []*cmdb.VirtualMachine) []string {
+		result := make([]string, 0, len(hosts))
+		for _, h := range hosts {
+			result = append(result, h.UUID)
+		}
+		return result
+	}
+	assertDiff := func(diff map[string][]*cmdb.VirtualMachine, kind string, expected ...string) {
+		t.Helper()
+		got := uuids(diff[kind])
+		if len(got) != len(expected) {
+			t.Fatalf("%s: expected %v, got %v", kind, expected, got)
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Fatalf("%s: expected %v, got %v", kind, expected, got)
+			}
+		}
+	}
+
+	diff := getDiffHosts(remote, local, now, 72*time.Hour)
+	assertDiff(diff, diffAdd, "i-new")
+	assertDiff(diff, diffUpdate, "i-changed", "i-legacy", "i-back")
+	assertDiff(diff, diffRemove, "i-released")
+	assertDiff(diff, diffDelete, "i-expired")
+
+	// 保留时长为0时只标记不删除
+	diff = getDiffHosts(remote, local, now, 0)
+	assertDiff(diff, diffRemove, "i-released")
+	assertDiff(diff, diffDelete)
+}
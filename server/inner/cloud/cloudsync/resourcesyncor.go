@@ -0,0 +1,137 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudsync
+
+import (
+	"fmt"
+	"kubespace/server/common"
+	"kubespace/server/inner/cloud/cloudvendor"
+	"kubespace/server/models/cmdb"
+)
+
+// resourceItem 云上拉取的资源, base指向value中嵌入的公共字段
+type resourceItem struct {
+	base  *cmdb.CloudResource
+	value interface{}
+}
+
+// syncResources 同步地域下主机以外的云资源, 只同步云厂商客户端实现了的资源类型
+func syncResources(client cloudvendor.VendorClient, platformId int, regionId string, addError func(string)) {
+	save := func(kind string, model interface{}, items []resourceItem, err error) {
+		if err != nil {
+			addError(fmt.Sprintf("同步地域%s%s发生错误, err: %v", regionId, kind, err))
+			return
+		}
+		if err := saveResources(model, platformId, regionId, items); err != nil {
+			addError(fmt.Sprintf("保存地域%s%s失败, err: %v", regionId, kind, err))
+		}
+	}
+
+	if c, ok := client.(cloudvendor.DiskClient); ok {
+		disks, err := c.GetDisks(regionId)
+		items := make([]resourceItem, 0, len(disks))
+		for i := range disks {
+			items = append(items, resourceItem{base: &disks[i].CloudResource, value: &disks[i]})
+		}
+		save("云硬盘", &cmdb.CloudDisk{}, items, err)
+	}
+	if c, ok := client.(cloudvendor.LoadBalancerClient); ok {
+		lbs, err := c.GetLoadBalancers(regionId)
+		items := make([]resourceItem, 0, len(lbs))
+		for i := range lbs {
+			items = append(items, resourceItem{base: &lbs[i].CloudResource, value: &lbs[i]})
+		}
+		save("负载均衡", &cmdb.CloudLoadBalancer{}, items, err)
+	}
+	if c, ok := client.(cloudvendor.DatabaseClient); ok {
+		dbs, err := c.GetDatabases(regionId)
+		items := make([]resourceItem, 0, len(dbs))
+		for i := range dbs {
+			items = append(items, resourceItem{base: &dbs[i].CloudResource, value: &dbs[i]})
+		}
+		save("云数据库", &cmdb.CloudDatabase{}, items, err)
+	}
+	if c, ok := client.(cloudvendor.VpcClient); ok {
+		vpcs, err := c.GetVpcs(regionId)
+		items := make([]resourceItem, 0, len(vpcs))
+		for i := range vpcs {
+			items = append(items, resourceItem{base: &vpcs[i].CloudResource, value: &vpcs[i]})
+		}
+		save("VPC", &cmdb.CloudVpc{}, items, err)
+
+		subnets, err := c.GetSubnets(regionId)
+		items = make([]resourceItem, 0, len(subnets))
+		for i := range subnets {
+			items = append(items, resourceItem{base: &subnets[i].CloudResource, value: &subnets[i]})
+		}
+		save("子网", &cmdb.CloudSubnet{}, items, err)
+	}
+}
+
+// diffResources 按云上资源ID匹配本地资源, 已存在的回填本地ID(新增的ID为0), 返回云上已不存在的本地资源ID
+func diffResources(localIds map[string]int, items []resourceItem) []int {
+	remoteIds := make(map[string]bool, len(items))
+	for _, item := range items {
+		remoteIds[item.base.UUID] = true
+		item.base.ID = localIds[item.base.UUID]
+	}
+	stale := make([]int, 0)
+	for uuid, id := range localIds {
+		if !remoteIds[uuid] {
+			stale = append(stale, id)
+		}
+	}
+	return stale
+}
+
+// saveResources 按 账号+地域 全量同步一种资源: 更新已有的, 新增缺少的, 软删除云上已释放的
+func saveResources(model interface{}, platformId int, regionId string, items []resourceItem) error {
+	var rows []struct {
+		ID   int
+		UUID string
+	}
+	if err := common.DB.Model(model).Select("id, uuid").
+		Where("platform_id = ? AND region_id = ?", platformId, regionId).Scan(&rows).Error; err != nil {
+		return err
+	}
+	localIds := make(map[string]int, len(rows))
+	for _, r := range rows {
+		localIds[r.UUID] = r.ID
+	}
+
+	for _, item := range items {
+		item.base.PlatformId = platformId
+		item.base.RegionId = regionId
+	}
+	stale := diffResources(localIds, items)
+
+	for _, item := range items {
+		var err error
+		if item.base.ID == 0 {
+			err = common.DB.Create(item.value).Error
+		} else {
+			err = common.DB.Omit("created_at").Save(item.value).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(stale) != 0 {
+		return common.DB.Delete(model, stale).Error
+	}
+	return nil
+}
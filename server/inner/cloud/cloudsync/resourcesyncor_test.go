@@ -0,0 +1,44 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudsync
+
+import (
+	"kubespace/server/models/cmdb"
+	"testing"
+)
+
+func TestDiffResources(t *testing.T) {
+	disks := []cmdb.CloudDisk{
+		{CloudResource: cmdb.CloudResource{UUID: "d-keep"}},
+		{CloudResource: cmdb.CloudResource{UUID: "d-new", ID: 99}},
+	}
+	items := make([]resourceItem, 0, len(disks))
+	for i := range disks {
+		items = append(items, resourceItem{base: &disks[i].CloudResource, value: &disks[i]})
+	}
+
+	stale := diffResources(map[string]int{"d-keep": 1, "d-released": 2}, items)
+	if disks[0].ID != 1 {
+		t.Fatalf("expected existing disk to reuse local id 1, got %d", disks[0].ID)
+	}
+	if disks[1].ID != 0 {
+		t.Fatalf("expected new disk id to be reset, got %d", disks[1].ID)
+	}
+	if len(stale) != 1 || stale[0] != 2 {
+		t.Fatalf("expected stale [2], got %v", stale)
+	}
+}
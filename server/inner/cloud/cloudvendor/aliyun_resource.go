@@ -0,0 +1,237 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudvendor
+
+import (
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/rds"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/slb"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/vpc"
+	"kubespace/server/models/cmdb"
+)
+
+var (
+	_ DiskClient         = (*aliClient)(nil)
+	_ LoadBalancerClient = (*aliClient)(nil)
+	_ DatabaseClient     = (*aliClient)(nil)
+	_ VpcClient          = (*aliClient)(nil)
+)
+
+// aliMaxPage 分页拉取的最大页数, 防止接口返回异常时死循环
+const aliMaxPage = 1000
+
+func (a *aliClient) resource(region, id, name, status, zone, createTime string) cmdb.CloudResource {
+	return cmdb.CloudResource{
+		RegionId:   region,
+		ZoneId:     zone,
+		UUID:       id,
+		Name:       name,
+		Status:     status,
+		Source:     a.vendorName,
+		CreateTime: createTime,
+	}
+}
+
+// GetDisks 获取云盘列表
+// API文档：https://help.aliyun.com/document_detail/25514.html
+func (a *aliClient) GetDisks(region string) ([]cmdb.CloudDisk, error) {
+	client, err := ecs.NewClientWithAccessKey(region, a.secretID, a.secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	request := ecs.CreateDescribeDisksRequest()
+	request.Scheme = "https"
+	request.RegionId = region
+	request.PageSize = MaxPageSize
+
+	disks := make([]cmdb.CloudDisk, 0)
+	for page := 1; page <= aliMaxPage; page++ {
+		request.PageNumber = requests.NewInteger(page)
+		resp, err := client.DescribeDisks(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range resp.Disks.Disk {
+			disks = append(disks, cmdb.CloudDisk{
+				CloudResource: a.resource(region, d.DiskId, d.DiskName, d.Status, d.ZoneId, d.CreationTime),
+				Size:          d.Size,
+				Category:      d.Category,
+				Type:          d.Type,
+				InstanceId:    d.InstanceId,
+				Device:        d.Device,
+				Encrypted:     d.Encrypted,
+				ExpiredTime:   d.ExpiredTime,
+			})
+		}
+		if len(resp.Disks.Disk) == 0 || len(disks) >= resp.TotalCount {
+			break
+		}
+	}
+	return disks, nil
+}
+
+// GetLoadBalancers 获取负载均衡实例列表
+// API文档：https://help.aliyun.com/document_detail/27582.html
+func (a *aliClient) GetLoadBalancers(region string) ([]cmdb.CloudLoadBalancer, error) {
+	client, err := slb.NewClientWithAccessKey(region, a.secretID, a.secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	request := slb.CreateDescribeLoadBalancersRequest()
+	request.Scheme = "https"
+	request.RegionId = region
+	request.PageSize = MaxPageSize
+
+	lbs := make([]cmdb.CloudLoadBalancer, 0)
+	for page := 1; page <= aliMaxPage; page++ {
+		request.PageNumber = requests.NewInteger(page)
+		resp, err := client.DescribeLoadBalancers(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, lb := range resp.LoadBalancers.LoadBalancer {
+			lbs = append(lbs, cmdb.CloudLoadBalancer{
+				CloudResource: a.resource(region, lb.LoadBalancerId, lb.LoadBalancerName, lb.LoadBalancerStatus, lb.MasterZoneId, lb.CreateTime),
+				Address:       lb.Address,
+				AddressType:   lb.AddressType,
+				NetworkType:   lb.NetworkType,
+				VpcId:         lb.VpcId,
+				SubnetId:      lb.VSwitchId,
+				Spec:          lb.LoadBalancerSpec,
+				BandWidth:     lb.Bandwidth,
+			})
+		}
+		if len(resp.LoadBalancers.LoadBalancer) == 0 || len(lbs) >= resp.TotalCount {
+			break
+		}
+	}
+	return lbs, nil
+}
+
+// GetDatabases 获取RDS实例列表
+// API文档：https://help.aliyun.com/document_detail/26232.html
+func (a *aliClient) GetDatabases(region string) ([]cmdb.CloudDatabase, error) {
+	client, err := rds.NewClientWithAccessKey(region, a.secretID, a.secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	request := rds.CreateDescribeDBInstancesRequest()
+	request.Scheme = "https"
+	request.RegionId = region
+	request.PageSize = MaxPageSize
+
+	dbs := make([]cmdb.CloudDatabase, 0)
+	for page := 1; page <= aliMaxPage; page++ {
+		request.PageNumber = requests.NewInteger(page)
+		resp, err := client.DescribeDBInstances(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, db := range resp.Items.DBInstance {
+			dbs = append(dbs, cmdb.CloudDatabase{
+				CloudResource: a.resource(region, db.DBInstanceId, db.DBInstanceDescription, db.DBInstanceStatus, db.ZoneId, db.CreateTime),
+				Engine:        db.Engine,
+				EngineVersion: db.EngineVersion,
+				InstanceClass: db.DBInstanceClass,
+				Address:       db.ConnectionString,
+				NetworkType:   db.InstanceNetworkType,
+				VpcId:         db.VpcId,
+				SubnetId:      db.VSwitchId,
+				ExpiredTime:   db.ExpireTime,
+			})
+		}
+		if len(resp.Items.DBInstance) == 0 || len(dbs) >= resp.TotalRecordCount {
+			break
+		}
+	}
+	return dbs, nil
+}
+
+// GetVpcs 获取专有网络列表
+// API文档：https://help.aliyun.com/document_detail/35739.html
+func (a *aliClient) GetVpcs(region string) ([]cmdb.CloudVpc, error) {
+	client, err := vpc.NewClientWithAccessKey(region, a.secretID, a.secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	request := vpc.CreateDescribeVpcsRequest()
+	request.Scheme = "https"
+	request.RegionId = region
+	request.PageSize = requests.NewInteger(50)
+
+	vpcs := make([]cmdb.CloudVpc, 0)
+	for page := 1; page <= aliMaxPage; page++ {
+		request.PageNumber = requests.NewInteger(page)
+		resp, err := client.DescribeVpcs(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range resp.Vpcs.Vpc {
+			vpcs = append(vpcs, cmdb.CloudVpc{
+				CloudResource: a.resource(region, v.VpcId, v.VpcName, v.Status, "", v.CreationTime),
+				CidrBlock:     v.CidrBlock,
+				IsDefault:     v.IsDefault,
+				Description:   v.Description,
+			})
+		}
+		if len(resp.Vpcs.Vpc) == 0 || len(vpcs) >= resp.TotalCount {
+			break
+		}
+	}
+	return vpcs, nil
+}
+
+// GetSubnets 获取交换机列表
+// API文档：https://help.aliyun.com/document_detail/35748.html
+func (a *aliClient) GetSubnets(region string) ([]cmdb.CloudSubnet, error) {
+	client, err := vpc.NewClientWithAccessKey(region, a.secretID, a.secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	request := vpc.CreateDescribeVSwitchesRequest()
+	request.Scheme = "https"
+	request.RegionId = region
+	request.PageSize = requests.NewInteger(50)
+
+	subnets := make([]cmdb.CloudSubnet, 0)
+	for page := 1; page <= aliMaxPage; page++ {
+		request.PageNumber = requests.NewInteger(page)
+		resp, err := client.DescribeVSwitches(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range resp.VSwitches.VSwitch {
+			subnets = append(subnets, cmdb.CloudSubnet{
+				CloudResource:    a.resource(region, s.VSwitchId, s.VSwitchName, s.Status, s.ZoneId, s.CreationTime),
+				VpcId:            s.VpcId,
+				CidrBlock:        s.CidrBlock,
+				AvailableIpCount: s.AvailableIpAddressCount,
+				IsDefault:        s.IsDefault,
+			})
+		}
+		if len(resp.VSwitches.VSwitch) == 0 || len(subnets) >= resp.TotalCount {
+			break
+		}
+	}
+	return subnets, nil
+}
@@ -0,0 +1,217 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudvendor
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"kubespace/server/models/cmdb"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register(cmdb.AWS, &awsClient{vendorName: cmdb.AWS})
+}
+
+// awsPageSize DescribeInstances单页最大数量
+const awsPageSize int64 = 1000
+
+type awsClient struct {
+	vendorName string
+	secretID   string
+	secretKey  string
+	// endpoint 为空时使用SDK默认的接入地址, 测试时可指向本地服务
+	endpoint string
+}
+
+// NewVendorClient 创建云厂商客户端
+func (a *awsClient) NewVendorClient(secretID, secretKey string) VendorClient {
+	return &awsClient{
+		vendorName: cmdb.AWS,
+		secretID:   secretID,
+		secretKey:  secretKey,
+		endpoint:   a.endpoint,
+	}
+}
+
+// newEc2Client 创建指定地域的EC2客户端
+func (a *awsClient) newEc2Client(region string) (*ec2.EC2, error) {
+	conf := &aws.Config{
+		Region:      aws.String(region),
+		Credentials: credentials.NewStaticCredentials(a.secretID, a.secretKey, ""),
+	}
+	if a.endpoint != "" {
+		conf.Endpoint = aws.String(a.endpoint)
+	}
+	sess, err := session.NewSession(conf)
+	if err != nil {
+		return nil, err
+	}
+	return ec2.New(sess), nil
+}
+
+// GetRegions 获取地域列表
+// API文档：https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeRegions.html
+func (a *awsClient) GetRegions() ([]*cmdb.Region, error) {
+	client, err := a.newEc2Client("us-east-1")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.DescribeRegions(&ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	regionSet := make([]*cmdb.Region, 0)
+	for _, region := range resp.Regions {
+		regionSet = append(regionSet, &cmdb.Region{
+			RegionId:   aws.StringValue(region.RegionName),
+			RegionName: aws.StringValue(region.RegionName),
+		})
+	}
+	return regionSet, nil
+}
+
+// GetInstances 获取实例列表
+// API文档：https://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstances.html
+func (a *awsClient) GetInstances(region string) ([]cmdb.VirtualMachine, error) {
+	client, err := a.newEc2Client(region)
+	if err != nil {
+		fmt.Printf("创建客户端连接失败，%v", err.Error())
+		return nil, err
+	}
+
+	var (
+		ec2List       []*ec2.Instance
+		instancesInfo []cmdb.VirtualMachine
+	)
+
+	// 按NextToken分页
+	err = client.DescribeInstancesPages(&ec2.DescribeInstancesInput{
+		MaxResults: aws.Int64(awsPageSize),
+	}, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+		for _, reservation := range page.Reservations {
+			ec2List = append(ec2List, reservation.Instances...)
+		}
+		return true
+	})
+	if err != nil {
+		fmt.Printf("查询EC2实例列表失败，%v", err.Error())
+		return nil, err
+	}
+	if len(ec2List) == 0 {
+		return instancesInfo, nil
+	}
+
+	// DescribeInstances不返回内存, 需要根据实例规格查询
+	instanceTypes, err := a.getInstanceTypes(client, ec2List)
+	if err != nil {
+		fmt.Printf("查询EC2实例规格失败，%v", err.Error())
+		return nil, err
+	}
+
+	// 同步的云资产放到默认的Default分组
+	for _, e := range ec2List {
+		var tree []*cmdb.TreeMenu
+		group := append(tree, &cmdb.TreeMenu{ID: 1})
+
+		var cpu, mem int64
+		if typeInfo, ok := instanceTypes[aws.StringValue(e.InstanceType)]; ok {
+			if typeInfo.VCpuInfo != nil {
+				cpu = aws.Int64Value(typeInfo.VCpuInfo.DefaultVCpus)
+			}
+			if typeInfo.MemoryInfo != nil {
+				mem = aws.Int64Value(typeInfo.MemoryInfo.SizeInMiB)
+			}
+		}
+		var state, zone, createdTime string
+		if e.State != nil {
+			state = aws.StringValue(e.State.Name)
+		}
+		if e.Placement != nil {
+			zone = aws.StringValue(e.Placement.AvailabilityZone)
+		}
+		if e.LaunchTime != nil {
+			createdTime = e.LaunchTime.Format(time.RFC3339)
+		}
+		// Platform仅在windows实例上返回
+		osType := "linux"
+		if strings.EqualFold(aws.StringValue(e.Platform), ec2.PlatformValuesWindows) {
+			osType = "windows"
+		}
+
+		instancesInfo = append(instancesInfo, cmdb.VirtualMachine{
+			Groups:        group,
+			UUID:          aws.StringValue(e.InstanceId),
+			HostName:      getAwsInstanceName(e),
+			CPU:           int(cpu),
+			Mem:           int(mem),
+			OS:            aws.StringValue(e.PlatformDetails),
+			OSType:        osType,
+			PrivateAddr:   aws.StringValue(e.PrivateIpAddress),
+			PublicAddr:    aws.StringValue(e.PublicIpAddress),
+			Status:        state,
+			Region:        zone,
+			VmCreatedTime: createdTime,
+			Source:        cmdb.AWS,
+		})
+	}
+
+	return instancesInfo, nil
+}
+
+// getInstanceTypes 查询实例用到的规格信息
+func (a *awsClient) getInstanceTypes(client *ec2.EC2, instances []*ec2.Instance) (map[string]*ec2.InstanceTypeInfo, error) {
+	typeNames := make([]*string, 0)
+	seen := make(map[string]bool)
+	for _, e := range instances {
+		name := aws.StringValue(e.InstanceType)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			typeNames = append(typeNames, aws.String(name))
+		}
+	}
+
+	instanceTypes := make(map[string]*ec2.InstanceTypeInfo)
+	if len(typeNames) == 0 {
+		return instanceTypes, nil
+	}
+	err := client.DescribeInstanceTypesPages(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: typeNames,
+	}, func(page *ec2.DescribeInstanceTypesOutput, lastPage bool) bool {
+		for _, t := range page.InstanceTypes {
+			instanceTypes[aws.StringValue(t.InstanceType)] = t
+		}
+		return true
+	})
+	return instanceTypes, err
+}
+
+// getAwsInstanceName EC2没有实例名称, 使用Name标签
+func getAwsInstanceName(instance *ec2.Instance) string {
+	for _, tag := range instance.Tags {
+		if aws.StringValue(tag.Key) == "Name" {
+			return aws.StringValue(tag.Value)
+		}
+	}
+	return aws.StringValue(instance.InstanceId)
+}
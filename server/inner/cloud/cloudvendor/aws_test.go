@@ -0,0 +1,134 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudvendor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const awsResponseHeader = `<?xml version="1.0" encoding="UTF-8"?>`
+
+// newAwsStandIn 启动一个模拟EC2 Query API的本地服务, DescribeInstances每页返回一个实例, 通过NextToken翻页
+func newAwsStandIn(t *testing.T, pages int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), "Credential=test-id/") {
+			t.Errorf("unexpected authorization header: %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		switch r.Form.Get("Action") {
+		case "DescribeRegions":
+			fmt.Fprint(w, awsResponseHeader+`<DescribeRegionsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+<requestId>1</requestId>
+<regionInfo>
+<item><regionName>us-east-1</regionName><regionEndpoint>ec2.us-east-1.amazonaws.com</regionEndpoint></item>
+<item><regionName>ap-northeast-1</regionName><regionEndpoint>ec2.ap-northeast-1.amazonaws.com</regionEndpoint></item>
+</regionInfo>
+</DescribeRegionsResponse>`)
+		case "DescribeInstances":
+			page := 0
+			if token := r.Form.Get("NextToken"); token != "" {
+				fmt.Sscanf(token, "page-%d", &page)
+			}
+			nextToken := ""
+			if page+1 < pages {
+				nextToken = fmt.Sprintf("<nextToken>page-%d</nextToken>", page+1)
+			}
+			platform := ""
+			if page == 1 {
+				platform = "<platform>windows</platform>"
+			}
+			fmt.Fprintf(w, awsResponseHeader+`<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+<requestId>2</requestId>
+<reservationSet><item><reservationId>r-%[1]d</reservationId><instancesSet><item>
+<instanceId>i-%[1]d</instanceId>
+<instanceType>t3.medium</instanceType>
+<instanceState><code>16</code><name>running</name></instanceState>
+<privateIpAddress>172.31.0.%[1]d</privateIpAddress>
+<ipAddress>54.0.0.%[1]d</ipAddress>
+<placement><availabilityZone>us-east-1a</availabilityZone></placement>
+<launchTime>2021-10-01T08:00:00.000Z</launchTime>
+%[2]s
+<tagSet><item><key>Name</key><value>web-%[1]d</value></item></tagSet>
+</item></instancesSet></item></reservationSet>
+%[3]s
+</DescribeInstancesResponse>`, page, platform, nextToken)
+		case "DescribeInstanceTypes":
+			fmt.Fprint(w, awsResponseHeader+`<DescribeInstanceTypesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+<requestId>3</requestId>
+<instanceTypeSet><item>
+<instanceType>t3.medium</instanceType>
+<vCpuInfo><defaultVCpus>2</defaultVCpus></vCpuInfo>
+<memoryInfo><sizeInMiB>4096</sizeInMiB></memoryInfo>
+</item></instanceTypeSet>
+</DescribeInstanceTypesResponse>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, awsResponseHeader+`<Response><Errors><Error><Code>InvalidAction</Code><Message>unknown action</Message></Error></Errors><RequestID>0</RequestID></Response>`)
+		}
+	}))
+}
+
+func newAwsTestClient(server *httptest.Server) VendorClient {
+	proto := &awsClient{endpoint: server.URL}
+	return proto.NewVendorClient("test-id", "test-key")
+}
+
+func TestAwsGetRegions(t *testing.T) {
+	server := newAwsStandIn(t, 1)
+	defer server.Close()
+
+	regionSet, err := newAwsTestClient(server).GetRegions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regionSet) != 2 || regionSet[1].RegionId != "ap-northeast-1" {
+		t.Fatalf("unexpected regions: %#v", regionSet)
+	}
+}
+
+func TestAwsGetInstances(t *testing.T) {
+	server := newAwsStandIn(t, 3)
+	defer server.Close()
+
+	instancesInfo, err := newAwsTestClient(server).GetInstances("us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instancesInfo) != 3 {
+		t.Fatalf("expected 3 instances across pages, got %d", len(instancesInfo))
+	}
+
+	vm := instancesInfo[1]
+	if vm.UUID != "i-1" || vm.HostName != "web-1" || vm.PrivateAddr != "172.31.0.1" || vm.PublicAddr != "54.0.0.1" {
+		t.Fatalf("unexpected instance: %#v", vm)
+	}
+	if vm.CPU != 2 || vm.Mem != 4096 || vm.Status != "running" || vm.Region != "us-east-1a" {
+		t.Fatalf("unexpected instance spec: %#v", vm)
+	}
+	if vm.OSType != "windows" || instancesInfo[0].OSType != "linux" || vm.Source != "aws" {
+		t.Fatalf("unexpected instance meta: %#v", vm)
+	}
+}
@@ -19,6 +19,7 @@ package cloudvendor
 import (
 	"fmt"
 	"kubespace/server/models/cmdb"
+	"kubespace/server/pkg/utils"
 )
 
 var vendorClients = make(map[string]VendorClient, 0)
@@ -32,18 +33,51 @@ type VendorClient interface {
 	GetInstances(region string) ([]cmdb.VirtualMachine, error)
 }
 
+// 以下为可选的资源类型能力, 云厂商客户端实现了哪些接口就同步哪些资源
+
+// DiskClient 云硬盘
+type DiskClient interface {
+	GetDisks(region string) ([]cmdb.CloudDisk, error)
+}
+
+// LoadBalancerClient 负载均衡
+type LoadBalancerClient interface {
+	GetLoadBalancers(region string) ([]cmdb.CloudLoadBalancer, error)
+}
+
+// DatabaseClient 云数据库
+type DatabaseClient interface {
+	GetDatabases(region string) ([]cmdb.CloudDatabase, error)
+}
+
+// VpcClient 专有网络和子网
+type VpcClient interface {
+	GetVpcs(region string) ([]cmdb.CloudVpc, error)
+	GetSubnets(region string) ([]cmdb.CloudSubnet, error)
+}
+
 // Register 注册云厂商客户端
 func Register(vendorName string, client VendorClient) {
 	vendorClients[vendorName] = client
 }
 
-// GetVendorClient 获取云厂商客户端
+// GetVendorClient 获取云厂商客户端, 数据库中读取的账号凭证为加密存储, 在这里解密
 func GetVendorClient(conf *cmdb.CloudPlatform) (VendorClient, error) {
 	var client VendorClient
 	var ok bool
 	if client, ok = vendorClients[conf.Type]; !ok {
 		return nil, fmt.Errorf("vendor %s is not supported", conf.Type)
 	}
-	cli := client.NewVendorClient(conf.AccessKey, conf.SecretKey)
+	accessKey, secretKey := conf.AccessKey, conf.SecretKey
+	if conf.Encrypted {
+		var err error
+		if accessKey, err = utils.DecryptSecret(accessKey); err != nil {
+			return nil, fmt.Errorf("解密云账号凭证失败: %v", err)
+		}
+		if secretKey, err = utils.DecryptSecret(secretKey); err != nil {
+			return nil, fmt.Errorf("解密云账号凭证失败: %v", err)
+		}
+	}
+	cli := client.NewVendorClient(accessKey, secretKey)
 	return cli, nil
 }
@@ -0,0 +1,203 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudvendor
+
+import (
+	"fmt"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/region"
+	ecs "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ecs/v2"
+	ecsModel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ecs/v2/model"
+	iam "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/iam/v3"
+	iamModel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/iam/v3/model"
+	"kubespace/server/models/cmdb"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(cmdb.HuaWei, &huaweiClient{vendorName: cmdb.HuaWei})
+}
+
+const (
+	// huaweiPageSize ListServersDetails单页最大数量
+	huaweiPageSize    int32 = 1000
+	huaweiIamEndpoint       = "https://iam.myhuaweicloud.com"
+)
+
+type huaweiClient struct {
+	vendorName string
+	secretID   string
+	secretKey  string
+	// endpoint 为空时使用IAM和各地域ECS的默认接入地址, 测试时可指向本地服务
+	endpoint string
+}
+
+// NewVendorClient 创建云厂商客户端
+func (h *huaweiClient) NewVendorClient(secretID, secretKey string) VendorClient {
+	return &huaweiClient{
+		vendorName: cmdb.HuaWei,
+		secretID:   secretID,
+		secretKey:  secretKey,
+		endpoint:   h.endpoint,
+	}
+}
+
+func (h *huaweiClient) iamEndpoint() string {
+	if h.endpoint != "" {
+		return h.endpoint
+	}
+	return huaweiIamEndpoint
+}
+
+func (h *huaweiClient) ecsEndpoint(regionId string) string {
+	if h.endpoint != "" {
+		return h.endpoint
+	}
+	return fmt.Sprintf("https://ecs.%s.myhuaweicloud.com", regionId)
+}
+
+// recoverHuaweiPanic SDK在鉴权失败(如获取项目ID失败)时会panic, 统一转换为错误返回
+func recoverHuaweiPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("华为云接口调用失败: %v", r)
+	}
+}
+
+// GetRegions 获取地域列表
+// API文档：https://support.huaweicloud.com/api-iam/iam_05_0001.html
+func (h *huaweiClient) GetRegions() (regionSet []*cmdb.Region, err error) {
+	defer recoverHuaweiPanic(&err)
+
+	credential := basic.NewCredentialsBuilder().
+		WithAk(h.secretID).
+		WithSk(h.secretKey).
+		Build()
+	client := iam.NewIamClient(iam.IamClientBuilder().
+		WithEndpoint(h.iamEndpoint()).
+		WithCredential(credential).
+		Build())
+
+	resp, err := client.KeystoneListRegions(&iamModel.KeystoneListRegionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	regionSet = make([]*cmdb.Region, 0)
+	if resp.Regions == nil {
+		return regionSet, nil
+	}
+	for _, r := range *resp.Regions {
+		regionName := r.Id
+		if r.Locales != nil && r.Locales.ZhCn != "" {
+			regionName = r.Locales.ZhCn
+		}
+		regionSet = append(regionSet, &cmdb.Region{
+			RegionId:   r.Id,
+			RegionName: regionName,
+		})
+	}
+	return regionSet, nil
+}
+
+// GetInstances 获取实例列表
+// API文档：https://support.huaweicloud.com/api-ecs/ecs_02_0104.html
+func (h *huaweiClient) GetInstances(regionId string) (instancesInfo []cmdb.VirtualMachine, err error) {
+	defer recoverHuaweiPanic(&err)
+
+	// 未指定项目ID, 创建客户端时SDK会通过IAM查询地域对应的项目ID
+	credential := basic.NewCredentialsBuilder().
+		WithAk(h.secretID).
+		WithSk(h.secretKey).
+		WithIamEndpointOverride(h.iamEndpoint()).
+		Build()
+	client := ecs.NewEcsClient(ecs.EcsClientBuilder().
+		WithRegion(region.NewRegion(regionId, h.ecsEndpoint(regionId))).
+		WithCredential(credential).
+		Build())
+
+	var serverList []ecsModel.ServerDetail
+
+	// offset为页码, 从1开始
+	limit := huaweiPageSize
+	for page := int32(1); ; page++ {
+		offset := page
+		resp, err := client.ListServersDetails(&ecsModel.ListServersDetailsRequest{
+			Limit:  &limit,
+			Offset: &offset,
+		})
+		if err != nil {
+			fmt.Printf("查询ECS实例列表失败，%v", err.Error())
+			return nil, err
+		}
+		if resp.Servers == nil || len(*resp.Servers) == 0 {
+			break
+		}
+		serverList = append(serverList, *resp.Servers...)
+		if resp.Count == nil || int32(len(serverList)) >= *resp.Count {
+			break
+		}
+	}
+
+	// 同步的云资产放到默认的Default分组
+	for _, e := range serverList {
+		var tree []*cmdb.TreeMenu
+		group := append(tree, &cmdb.TreeMenu{ID: 1})
+
+		var cpu, mem int
+		if e.Flavor != nil {
+			cpu, _ = strconv.Atoi(e.Flavor.Vcpus)
+			mem, _ = strconv.Atoi(e.Flavor.Ram)
+		}
+		privateAddr, publicAddr := getHuaweiInstanceIP(e.Addresses)
+
+		instancesInfo = append(instancesInfo, cmdb.VirtualMachine{
+			Groups:        group,
+			UUID:          e.Id,
+			HostName:      e.Name,
+			CPU:           cpu,
+			Mem:           mem,
+			OS:            e.Metadata["image_name"],
+			OSType:        strings.ToLower(e.Metadata["os_type"]),
+			PrivateAddr:   privateAddr,
+			PublicAddr:    publicAddr,
+			Status:        e.Status,
+			Region:        e.OSEXTAZavailabilityZone,
+			VmCreatedTime: e.Created,
+			Source:        cmdb.HuaWei,
+		})
+	}
+
+	return instancesInfo, nil
+}
+
+// getHuaweiInstanceIP 获取实例的私网和公网IP, 公网IP为floating类型的地址
+func getHuaweiInstanceIP(addresses map[string][]ecsModel.ServerAddress) (privateAddr, publicAddr string) {
+	floating := ecsModel.GetServerAddressOSEXTIPStypeEnum().FLOATING
+	for _, addrs := range addresses {
+		for _, addr := range addrs {
+			if addr.OSEXTIPStype != nil && *addr.OSEXTIPStype == floating {
+				if publicAddr == "" {
+					publicAddr = addr.Addr
+				}
+			} else if privateAddr == "" {
+				privateAddr = addr.Addr
+			}
+		}
+	}
+	return
+}
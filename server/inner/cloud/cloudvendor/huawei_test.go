@@ -0,0 +1,128 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudvendor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newHuaweiStandIn 启动一个同时模拟IAM和ECS接口的本地服务
+func newHuaweiStandIn(t *testing.T, total int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/regions", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"regions": []map[string]interface{}{
+				{"id": "cn-north-4", "type": "public", "locales": map[string]string{"zh-cn": "华北-北京四", "en-us": "CN North-Beijing4"}},
+				{"id": "cn-east-3", "type": "public", "locales": map[string]string{"zh-cn": "华东-上海一", "en-us": "CN East-Shanghai1"}},
+			},
+		})
+	})
+	mux.HandleFunc("/v3/projects", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		projects := []map[string]string{{"id": "project-" + name, "name": name}}
+		// 模拟账号未开通该地域
+		if name == "cn-east-3" {
+			projects = projects[:0]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"projects": projects})
+	})
+	mux.HandleFunc("/v1/project-cn-north-4/cloudservers/detail", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Project-Id") != "project-cn-north-4" {
+			t.Errorf("unexpected project header: %q", r.Header.Get("X-Project-Id"))
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		// 模拟较小的单页上限, 验证分页
+		if limit > 2 {
+			limit = 2
+		}
+		servers := make([]map[string]interface{}, 0)
+		for i := (page - 1) * limit; i < total && i < page*limit; i++ {
+			servers = append(servers, map[string]interface{}{
+				"id":     fmt.Sprintf("ecs-%d", i),
+				"name":   fmt.Sprintf("node-%d", i),
+				"status": "ACTIVE",
+				"flavor": map[string]string{"id": "s6.large.2", "vcpus": "2", "ram": "4096"},
+				"addresses": map[string]interface{}{
+					"vpc-1": []map[string]string{
+						{"addr": fmt.Sprintf("192.168.0.%d", i), "version": "4", "OS-EXT-IPS:type": "fixed"},
+						{"addr": fmt.Sprintf("121.36.0.%d", i), "version": "4", "OS-EXT-IPS:type": "floating"},
+					},
+				},
+				"metadata":                    map[string]string{"os_type": "Linux", "image_name": "CentOS 7.6 64bit"},
+				"OS-EXT-AZ:availability_zone": "cn-north-4a",
+				"created":                     "2021-10-01T08:00:00Z",
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"count": total, "servers": servers})
+	})
+	return httptest.NewServer(mux)
+}
+
+func newHuaweiTestClient(server *httptest.Server, ak string) VendorClient {
+	proto := &huaweiClient{endpoint: server.URL}
+	return proto.NewVendorClient(ak, "test-key")
+}
+
+func TestHuaweiGetRegions(t *testing.T) {
+	server := newHuaweiStandIn(t, 0)
+	defer server.Close()
+
+	regionSet, err := newHuaweiTestClient(server, "regions-ak").GetRegions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regionSet) != 2 || regionSet[0].RegionId != "cn-north-4" || regionSet[0].RegionName != "华北-北京四" {
+		t.Fatalf("unexpected regions: %#v", regionSet)
+	}
+}
+
+func TestHuaweiGetInstances(t *testing.T) {
+	server := newHuaweiStandIn(t, 5)
+	defer server.Close()
+
+	instancesInfo, err := newHuaweiTestClient(server, "instances-ak").GetInstances("cn-north-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instancesInfo) != 5 {
+		t.Fatalf("expected 5 instances across pages, got %d", len(instancesInfo))
+	}
+
+	vm := instancesInfo[3]
+	if vm.UUID != "ecs-3" || vm.HostName != "node-3" || vm.PrivateAddr != "192.168.0.3" || vm.PublicAddr != "121.36.0.3" {
+		t.Fatalf("unexpected instance: %#v", vm)
+	}
+	if vm.CPU != 2 || vm.Mem != 4096 || vm.OSType != "linux" || vm.Region != "cn-north-4a" || vm.Source != "huawei" {
+		t.Fatalf("unexpected instance spec: %#v", vm)
+	}
+}
+
+func TestHuaweiGetInstancesUnknownProject(t *testing.T) {
+	server := newHuaweiStandIn(t, 0)
+	defer server.Close()
+
+	// 查询不到项目ID时SDK会panic, 应转换为错误返回
+	if _, err := newHuaweiTestClient(server, "unknown-ak").GetInstances("cn-east-3"); err == nil {
+		t.Fatal("expected error for region without project")
+	}
+}
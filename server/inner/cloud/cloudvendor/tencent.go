@@ -15,3 +15,173 @@ limitations under the License.
 */
 
 package cloudvendor
+
+import (
+	"fmt"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	"kubespace/server/models/cmdb"
+	"strings"
+)
+
+func init() {
+	Register(cmdb.Tencent, &tencentClient{vendorName: cmdb.Tencent})
+}
+
+// tencentPageSize DescribeInstances单页最大数量
+const tencentPageSize int64 = 100
+
+type tencentClient struct {
+	vendorName string
+	secretID   string
+	secretKey  string
+	// endpoint、scheme 为空时使用SDK默认的接入地址, 测试时可指向本地服务
+	endpoint string
+	scheme   string
+}
+
+// NewVendorClient 创建云厂商客户端
+func (t *tencentClient) NewVendorClient(secretID, secretKey string) VendorClient {
+	return &tencentClient{
+		vendorName: cmdb.Tencent,
+		secretID:   secretID,
+		secretKey:  secretKey,
+		endpoint:   t.endpoint,
+		scheme:     t.scheme,
+	}
+}
+
+// newCvmClient 创建指定地域的CVM客户端
+func (t *tencentClient) newCvmClient(region string) (*cvm.Client, error) {
+	credential := common.NewCredential(t.secretID, t.secretKey)
+	cpf := profile.NewClientProfile()
+	if t.endpoint != "" {
+		cpf.HttpProfile.Endpoint = t.endpoint
+	}
+	if t.scheme != "" {
+		cpf.HttpProfile.Scheme = t.scheme
+	}
+	return cvm.NewClient(credential, region, cpf)
+}
+
+// GetRegions 获取地域列表
+// API文档：https://cloud.tencent.com/document/api/213/15708
+func (t *tencentClient) GetRegions() ([]*cmdb.Region, error) {
+	client, err := t.newCvmClient("ap-guangzhou")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.DescribeRegions(cvm.NewDescribeRegionsRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	regionSet := make([]*cmdb.Region, 0)
+	for _, region := range resp.Response.RegionSet {
+		regionSet = append(regionSet, &cmdb.Region{
+			RegionId:   tcString(region.Region),
+			RegionName: tcString(region.RegionName),
+		})
+	}
+	return regionSet, nil
+}
+
+// GetInstances 获取实例列表
+// API文档：https://cloud.tencent.com/document/api/213/15728
+func (t *tencentClient) GetInstances(region string) ([]cmdb.VirtualMachine, error) {
+	client, err := t.newCvmClient(region)
+	if err != nil {
+		fmt.Printf("创建客户端连接失败，%v", err.Error())
+		return nil, err
+	}
+
+	var (
+		cvmList       []*cvm.Instance
+		instancesInfo []cmdb.VirtualMachine
+	)
+
+	request := cvm.NewDescribeInstancesRequest()
+	request.Limit = common.Int64Ptr(tencentPageSize)
+	for offset := int64(0); ; offset += tencentPageSize {
+		request.Offset = common.Int64Ptr(offset)
+		resp, err := client.DescribeInstances(request)
+		if err != nil {
+			fmt.Printf("查询CVM实例列表失败，%v", err.Error())
+			return nil, err
+		}
+		cvmList = append(cvmList, resp.Response.InstanceSet...)
+		if len(resp.Response.InstanceSet) == 0 || int64(len(cvmList)) >= tcInt64(resp.Response.TotalCount) {
+			break
+		}
+	}
+
+	// 同步的云资产放到默认的Default分组
+	for _, e := range cvmList {
+		var tree []*cmdb.TreeMenu
+		group := append(tree, &cmdb.TreeMenu{ID: 1})
+
+		var bandWidth int64
+		if e.InternetAccessible != nil {
+			bandWidth = tcInt64(e.InternetAccessible.InternetMaxBandwidthOut)
+		}
+		var zone string
+		if e.Placement != nil {
+			zone = tcString(e.Placement.Zone)
+		}
+		osName := tcString(e.OsName)
+
+		instancesInfo = append(instancesInfo, cmdb.VirtualMachine{
+			Groups:      group,
+			UUID:        tcString(e.InstanceId),
+			HostName:    tcString(e.InstanceName),
+			CPU:         int(tcInt64(e.CPU)),
+			Mem:         int(tcInt64(e.Memory)) * 1024, // 腾讯云返回GB
+			OS:          osName,
+			OSType:      getTencentOSType(osName),
+			PrivateAddr: getTencentInstanceIP(e.PrivateIpAddresses),
+			PublicAddr:  getTencentInstanceIP(e.PublicIpAddresses),
+			// 腾讯云没有SN, 使用实例的全局唯一ID
+			SN:            tcString(e.Uuid),
+			BandWidth:     int(bandWidth),
+			Status:        tcString(e.InstanceState),
+			Region:        zone,
+			VmCreatedTime: tcString(e.CreatedTime),
+			VmExpiredTime: tcString(e.ExpiredTime),
+			Source:        cmdb.Tencent,
+		})
+	}
+
+	return instancesInfo, nil
+}
+
+// getTencentInstanceIP 获取实例IP
+func getTencentInstanceIP(ip []*string) string {
+	if len(ip) == 0 {
+		return ""
+	}
+	return tcString(ip[0])
+}
+
+// getTencentOSType 腾讯云只返回系统名称, 根据名称判断系统类型
+func getTencentOSType(osName string) string {
+	if strings.Contains(strings.ToLower(osName), "windows") {
+		return "windows"
+	}
+	return "linux"
+}
+
+func tcString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func tcInt64(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
@@ -0,0 +1,123 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudvendor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTencentStandIn 启动一个模拟腾讯云API的本地服务, 按X-TC-Action返回地域或分页的实例
+func newTencentStandIn(t *testing.T, total int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "TC3-HMAC-SHA256 Credential=test-id/") {
+			t.Errorf("unexpected authorization header: %q", r.Header.Get("Authorization"))
+		}
+		var resp interface{}
+		switch r.Header.Get("X-TC-Action") {
+		case "DescribeRegions":
+			resp = map[string]interface{}{
+				"TotalCount": 2,
+				"RegionSet": []map[string]string{
+					{"Region": "ap-guangzhou", "RegionName": "华南地区(广州)", "RegionState": "AVAILABLE"},
+					{"Region": "ap-shanghai", "RegionName": "华东地区(上海)", "RegionState": "AVAILABLE"},
+				},
+			}
+		case "DescribeInstances":
+			var req struct {
+				Offset int
+				Limit  int
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Error(err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			instances := make([]map[string]interface{}, 0)
+			for i := req.Offset; i < total && i < req.Offset+req.Limit; i++ {
+				instances = append(instances, map[string]interface{}{
+					"InstanceId":         fmt.Sprintf("ins-%04d", i),
+					"InstanceName":       fmt.Sprintf("host-%d", i),
+					"CPU":                2,
+					"Memory":             4,
+					"OsName":             "TencentOS Server 3.1",
+					"PrivateIpAddresses": []string{fmt.Sprintf("10.0.0.%d", i%250)},
+					"InstanceState":      "RUNNING",
+					"Placement":          map[string]interface{}{"Zone": "ap-guangzhou-3"},
+					"InternetAccessible": map[string]interface{}{"InternetMaxBandwidthOut": 5},
+					"CreatedTime":        "2021-10-01T00:00:00Z",
+				})
+			}
+			resp = map[string]interface{}{"TotalCount": total, "InstanceSet": instances}
+		default:
+			resp = map[string]interface{}{
+				"Error": map[string]string{"Code": "InvalidAction", "Message": "unknown action"},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"Response": resp})
+	}))
+}
+
+func newTencentTestClient(server *httptest.Server) VendorClient {
+	proto := &tencentClient{
+		endpoint: strings.TrimPrefix(server.URL, "http://"),
+		scheme:   "HTTP",
+	}
+	return proto.NewVendorClient("test-id", "test-key")
+}
+
+func TestTencentGetRegions(t *testing.T) {
+	server := newTencentStandIn(t, 0)
+	defer server.Close()
+
+	regionSet, err := newTencentTestClient(server).GetRegions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regionSet) != 2 || regionSet[1].RegionId != "ap-shanghai" || regionSet[1].RegionName != "华东地区(上海)" {
+		t.Fatalf("unexpected regions: %#v", regionSet)
+	}
+}
+
+func TestTencentGetInstances(t *testing.T) {
+	// 超过一页, 验证分页
+	server := newTencentStandIn(t, 150)
+	defer server.Close()
+
+	instancesInfo, err := newTencentTestClient(server).GetInstances("ap-guangzhou")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instancesInfo) != 150 {
+		t.Fatalf("expected 150 instances, got %d", len(instancesInfo))
+	}
+
+	vm := instancesInfo[120]
+	if vm.UUID != "ins-0120" || vm.HostName != "host-120" || vm.PrivateAddr != "10.0.0.120" {
+		t.Fatalf("unexpected instance: %#v", vm)
+	}
+	if vm.Mem != 4096 || vm.CPU != 2 || vm.BandWidth != 5 || vm.Region != "ap-guangzhou-3" {
+		t.Fatalf("unexpected instance spec: %#v", vm)
+	}
+	if vm.OSType != "linux" || vm.Source != "tencent" || len(vm.Groups) != 1 {
+		t.Fatalf("unexpected instance meta: %#v", vm)
+	}
+}
@@ -20,8 +20,12 @@ import (
 	phttp "kubespace/server/http"
 	"kubespace/server/middleware"
 	"kubespace/server/models"
+	"kubespace/server/pkg/utils"
 	"kubespace/server/routers"
 	"kubespace/server/routers/cmdb"
+	"kubespace/server/services"
+	cmdbService "kubespace/server/services/cmdb"
+	"kubespace/server/tasks"
 	"kubespace/server/tools"
 	"os"
 	"os/signal"
@@ -41,8 +45,13 @@ func main() {
 
 	common.VP = tools.Viper()      // 初始化Viper
 	common.LOG = tools.Zap()       // 初始化zap日志库
+	initSecretKey()                // 初始化加密存储凭证的密钥
 	common.DB = common.GormMysql() // gorm连接数据库
 	common.MysqlTables(common.DB)  // 初始化表
+
+	// 迁移明文存储或使用旧密钥加密的凭证
+	services.EncryptCloudAccounts()
+	cmdbService.EncryptLegacySSHSecrets()
 	// 程序结束前关闭数据库链接
 	db, _ := common.DB.DB()
 	defer db.Close()
@@ -53,6 +62,17 @@ func main() {
 	InitServer()
 }
 
+// initSecretKey 未配置加密密钥时不能读写加密存储的凭证, 直接退出
+func initSecretKey() {
+	secretKey := common.CONFIG.System.SecretKey
+	if key := os.Getenv(common.SecretKeyEnv); key != "" {
+		secretKey = key
+	}
+	if err := utils.SetSecretKey(secretKey); err != nil {
+		panic(fmt.Errorf("加密密钥配置错误, 请配置system.secret-key或环境变量%s: %v", common.SecretKeyEnv, err))
+	}
+}
+
 func InitServer() {
 
 	r := gin.Default()
@@ -95,8 +115,8 @@ func InitServer() {
 
 	}
 	// 任务调度
-	//go tasks.TaskBeta()
-	//go tasks.TaskWorker()
+	go tasks.TaskBeta()
+	go tasks.TaskWorker()
 	address := fmt.Sprintf(":%d", common.CONFIG.System.Addr)
 	err := r.Run(address)
 
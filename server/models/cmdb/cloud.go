@@ -33,6 +33,22 @@ const (
 // SupportedCloudVendors 实现了相应的云厂商插件
 var SupportedCloudVendors = []string{AliYun, Tencent, HuaWei, AWS}
 
+// HostSourceManual 手动录入或导入的主机(物理机、IDC机器)
+const HostSourceManual string = "manual"
+
+// IsCloudSource 主机是否由云同步维护
+func IsCloudSource(source string) bool {
+	for _, vendor := range SupportedCloudVendors {
+		if source == vendor {
+			return true
+		}
+	}
+	return false
+}
+
+// VmStatusDestroyed 云上已释放的实例状态
+const VmStatusDestroyed string = "Destroyed"
+
 // 云同步任务同步状态
 const (
 	CloudSyncSuccess    string = "cloud_sync_success"
@@ -40,6 +56,14 @@ const (
 	CloudSyncInProgress string = "cloud_sync_in_progress"
 )
 
+// 云账号同步状态, 对应CloudPlatform.Status
+const (
+	PlatformSyncNone int = iota
+	PlatformSyncInProgress
+	PlatformSyncSuccess
+	PlatformSyncFail
+)
+
 // Region 云资产地域信息
 type Region struct {
 	RegionId   string `json:"region"`
@@ -63,6 +87,8 @@ type CloudPlatform struct {
 	DeletedAt gorm.DeletedAt   `json:"-"`
 	UpdatedAt models.LocalTime `json:"updated_at"`
 	SyncTime  *time.Time       `json:"sync_time"`
+	SyncCron  string           `json:"sync_cron" gorm:"comment:'同步周期(cron表达式), 为空时使用配置文件中的默认周期'"`
+	Encrypted bool             `json:"-" gorm:"comment:'AccessKey、SecretKey是否已加密存储'"`
 	//VirtualMachines []*VirtualMachine `gorm:"many2many:cloud_platform_virtual_machines;"`
 }
 
@@ -76,8 +102,10 @@ type VirtualMachine struct {
 	Groups        []*TreeMenu      `gorm:"many2many:hosts_group_virtual_machines" json:"groups"`
 	UUID          string           `json:"uuid"`
 	UserName      string           `gorm:"comment:'用户';column:username" json:"-"`
-	Password      string           `gorm:"comment:'密码'" json:"-"`
-	Port          string           `gorm:"comment:'端口';default:22" json:"-"`
+	Password      string           `gorm:"comment:'密码(加密存储)'" json:"-"`
+	Port          string           `gorm:"comment:'端口';default:22" json:"port"`
+	PrivateKey    string           `gorm:"comment:'私钥(加密存储)';type:text" json:"-"`
+	CredentialId  *uint            `gorm:"index;comment:'SSH凭证Id, 优先于主机自身的密码和私钥'" json:"credential_id"`
 	HostName      string           `gorm:"comment:'主机名';column:hostname" json:"hostname"`
 	CPU           int              `gorm:"comment:'CPU'" json:"cpu"`
 	Mem           int              `gorm:"comment:'内存'" json:"memory"` // MB
@@ -93,6 +121,10 @@ type VirtualMachine struct {
 	Source        string           `json:"source"`
 	VmCreatedTime string           `json:"vm_created_time"`
 	VmExpiredTime string           `json:"vm_expired_time"`
+	PlatformId    int              `gorm:"index;comment:'云账号ID'" json:"platform_id"`
+	RegionId      string           `gorm:"comment:'地域';size:64" json:"region_id"`
+	DestroyedTime *time.Time       `gorm:"comment:'云上释放时间'" json:"destroyed_time"`
+	Remark        string           `gorm:"comment:'备注'" json:"remark"`
 	CreatedAt     models.LocalTime `json:"created_at"`
 	DeletedAt     gorm.DeletedAt   `json:"-"`
 	UpdatedAt     models.LocalTime `json:"updated_at"`
@@ -101,3 +133,25 @@ type VirtualMachine struct {
 func (v VirtualMachine) TableName() string {
 	return "cloud_virtual_machine"
 }
+
+// CloudSyncRecord 云资产同步记录, 每个云账号每次同步生成一条
+type CloudSyncRecord struct {
+	ID         int              `json:"id" gorm:"column:id;AUTO_INCREMENT;comment:主键"`
+	PlatformId int              `json:"platform_id" gorm:"index;comment:'云账号ID'"`
+	Trigger    string           `json:"trigger" gorm:"size:16;comment:'触发方式'"`
+	Status     string           `json:"status" gorm:"size:32;comment:'同步结果'"`
+	Added      int              `json:"added" gorm:"comment:'新增数量'"`
+	Updated    int              `json:"updated" gorm:"comment:'更新数量'"`
+	Removed    int              `json:"removed" gorm:"comment:'标记销毁数量'"`
+	Deleted    int              `json:"deleted" gorm:"comment:'超过保留期删除数量'"`
+	Errors     int              `json:"errors" gorm:"comment:'错误数量'"`
+	ErrMsg     string           `json:"err_msg" gorm:"type:text;comment:'错误信息'"`
+	StartTime  models.LocalTime `json:"start_time" gorm:"index;comment:'开始时间'"`
+	EndTime    models.LocalTime `json:"end_time" gorm:"comment:'结束时间'"`
+	Duration   float64          `json:"duration" gorm:"comment:'耗时(秒)'"`
+	CreatedAt  models.LocalTime `json:"created_at"`
+}
+
+func (c CloudSyncRecord) TableName() string {
+	return "cloud_sync_record"
+}
@@ -0,0 +1,112 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"gorm.io/gorm"
+	"kubespace/server/models"
+)
+
+// CloudResource 云资源公共字段, 按 账号+资源ID 唯一
+type CloudResource struct {
+	ID         int              `json:"id" gorm:"column:id;AUTO_INCREMENT;comment:主键"`
+	PlatformId int              `json:"platform_id" gorm:"index;comment:'云账号ID'"`
+	RegionId   string           `json:"region_id" gorm:"index;size:64;comment:'地域'"`
+	ZoneId     string           `json:"zone_id" gorm:"size:64;comment:'可用区'"`
+	UUID       string           `json:"uuid" gorm:"index;size:128;comment:'云上资源ID'"`
+	Name       string           `json:"name" gorm:"comment:'名称'"`
+	Status     string           `json:"status" gorm:"size:64;comment:'状态'"`
+	Source     string           `json:"source" gorm:"size:32;comment:'云厂商'"`
+	CreateTime string           `json:"create_time" gorm:"size:64;comment:'云上创建时间'"`
+	CreatedAt  models.LocalTime `json:"created_at"`
+	UpdatedAt  models.LocalTime `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt   `json:"-"`
+}
+
+// CloudDisk 云硬盘
+type CloudDisk struct {
+	CloudResource
+	Size        int    `json:"size" gorm:"comment:'容量(GB)'"`
+	Category    string `json:"category" gorm:"size:64;comment:'磁盘种类'"`
+	Type        string `json:"type" gorm:"size:32;comment:'系统盘或数据盘'"`
+	InstanceId  string `json:"instance_id" gorm:"size:128;comment:'挂载的实例ID'"`
+	Device      string `json:"device" gorm:"size:64;comment:'挂载点'"`
+	Encrypted   bool   `json:"encrypted" gorm:"comment:'是否加密'"`
+	ExpiredTime string `json:"expired_time" gorm:"size:64;comment:'过期时间'"`
+}
+
+func (d CloudDisk) TableName() string {
+	return "cloud_disk"
+}
+
+// CloudLoadBalancer 负载均衡(SLB/CLB/ELB)
+type CloudLoadBalancer struct {
+	CloudResource
+	Address     string `json:"address" gorm:"size:64;comment:'服务地址'"`
+	AddressType string `json:"address_type" gorm:"size:32;comment:'地址类型, 公网或私网'"`
+	NetworkType string `json:"network_type" gorm:"size:32;comment:'网络类型'"`
+	VpcId       string `json:"vpc_id" gorm:"size:128;comment:'VPC ID'"`
+	SubnetId    string `json:"subnet_id" gorm:"size:128;comment:'子网ID'"`
+	Spec        string `json:"spec" gorm:"size:64;comment:'规格'"`
+	BandWidth   int    `json:"bandwidth" gorm:"column:bandwidth;comment:'带宽'"`
+}
+
+func (l CloudLoadBalancer) TableName() string {
+	return "cloud_load_balancer"
+}
+
+// CloudDatabase 云数据库实例
+type CloudDatabase struct {
+	CloudResource
+	Engine        string `json:"engine" gorm:"size:32;comment:'数据库类型'"`
+	EngineVersion string `json:"engine_version" gorm:"size:32;comment:'数据库版本'"`
+	InstanceClass string `json:"instance_class" gorm:"size:64;comment:'实例规格'"`
+	Address       string `json:"address" gorm:"comment:'连接地址'"`
+	NetworkType   string `json:"network_type" gorm:"size:32;comment:'网络类型'"`
+	VpcId         string `json:"vpc_id" gorm:"size:128;comment:'VPC ID'"`
+	SubnetId      string `json:"subnet_id" gorm:"size:128;comment:'子网ID'"`
+	ExpiredTime   string `json:"expired_time" gorm:"size:64;comment:'过期时间'"`
+}
+
+func (d CloudDatabase) TableName() string {
+	return "cloud_database"
+}
+
+// CloudVpc 专有网络
+type CloudVpc struct {
+	CloudResource
+	CidrBlock   string `json:"cidr_block" gorm:"size:64;comment:'网段'"`
+	IsDefault   bool   `json:"is_default" gorm:"comment:'是否默认VPC'"`
+	Description string `json:"description" gorm:"comment:'描述'"`
+}
+
+func (v CloudVpc) TableName() string {
+	return "cloud_vpc"
+}
+
+// CloudSubnet 子网(阿里云交换机)
+type CloudSubnet struct {
+	CloudResource
+	VpcId            string `json:"vpc_id" gorm:"index;size:128;comment:'VPC ID'"`
+	CidrBlock        string `json:"cidr_block" gorm:"size:64;comment:'网段'"`
+	AvailableIpCount int64  `json:"available_ip_count" gorm:"comment:'可用IP数'"`
+	IsDefault        bool   `json:"is_default" gorm:"comment:'是否默认子网'"`
+}
+
+func (s CloudSubnet) TableName() string {
+	return "cloud_subnet"
+}
@@ -39,16 +39,96 @@ func (v SSHGlobalConfig) TableName() string {
 
 type SSHRecord struct {
 	gorm.Model
-	ConnectID   string           `gorm:"comment:'连接标识';size:64" json:"connect_id"`
-	UserName    string           `gorm:"comment:'系统用户名';size:128" json:"user_name"`
+	ConnectID   string           `gorm:"comment:'连接标识';size:64;index" json:"connect_id"`
+	UserName    string           `gorm:"comment:'系统用户名';size:128;index" json:"user_name"`
+	Operator    string           `gorm:"comment:'平台用户';size:128;index" json:"operator"`
 	HostName    string           `gorm:"comment:'主机名';size:128" json:"host_name"`
 	ConnectTime models.LocalTime `gorm:"index;comment:'接入时间'" json:"connect_time"`
 	LogoutTime  models.LocalTime `gorm:"index;comment:'注销时间'" json:"logout_time"`
-	Records     []byte           `json:"records" gorm:"type:longblob;comment:'操作记录(二进制存储)';size:128"`
+	Records     []byte           `json:"-" gorm:"type:longblob;comment:'操作记录(二进制存储)';size:128"`
+	StoragePath string           `gorm:"comment:'录像存储路径, 为空时录像存储在records字段';size:256" json:"storage_path"`
+	Size        int              `gorm:"comment:'录像压缩后大小(字节)'" json:"size"`
+	Duration    float64          `gorm:"comment:'录像时长(秒)'" json:"duration"`
 	HostId      uint             `gorm:"comment:'主机Id外键'" json:"host_id"`
 	Host        VirtualMachine   `gorm:"foreignkey:HostId" json:"host"`
+	Commands    []SSHCommand     `gorm:"foreignkey:RecordId" json:"commands,omitempty"`
 }
 
 func (s SSHRecord) TableName() string {
 	return "ssh_record"
 }
+
+// SSHCommand 从SSH会话输入中还原的命令, 用于命令审计
+type SSHCommand struct {
+	gorm.Model
+	RecordId  uint             `gorm:"comment:'会话录像Id外键';index" json:"record_id"`
+	ConnectID string           `gorm:"comment:'连接标识';size:64;index" json:"connect_id"`
+	UserName  string           `gorm:"comment:'系统用户名';size:128;index" json:"user_name"`
+	Operator  string           `gorm:"comment:'平台用户';size:128;index" json:"operator"`
+	HostId    uint             `gorm:"comment:'主机Id';index" json:"host_id"`
+	HostName  string           `gorm:"comment:'主机名';size:128" json:"host_name"`
+	Command   string           `gorm:"comment:'命令';type:text" json:"command"`
+	ExecTime  models.LocalTime `gorm:"index;comment:'执行时间'" json:"exec_time"`
+	Offset    float64          `gorm:"comment:'距会话开始的秒数, 用于定位录像'" json:"offset"`
+}
+
+func (s SSHCommand) TableName() string {
+	return "ssh_command"
+}
+
+// SSHCommandRule 命令黑名单规则, 未指定分组或角色时对全部主机或角色生效
+type SSHCommandRule struct {
+	gorm.Model
+	Name    string        `gorm:"comment:'规则名称';size:128" json:"name"`
+	Pattern string        `gorm:"comment:'命令匹配的正则表达式';size:1024" json:"pattern"`
+	Enable  bool          `gorm:"comment:'是否启用'" json:"enable"`
+	Remark  string        `gorm:"comment:'备注';size:256" json:"remark"`
+	Groups  []*TreeMenu   `gorm:"many2many:ssh_command_rule_groups" json:"groups"`
+	Roles   []models.Role `gorm:"many2many:ssh_command_rule_roles" json:"roles"`
+}
+
+func (s SSHCommandRule) TableName() string {
+	return "ssh_command_rule"
+}
+
+// SSHCommandBlock 被命令黑名单拦截的命令, 用于审计
+type SSHCommandBlock struct {
+	gorm.Model
+	RuleId    uint             `gorm:"comment:'命中的规则Id';index" json:"rule_id"`
+	RuleName  string           `gorm:"comment:'命中的规则名称';size:128" json:"rule_name"`
+	ConnectID string           `gorm:"comment:'连接标识';size:64;index" json:"connect_id"`
+	Operator  string           `gorm:"comment:'平台用户';size:128;index" json:"operator"`
+	UserName  string           `gorm:"comment:'系统用户名';size:128" json:"user_name"`
+	HostId    uint             `gorm:"comment:'主机Id';index" json:"host_id"`
+	HostName  string           `gorm:"comment:'主机名';size:128" json:"host_name"`
+	Command   string           `gorm:"comment:'被拦截的命令';type:text" json:"command"`
+	BlockTime models.LocalTime `gorm:"index;comment:'拦截时间'" json:"block_time"`
+}
+
+func (s SSHCommandBlock) TableName() string {
+	return "ssh_command_block"
+}
+
+const (
+	SSHCredentialPassword    = "password"
+	SSHCredentialKey         = "key"
+	SSHCredentialCertificate = "certificate"
+)
+
+// SSHCredential SSH凭证, 密码、私钥及私钥密码加密存储, 可分配给主机或主机分组
+type SSHCredential struct {
+	gorm.Model
+	Name        string `gorm:"comment:'凭证名称';size:128;index" json:"name"`
+	Type        string `gorm:"comment:'凭证类型: password、key、certificate';size:32" json:"type"`
+	UserName    string `gorm:"comment:'登录用户, 为空时使用主机配置的用户';size:128" json:"user_name"`
+	Password    string `gorm:"comment:'密码(加密存储)';type:text" json:"-"`
+	PrivateKey  string `gorm:"comment:'私钥(加密存储)';type:text" json:"-"`
+	Passphrase  string `gorm:"comment:'私钥密码(加密存储)';type:text" json:"-"`
+	Certificate string `gorm:"comment:'OpenSSH证书';type:text" json:"certificate"`
+	Fingerprint string `gorm:"comment:'公钥指纹';size:128" json:"fingerprint"`
+	Remark      string `gorm:"comment:'备注'" json:"remark"`
+}
+
+func (s SSHCredential) TableName() string {
+	return "ssh_credential"
+}
@@ -22,6 +22,7 @@ type TreeMenu struct {
 	ParentId        int64             `gorm:"default:0" json:"parent_id"`
 	Hide            int               `gorm:"default:0" json:"hide"`
 	SortId          int               `json:"sort_id"`
+	CredentialId    *uint             `gorm:"comment:'SSH凭证Id, 子分组和分组内主机未指定凭证时继承'" json:"credential_id"`
 	VirtualMachines []*VirtualMachine `gorm:"many2many:hosts_group_virtual_machines" json:"cloud_virtual_machine"`
 }
 
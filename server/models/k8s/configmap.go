@@ -20,3 +20,11 @@ type ConfigMapData struct {
 	Namespace string `json:"namespace"  binding:"required"`
 	Name      string `json:"name" binding:"required"`
 }
+
+// ConfigMapForm 创建或更新ConfigMap, 更新时Data整体替换
+type ConfigMapForm struct {
+	Namespace string            `json:"namespace" binding:"required"`
+	Name      string            `json:"name" binding:"required"`
+	Labels    map[string]string `json:"labels"`
+	Data      map[string]string `json:"data"`
+}
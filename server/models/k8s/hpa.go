@@ -0,0 +1,26 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import autoscaling "k8s.io/api/autoscaling/v2beta2"
+
+// HorizontalPodAutoscalerData 创建或更新HPA, spec对应autoscaling/v2beta2
+type HorizontalPodAutoscalerData struct {
+	Namespace string                                  `json:"namespace" binding:"required"`
+	Name      string                                  `json:"name" binding:"required"`
+	Spec      autoscaling.HorizontalPodAutoscalerSpec `json:"spec"`
+}
@@ -27,3 +27,10 @@ type ScaleJob struct {
 	// Job并行运行的Pod数量
 	Number *int32 `json:"number" binding:"required"`
 }
+
+type CronJobSuspend struct {
+	Namespace string `json:"namespace" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	// Suspend true暂停调度, false恢复调度
+	Suspend *bool `json:"suspend" binding:"required"`
+}
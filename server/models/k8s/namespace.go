@@ -0,0 +1,46 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import v1 "k8s.io/api/core/v1"
+
+// NamespaceData 创建命名空间或修改命名空间的标签、注解
+type NamespaceData struct {
+	Name        string            `json:"name" binding:"required"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// NamespaceDelete 删除命名空间, Token通过 namespace/deleteToken 获取
+type NamespaceDelete struct {
+	Name  string `json:"name" binding:"required"`
+	Token string `json:"token" binding:"required"`
+}
+
+// ResourceQuotaData 创建或更新ResourceQuota
+type ResourceQuotaData struct {
+	Namespace string               `json:"namespace" binding:"required"`
+	Name      string               `json:"name" binding:"required"`
+	Spec      v1.ResourceQuotaSpec `json:"spec"`
+}
+
+// LimitRangeData 创建或更新LimitRange
+type LimitRangeData struct {
+	Namespace string            `json:"namespace" binding:"required"`
+	Name      string            `json:"name" binding:"required"`
+	Spec      v1.LimitRangeSpec `json:"spec"`
+}
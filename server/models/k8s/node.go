@@ -126,3 +126,18 @@ type Unschedulable bool
 type NodeIP string
 
 type UID string
+
+// NodeDrain 排空节点参数, 与kubectl drain的参数对应
+type NodeDrain struct {
+	NodeName string `json:"nodeName" binding:"required"`
+	// Force 是否驱逐不受控制器管理的Pod
+	Force bool `json:"force"`
+	// DeleteEmptyDirData 是否驱逐使用emptyDir的Pod(数据会丢失)
+	DeleteEmptyDirData bool `json:"deleteEmptyDirData"`
+	// GracePeriodSeconds Pod优雅退出时间, 为空时使用Pod自身的terminationGracePeriodSeconds
+	GracePeriodSeconds *int `json:"gracePeriodSeconds"`
+	// TimeoutSeconds 排空超时时间, 为0时使用默认值
+	TimeoutSeconds int `json:"timeoutSeconds"`
+	// DeleteNode 排空成功后是否将节点从集群中移除
+	DeleteNode bool `json:"deleteNode"`
+}
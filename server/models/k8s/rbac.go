@@ -0,0 +1,44 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import rbac "k8s.io/api/rbac/v1"
+
+// ServiceAccountData 创建ServiceAccount, RoleName不为空时在同一命名空间绑定该角色
+type ServiceAccountData struct {
+	Namespace string `json:"namespace" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	// RoleKind Role或ClusterRole, 默认ClusterRole
+	RoleKind string `json:"roleKind"`
+	RoleName string `json:"roleName"`
+}
+
+// RoleData 创建或更新Role和ClusterRole, ClusterRole忽略Namespace
+type RoleData struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name" binding:"required"`
+	Rules     []rbac.PolicyRule `json:"rules"`
+}
+
+// RoleBindingData 创建或更新RoleBinding和ClusterRoleBinding, ClusterRoleBinding忽略Namespace.
+// 绑定创建后RoleRef不能修改, 更新时只修改Subjects
+type RoleBindingData struct {
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name" binding:"required"`
+	RoleRef   rbac.RoleRef   `json:"roleRef"`
+	Subjects  []rbac.Subject `json:"subjects"`
+}
@@ -0,0 +1,47 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+// ContainerImage 容器名称及新镜像
+type ContainerImage struct {
+	Container string `json:"container" binding:"required"`
+	Image     string `json:"image" binding:"required"`
+}
+
+// RolloutImage 修改工作负载的镜像, Kind为deployment、statefulset或daemonset
+type RolloutImage struct {
+	Kind        string           `json:"kind" binding:"required"`
+	Namespace   string           `json:"namespace" binding:"required"`
+	Name        string           `json:"name" binding:"required"`
+	Images      []ContainerImage `json:"images" binding:"required,min=1,dive"`
+	ChangeCause string           `json:"changeCause"`
+}
+
+// RolloutTarget 暂停或恢复发布的工作负载
+type RolloutTarget struct {
+	Kind      string `json:"kind" binding:"required"`
+	Namespace string `json:"namespace" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+}
+
+// RolloutRollback StatefulSet或DaemonSet回滚, Revision为0时回滚到上一个版本
+type RolloutRollback struct {
+	Kind      string `json:"kind" binding:"required"`
+	Namespace string `json:"namespace" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	Revision  *int64 `json:"revision" binding:"required"`
+}
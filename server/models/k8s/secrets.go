@@ -20,3 +20,32 @@ type SecretsData struct {
 	Namespace string `json:"namespace"  binding:"required"`
 	Name      string `json:"name" binding:"required"`
 }
+
+// SecretTLS kubernetes.io/tls类型的证书和私钥, PEM格式
+type SecretTLS struct {
+	Cert string `json:"cert" binding:"required"`
+	Key  string `json:"key" binding:"required"`
+}
+
+// SecretDockerConfig kubernetes.io/dockerconfigjson类型的镜像仓库认证信息
+type SecretDockerConfig struct {
+	Server   string `json:"server" binding:"required"`
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email"`
+}
+
+// SecretForm 创建或更新Secret, 按Type读取对应字段, 更新时不允许修改类型
+type SecretForm struct {
+	Namespace string `json:"namespace" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	// Type Opaque、kubernetes.io/tls或kubernetes.io/dockerconfigjson, 为空时使用Opaque
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels"`
+	// Data Opaque类型的明文键值, 由服务端编码. 更新时只修改填写的键, 其余键保持不变
+	Data map[string]string `json:"data"`
+	// RemoveKeys 更新Opaque类型时删除的键
+	RemoveKeys   []string            `json:"removeKeys"`
+	TLS          *SecretTLS          `json:"tls"`
+	DockerConfig *SecretDockerConfig `json:"dockerConfig"`
+}
@@ -20,3 +20,58 @@ type ServiceData struct {
 	Namespace string `json:"namespace"  binding:"required"`
 	Name      string `json:"name" binding:"required"`
 }
+
+// ServicePort Service端口, TargetPort可以是端口号或容器端口名称
+type ServicePort struct {
+	Name       string `json:"name"`
+	Protocol   string `json:"protocol"`
+	Port       int32  `json:"port" binding:"required"`
+	TargetPort string `json:"targetPort"`
+	NodePort   int32  `json:"nodePort"`
+}
+
+// ServiceForm 创建或更新Service
+type ServiceForm struct {
+	Namespace string `json:"namespace" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	// Type ClusterIP、NodePort、LoadBalancer或ExternalName, 为空时使用ClusterIP
+	Type string `json:"type"`
+	// Headless 为true时创建clusterIP为None的Headless Service, 仅创建时生效
+	Headless     bool              `json:"headless"`
+	ExternalName string            `json:"externalName"`
+	Selector     map[string]string `json:"selector"`
+	Ports        []ServicePort     `json:"ports"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+}
+
+// IngressPath Ingress规则的路径, 后端为Service
+type IngressPath struct {
+	Path string `json:"path"`
+	// PathType Prefix、Exact或ImplementationSpecific, 为空时使用Prefix
+	PathType    string `json:"pathType"`
+	ServiceName string `json:"serviceName" binding:"required"`
+	// ServicePort Service端口号或端口名称
+	ServicePort string `json:"servicePort" binding:"required"`
+}
+
+type IngressRule struct {
+	Host  string        `json:"host"`
+	Paths []IngressPath `json:"paths" binding:"required,dive"`
+}
+
+type IngressTLS struct {
+	Hosts      []string `json:"hosts"`
+	SecretName string   `json:"secretName"`
+}
+
+// IngressForm 创建或更新Ingress
+type IngressForm struct {
+	Namespace        string            `json:"namespace" binding:"required"`
+	Name             string            `json:"name" binding:"required"`
+	IngressClassName string            `json:"ingressClassName"`
+	Rules            []IngressRule     `json:"rules" binding:"dive"`
+	TLS              []IngressTLS      `json:"tls"`
+	Labels           map[string]string `json:"labels"`
+	Annotations      map[string]string `json:"annotations"`
+}
@@ -0,0 +1,36 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+type K8STerminalRecord struct {
+	GModel
+	ConnectID   string    `gorm:"comment:'连接标识';size:64;index" json:"connect_id"`
+	ClusterId   uint      `gorm:"comment:'集群ID';index" json:"cluster_id"`
+	Namespace   string    `gorm:"comment:'命名空间';size:128" json:"namespace"`
+	Pod         string    `gorm:"comment:'Pod名称';size:256" json:"pod"`
+	Container   string    `gorm:"comment:'容器名称';size:256" json:"container"`
+	Shell       string    `gorm:"comment:'终端类型';size:32" json:"shell"`
+	UserName    string    `gorm:"comment:'操作用户';size:128" json:"user_name"`
+	ConnectTime LocalTime `gorm:"index;comment:'接入时间'" json:"connect_time"`
+	LogoutTime  LocalTime `gorm:"index;comment:'注销时间'" json:"logout_time"`
+	Records     []byte    `gorm:"type:longblob;comment:'操作记录(asciicast v2, zlib压缩)'" json:"records"`
+}
+
+func (r K8STerminalRecord) TableName() string {
+	var k GModel
+	return k.TableName("k8s_terminal_record")
+}
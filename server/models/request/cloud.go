@@ -17,3 +17,52 @@ type PageInfo struct {
 	PageSize   int `json:"pageSize" form:"pageSize"`
 	PageSelect int `json:"pageSelect" form:"pageSelect"`
 }
+
+// CloudSyncRecordQuery 云资产同步记录查询条件
+type CloudSyncRecordQuery struct {
+	PageInfo
+	PlatformId int `json:"platformId" form:"platformId"`
+}
+
+// CloudResourceQuery 云资源(云硬盘、负载均衡、云数据库、VPC、子网)查询条件
+type CloudResourceQuery struct {
+	PageInfo
+	PlatformId int    `json:"platformId" form:"platformId"`
+	RegionId   string `json:"regionId" form:"regionId"`
+}
+
+// CloudAccountId 云账号ID
+type CloudAccountId struct {
+	ID int `json:"id" form:"id" binding:"required"`
+}
+
+// CloudAccountCreate 创建云账号, 未填写Enable时默认启用
+type CloudAccountCreate struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	Region    string `json:"region"`
+	Remark    string `json:"remark"`
+	SyncCron  string `json:"sync_cron"`
+	Enable    *bool  `json:"enable"`
+}
+
+// CloudAccountUpdate 更新云账号, AccessKey和SecretKey同时填写时才更新凭证
+type CloudAccountUpdate struct {
+	ID        int    `json:"id" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	Region    string `json:"region"`
+	Remark    string `json:"remark"`
+	SyncCron  string `json:"sync_cron"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+}
+
+// CloudAccountVerify 校验云账号凭证, 指定ID时使用已保存的凭证
+type CloudAccountVerify struct {
+	ID        int    `json:"id"`
+	Type      string `json:"type"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+}
@@ -0,0 +1,42 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+// HostForm 手动添加、更新、导入主机; 更新时Password、PrivateKey为空表示不修改
+type HostForm struct {
+	ID          int    `json:"id"`
+	HostName    string `json:"hostname" binding:"required"`
+	PrivateAddr string `json:"private_addr" binding:"required"`
+	PublicAddr  string `json:"public_addr"`
+	Port        string `json:"port"`
+	UserName    string `json:"username"`
+	Password    string `json:"password"`
+	PrivateKey  string `json:"private_key"`
+	CPU         int    `json:"cpu"`
+	Mem         int    `json:"memory"`
+	OS          string `json:"os"`
+	OSType      string `json:"os_type"`
+	Region      string `json:"region"`
+	SN          string `json:"sn"`
+	Remark      string `json:"remark"`
+	GroupIds    []int  `json:"group_ids"`
+}
+
+// HostIds 批量操作主机
+type HostIds struct {
+	Ids []int `json:"ids" binding:"required"`
+}
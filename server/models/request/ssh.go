@@ -0,0 +1,97 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+// SSHRecordQuery SSH会话录像查询条件, UserName为主机登录用户, Operator为平台用户, 时间格式为2006-01-02 15:04:05, 按接入时间过滤
+type SSHRecordQuery struct {
+	PageInfo
+	UserName  string `json:"user_name" form:"user_name"`
+	Operator  string `json:"operator" form:"operator"`
+	HostId    uint   `json:"host_id" form:"host_id"`
+	HostName  string `json:"host_name" form:"host_name"`
+	StartTime string `json:"start_time" form:"start_time"`
+	EndTime   string `json:"end_time" form:"end_time"`
+}
+
+// SSHCommandQuery SSH命令审计查询条件, Command按包含匹配, 时间按执行时间过滤
+type SSHCommandQuery struct {
+	PageInfo
+	RecordId  uint   `json:"record_id" form:"record_id"`
+	UserName  string `json:"user_name" form:"user_name"`
+	Operator  string `json:"operator" form:"operator"`
+	HostId    uint   `json:"host_id" form:"host_id"`
+	Command   string `json:"command" form:"command"`
+	StartTime string `json:"start_time" form:"start_time"`
+	EndTime   string `json:"end_time" form:"end_time"`
+}
+
+// SSHCommandRuleForm 创建或更新命令黑名单规则, GroupIds、RoleIds为空表示对全部主机分组或角色生效
+type SSHCommandRuleForm struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name" binding:"required"`
+	Pattern  string `json:"pattern" binding:"required"`
+	Enable   *bool  `json:"enable"`
+	Remark   string `json:"remark"`
+	GroupIds []int  `json:"group_ids"`
+	RoleIds  []uint `json:"role_ids"`
+}
+
+// SSHCommandRuleIds 批量删除命令黑名单规则
+type SSHCommandRuleIds struct {
+	Ids []uint `json:"ids" binding:"required"`
+}
+
+// SSHCommandBlockQuery 命令拦截记录查询条件
+type SSHCommandBlockQuery struct {
+	PageInfo
+	Operator  string `json:"operator" form:"operator"`
+	HostId    uint   `json:"host_id" form:"host_id"`
+	RuleId    uint   `json:"rule_id" form:"rule_id"`
+	StartTime string `json:"start_time" form:"start_time"`
+	EndTime   string `json:"end_time" form:"end_time"`
+}
+
+// SSHSessionKill 强制断开在线会话
+type SSHSessionKill struct {
+	ConnectId string `json:"connect_id" binding:"required"`
+	Reason    string `json:"reason"`
+}
+
+// SSHCredentialForm 创建或更新SSH凭证; 更新时Password、PrivateKey、Passphrase为空表示不修改
+type SSHCredentialForm struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name" binding:"required"`
+	Type        string `json:"type" binding:"required"`
+	UserName    string `json:"user_name"`
+	Password    string `json:"password"`
+	PrivateKey  string `json:"private_key"`
+	Passphrase  string `json:"passphrase"`
+	Certificate string `json:"certificate"`
+	Remark      string `json:"remark"`
+}
+
+// SSHCredentialIds 批量删除SSH凭证
+type SSHCredentialIds struct {
+	Ids []uint `json:"ids" binding:"required"`
+}
+
+// SSHCredentialBind 为主机或主机分组分配SSH凭证, CredentialId为0表示解除分配
+type SSHCredentialBind struct {
+	CredentialId uint  `json:"credential_id"`
+	HostIds      []int `json:"host_ids"`
+	GroupIds     []int `json:"group_ids"`
+}
@@ -0,0 +1,116 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package asciicast2
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxLineSize 单个事件的最大长度, 大段输出会被记录为一行
+const maxLineSize = 16 * 1024 * 1024
+
+// Event asciicast v2的一个事件, Time为距会话开始的秒数
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+// MarshalJSON 按asciicast v2格式输出为[time, type, data]
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Time, e.Type, e.Data})
+}
+
+// Decode 解析asciicast v2内容, 返回头部和全部事件
+func Decode(r io.Reader) (*CastV2Header, []Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("录像内容为空")
+	}
+	var header CastV2Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, nil, fmt.Errorf("解析录像头部失败: %v", err)
+	}
+	if header.Version != 2 {
+		return nil, nil, fmt.Errorf("不支持的录像版本: %d", header.Version)
+	}
+	events := make([]Event, 0)
+	for line := 2; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var raw []interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil || len(raw) != 3 {
+			return nil, nil, fmt.Errorf("解析录像第%d行失败", line)
+		}
+		t, ok1 := raw[0].(float64)
+		typ, ok2 := raw[1].(string)
+		data, ok3 := raw[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, nil, fmt.Errorf("解析录像第%d行失败", line)
+		}
+		events = append(events, Event{Time: t, Type: typ, Data: data})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(events) > 0 {
+		header.Duration = events[len(events)-1].Time
+	}
+	return &header, events, nil
+}
+
+// Replay 按原始时间间隔依次发送事件, speed为播放倍速, idleLimit大于0时将超过该秒数的停顿压缩为idleLimit
+func Replay(ctx context.Context, events []Event, speed, idleLimit float64, send func(Event) error) error {
+	if speed <= 0 {
+		speed = 1
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+	last := 0.0
+	for _, e := range events {
+		wait := e.Time - last
+		last = e.Time
+		if idleLimit > 0 && wait > idleLimit {
+			wait = idleLimit
+		}
+		if wait > 0 {
+			timer.Reset(time.Duration(wait / speed * float64(time.Second)))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := send(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
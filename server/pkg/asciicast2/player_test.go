@@ -0,0 +1,62 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package asciicast2
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDecodeAndReplay(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	cast, _ := NewCastV2(CastV2Header{Width: 80, Height: 24, Title: "test"}, buffer)
+	cast.Record(0.5, []byte("hello"), "o")
+	cast.Record(30, []byte("world"), "o")
+
+	header, events, err := Decode(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Width != 80 || header.Duration != 30 || len(events) != 2 || events[1].Data != "world" {
+		t.Fatalf("unexpected decode result: %+v %+v", header, events)
+	}
+
+	var got []string
+	start := time.Now()
+	err = Replay(context.Background(), events, 100, 1, func(e Event) error {
+		got = append(got, e.Data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0.5s + 压缩后的1s停顿, 100倍速播放约15ms
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("idle limit not applied, replay took %v", elapsed)
+	}
+	if len(got) != 2 {
+		t.Fatalf("unexpected events: %v", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Replay(ctx, events, 1, 0, func(Event) error { return nil }); err == nil {
+		t.Fatal("expected error after cancel")
+	}
+}
@@ -21,8 +21,10 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 	"kubespace/server/common"
 	"kubespace/server/services"
@@ -58,18 +60,56 @@ func GetRestConf(k8sConf string) (restConf *rest.Config, err error) {
 	return restConf, nil
 }
 
-// ClusterID 公共方法, 获取指定k8s集群的KubeConfig
+// ClusterID 公共方法, 获取指定k8s集群的Client, Client由Manager按集群缓存
 func ClusterID(c *gin.Context) (*kubernetes.Clientset, error) {
 
-	clusterId := c.DefaultQuery("clusterId", "1")
-	clusterIdUint, err := strconv.ParseUint(clusterId, 10, 32)
-	cluster, err := services.GetK8sCluster(uint(clusterIdUint))
+	clusterId, err := parseClusterID(c)
+	if err != nil {
+		return nil, err
+	}
+	return Manager.Get(clusterId)
+}
+
+// ClusterRestConfig 公共方法, 获取指定k8s集群的RESTConfig
+func ClusterRestConfig(c *gin.Context) (*rest.Config, error) {
+
+	clusterId, err := parseClusterID(c)
 	if err != nil {
-		common.LOG.Error("获取集群失败", zap.Any("err", err))
 		return nil, err
 	}
+	return Manager.GetRestConfig(clusterId)
+}
+
+// ClusterDynamic 公共方法, 获取指定k8s集群的DynamicClient和RESTMapper
+func ClusterDynamic(c *gin.Context) (dynamic.Interface, *restmapper.DeferredDiscoveryRESTMapper, error) {
+
+	clusterId, err := parseClusterID(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Manager.GetDynamic(clusterId)
+}
+
+// ClusterName 公共方法, 获取指定k8s集群的名称
+func ClusterName(c *gin.Context) (string, error) {
 
-	client, _ := GetK8sClient(cluster.KubeConfig)
+	clusterId, err := parseClusterID(c)
+	if err != nil {
+		return "", err
+	}
+	cluster, err := services.GetK8sCluster(clusterId)
+	if err != nil {
+		return "", err
+	}
+	return cluster.ClusterName, nil
+}
 
-	return client, nil
+func parseClusterID(c *gin.Context) (uint, error) {
+	clusterId := c.DefaultQuery("clusterId", "1")
+	clusterIdUint, err := strconv.ParseUint(clusterId, 10, 32)
+	if err != nil {
+		common.LOG.Error("集群ID错误", zap.Any("err", err))
+		return 0, fmt.Errorf("集群ID错误: %v", clusterId)
+	}
+	return uint(clusterIdUint), nil
 }
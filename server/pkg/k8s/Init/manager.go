@@ -0,0 +1,157 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package Init
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"go.uber.org/zap"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"kubespace/server/common"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"kubespace/server/services"
+	"sync"
+)
+
+// Manager 全局集群管理器, 按集群ID缓存Client和Informer
+var Manager = NewClusterManager()
+
+// clusterCache 单个集群的Client和Informer缓存
+type clusterCache struct {
+	client      *kubernetes.Clientset
+	restConfig  *rest.Config
+	dynamic     dynamic.Interface
+	mapper      *restmapper.DeferredDiscoveryRESTMapper
+	factory     informers.SharedInformerFactory
+	stopCh      chan struct{}
+	fingerprint string
+}
+
+// ClusterManager 为每个已注册的集群维护一个Client和SharedInformerFactory,
+// 集群记录变更后重建, 集群删除后释放
+type ClusterManager struct {
+	sync.Mutex
+	clusters map[uint]*clusterCache
+}
+
+func NewClusterManager() *ClusterManager {
+	return &ClusterManager{clusters: make(map[uint]*clusterCache)}
+}
+
+// clusterFingerprint 集群记录的指纹, KubeConfig或更新时间变化时需要重建缓存
+func clusterFingerprint(kubeConfig string, updatedAt string) string {
+	return fmt.Sprintf("%x-%s", sha256.Sum256([]byte(kubeConfig)), updatedAt)
+}
+
+// Get 获取集群的Client, 缓存不存在或集群记录已变更时重新创建
+func (m *ClusterManager) Get(clusterId uint) (*kubernetes.Clientset, error) {
+	cc, err := m.get(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return cc.client, nil
+}
+
+// GetDynamic 获取集群的DynamicClient和基于Discovery的RESTMapper
+func (m *ClusterManager) GetDynamic(clusterId uint) (dynamic.Interface, *restmapper.DeferredDiscoveryRESTMapper, error) {
+	cc, err := m.get(clusterId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cc.dynamic, cc.mapper, nil
+}
+
+// GetRestConfig 获取集群的RESTConfig
+func (m *ClusterManager) GetRestConfig(clusterId uint) (*rest.Config, error) {
+	cc, err := m.get(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return cc.restConfig, nil
+}
+
+func (m *ClusterManager) get(clusterId uint) (*clusterCache, error) {
+	cluster, err := services.GetK8sCluster(clusterId)
+	if err != nil {
+		common.LOG.Error("获取集群失败", zap.Any("err", err))
+		return nil, err
+	}
+	fingerprint := clusterFingerprint(cluster.KubeConfig, cluster.UpdatedAt.String())
+
+	m.Lock()
+	defer m.Unlock()
+
+	if cc, ok := m.clusters[clusterId]; ok {
+		if cc.fingerprint == fingerprint {
+			return cc, nil
+		}
+		common.LOG.Info(fmt.Sprintf("集群: %v 记录已变更, 重建Client缓存", cluster.ClusterName))
+		m.release(clusterId, cc)
+	}
+
+	restConfig, err := GetRestConf(cluster.KubeConfig)
+	if err != nil {
+		common.LOG.Error("KubeConfig内容错误", zap.Any("err", err))
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		common.LOG.Error("创建Client失败", zap.Any("err", err))
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		common.LOG.Error("创建DynamicClient失败", zap.Any("err", err))
+		return nil, err
+	}
+
+	cc := &clusterCache{
+		client:      client,
+		restConfig:  restConfig,
+		dynamic:     dynamicClient,
+		mapper:      restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client.Discovery())),
+		factory:     informers.NewSharedInformerFactory(client, 0),
+		stopCh:      make(chan struct{}),
+		fingerprint: fingerprint,
+	}
+	k8scommon.RegisterInformerCache(client, clusterId, cc.factory, cc.stopCh)
+	m.clusters[clusterId] = cc
+	return cc, nil
+}
+
+// Remove 删除集群时停止Informer并释放缓存
+func (m *ClusterManager) Remove(clusterId uint) {
+	m.Lock()
+	defer m.Unlock()
+
+	if cc, ok := m.clusters[clusterId]; ok {
+		m.release(clusterId, cc)
+	}
+}
+
+func (m *ClusterManager) release(clusterId uint, cc *clusterCache) {
+	k8scommon.UnregisterInformerCache(cc.client)
+	k8scommon.ForgetServerVersion(clusterId)
+	close(cc.stopCh)
+	delete(m.clusters, clusterId)
+}
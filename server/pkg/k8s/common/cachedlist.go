@@ -0,0 +1,290 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+
+	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
+	batch2 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	storage "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// The list functions below serve a list from the informer cache registered for the client and
+// fall back to the apiserver when there is none. Objects coming from the cache are deep copied so
+// callers can't modify the shared store. Secrets and Events are always listed from the apiserver:
+// a cluster-wide informer would keep every Secret in memory and Events churn too much to be worth
+// caching.
+
+func listPods(c client.Interface, namespace string, options metaV1.ListOptions) (*v1.PodList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Pods().Informer()
+	})
+	if ok {
+		if items, err := factory.Core().V1().Pods().Lister().Pods(namespace).List(selector); err == nil {
+			list := &v1.PodList{Items: make([]v1.Pod, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.CoreV1().Pods(namespace).List(context.TODO(), options)
+}
+
+func listDeployments(c client.Interface, namespace string, options metaV1.ListOptions) (*apps.DeploymentList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Apps().V1().Deployments().Informer()
+	})
+	if ok {
+		if items, err := factory.Apps().V1().Deployments().Lister().Deployments(namespace).List(selector); err == nil {
+			list := &apps.DeploymentList{Items: make([]apps.Deployment, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.AppsV1().Deployments(namespace).List(context.TODO(), options)
+}
+
+func listReplicaSets(c client.Interface, namespace string, options metaV1.ListOptions) (*apps.ReplicaSetList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Apps().V1().ReplicaSets().Informer()
+	})
+	if ok {
+		if items, err := factory.Apps().V1().ReplicaSets().Lister().ReplicaSets(namespace).List(selector); err == nil {
+			list := &apps.ReplicaSetList{Items: make([]apps.ReplicaSet, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.AppsV1().ReplicaSets(namespace).List(context.TODO(), options)
+}
+
+func listConfigMaps(c client.Interface, namespace string, options metaV1.ListOptions) (*v1.ConfigMapList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().ConfigMaps().Informer()
+	})
+	if ok {
+		if items, err := factory.Core().V1().ConfigMaps().Lister().ConfigMaps(namespace).List(selector); err == nil {
+			list := &v1.ConfigMapList{Items: make([]v1.ConfigMap, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.CoreV1().ConfigMaps(namespace).List(context.TODO(), options)
+}
+
+func listPersistentVolumes(c client.Interface, options metaV1.ListOptions) (*v1.PersistentVolumeList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().PersistentVolumes().Informer()
+	})
+	if ok {
+		if items, err := factory.Core().V1().PersistentVolumes().Lister().List(selector); err == nil {
+			list := &v1.PersistentVolumeList{Items: make([]v1.PersistentVolume, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.CoreV1().PersistentVolumes().List(context.TODO(), options)
+}
+
+func listPersistentVolumeClaims(c client.Interface, namespace string, options metaV1.ListOptions) (*v1.PersistentVolumeClaimList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().PersistentVolumeClaims().Informer()
+	})
+	if ok {
+		if items, err := factory.Core().V1().PersistentVolumeClaims().Lister().PersistentVolumeClaims(namespace).List(selector); err == nil {
+			list := &v1.PersistentVolumeClaimList{Items: make([]v1.PersistentVolumeClaim, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), options)
+}
+
+func listStatefulSets(c client.Interface, namespace string, options metaV1.ListOptions) (*apps.StatefulSetList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Apps().V1().StatefulSets().Informer()
+	})
+	if ok {
+		if items, err := factory.Apps().V1().StatefulSets().Lister().StatefulSets(namespace).List(selector); err == nil {
+			list := &apps.StatefulSetList{Items: make([]apps.StatefulSet, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.AppsV1().StatefulSets(namespace).List(context.TODO(), options)
+}
+
+func listDaemonSets(c client.Interface, namespace string, options metaV1.ListOptions) (*apps.DaemonSetList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Apps().V1().DaemonSets().Informer()
+	})
+	if ok {
+		if items, err := factory.Apps().V1().DaemonSets().Lister().DaemonSets(namespace).List(selector); err == nil {
+			list := &apps.DaemonSetList{Items: make([]apps.DaemonSet, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.AppsV1().DaemonSets(namespace).List(context.TODO(), options)
+}
+
+func listServices(c client.Interface, namespace string, options metaV1.ListOptions) (*v1.ServiceList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Services().Informer()
+	})
+	if ok {
+		if items, err := factory.Core().V1().Services().Lister().Services(namespace).List(selector); err == nil {
+			list := &v1.ServiceList{Items: make([]v1.Service, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.CoreV1().Services(namespace).List(context.TODO(), options)
+}
+
+func listJobs(c client.Interface, namespace string, options metaV1.ListOptions) (*batch.JobList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Batch().V1().Jobs().Informer()
+	})
+	if ok {
+		if items, err := factory.Batch().V1().Jobs().Lister().Jobs(namespace).List(selector); err == nil {
+			list := &batch.JobList{Items: make([]batch.Job, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.BatchV1().Jobs(namespace).List(context.TODO(), options)
+}
+
+func listCronJobs(c client.Interface, namespace string, options metaV1.ListOptions) (*batch.CronJobList, error) {
+	if !CronJobUseV1(c) {
+		return listCronJobsV1beta1(c, namespace, options)
+	}
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Batch().V1().CronJobs().Informer()
+	})
+	if ok {
+		if items, err := factory.Batch().V1().CronJobs().Lister().CronJobs(namespace).List(selector); err == nil {
+			list := &batch.CronJobList{Items: make([]batch.CronJob, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.BatchV1().CronJobs(namespace).List(context.TODO(), options)
+}
+
+// listCronJobsV1beta1 集群不提供batch/v1 CronJob时使用v1beta1查询, 结果转换为batch/v1
+func listCronJobsV1beta1(c client.Interface, namespace string, options metaV1.ListOptions) (*batch.CronJobList, error) {
+	var items []*batch2.CronJob
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Batch().V1beta1().CronJobs().Informer()
+	})
+	if ok {
+		items, _ = factory.Batch().V1beta1().CronJobs().Lister().CronJobs(namespace).List(selector)
+	}
+	if items == nil {
+		raw, err := c.BatchV1beta1().CronJobs(namespace).List(context.TODO(), options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range raw.Items {
+			items = append(items, &raw.Items[i])
+		}
+	}
+	list := &batch.CronJobList{Items: make([]batch.CronJob, 0, len(items))}
+	for _, item := range items {
+		list.Items = append(list.Items, ConvertCronJobV1beta1(item.DeepCopy()))
+	}
+	return list, nil
+}
+
+func listStorageClasses(c client.Interface, options metaV1.ListOptions) (*storage.StorageClassList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Storage().V1().StorageClasses().Informer()
+	})
+	if ok {
+		if items, err := factory.Storage().V1().StorageClasses().Lister().List(selector); err == nil {
+			list := &storage.StorageClassList{Items: make([]storage.StorageClass, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.StorageV1().StorageClasses().List(context.TODO(), options)
+}
+
+func listEndpoints(c client.Interface, namespace string, options metaV1.ListOptions) (*v1.EndpointsList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Endpoints().Informer()
+	})
+	if ok {
+		if items, err := factory.Core().V1().Endpoints().Lister().Endpoints(namespace).List(selector); err == nil {
+			list := &v1.EndpointsList{Items: make([]v1.Endpoints, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.CoreV1().Endpoints(namespace).List(context.TODO(), options)
+}
+
+func listIngresses(c client.Interface, namespace string, options metaV1.ListOptions) (*extensions.IngressList, error) {
+	factory, selector, ok := syncedInformerFactory(c, options, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Extensions().V1beta1().Ingresses().Informer()
+	})
+	if ok {
+		if items, err := factory.Extensions().V1beta1().Ingresses().Lister().Ingresses(namespace).List(selector); err == nil {
+			list := &extensions.IngressList{Items: make([]extensions.Ingress, 0, len(items))}
+			for _, item := range items {
+				list.Items = append(list.Items, *item.DeepCopy())
+			}
+			return list, nil
+		}
+	}
+	return c.ExtensionsV1beta1().Ingresses(namespace).List(context.TODO(), options)
+}
@@ -0,0 +1,69 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	batch "k8s.io/api/batch/v1"
+	batch2 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/util/version"
+	client "k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+)
+
+// cronJobV1Version batch/v1 CronJob从1.21开始提供, 1.25起不再提供batch/v1beta1
+var cronJobV1Version = version.MustParseGeneric("v1.21.0")
+
+// CronJobUseV1 根据集群版本判断是否使用batch/v1访问CronJob, 无法获取版本时使用batch/v1
+func CronJobUseV1(c client.Interface) bool {
+	serverVersion, err := ServerVersion(c)
+	if err != nil {
+		common.LOG.Warn(fmt.Sprintf("获取集群版本失败, CronJob使用batch/v1: %v", err))
+		return true
+	}
+	return serverVersion.AtLeast(cronJobV1Version)
+}
+
+// CronJobAPIVersion 返回集群使用的CronJob apiVersion
+func CronJobAPIVersion(c client.Interface) string {
+	if CronJobUseV1(c) {
+		return batch.SchemeGroupVersion.String()
+	}
+	return batch2.SchemeGroupVersion.String()
+}
+
+// ConvertCronJobV1beta1 将batch/v1beta1的CronJob转换为batch/v1, 两个版本的字段一致
+func ConvertCronJobV1beta1(cj *batch2.CronJob) batch.CronJob {
+	return batch.CronJob{
+		TypeMeta:   cj.TypeMeta,
+		ObjectMeta: cj.ObjectMeta,
+		Spec: batch.CronJobSpec{
+			Schedule:                   cj.Spec.Schedule,
+			StartingDeadlineSeconds:    cj.Spec.StartingDeadlineSeconds,
+			ConcurrencyPolicy:          batch.ConcurrencyPolicy(cj.Spec.ConcurrencyPolicy),
+			Suspend:                    cj.Spec.Suspend,
+			JobTemplate:                batch.JobTemplateSpec{ObjectMeta: cj.Spec.JobTemplate.ObjectMeta, Spec: cj.Spec.JobTemplate.Spec},
+			SuccessfulJobsHistoryLimit: cj.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     cj.Spec.FailedJobsHistoryLimit,
+		},
+		Status: batch.CronJobStatus{
+			Active:             cj.Status.Active,
+			LastScheduleTime:   cj.Status.LastScheduleTime,
+			LastSuccessfulTime: cj.Status.LastSuccessfulTime,
+		},
+	}
+}
@@ -0,0 +1,37 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/util/version"
+	client "k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+)
+
+// hpaV2Version autoscaling/v2 HPA从1.23开始提供, 1.26起不再提供autoscaling/v2beta2
+var hpaV2Version = version.MustParseGeneric("v1.23.0")
+
+// HPAUseV2 根据集群版本判断是否使用autoscaling/v2访问HPA, 无法获取版本时使用autoscaling/v2
+func HPAUseV2(c client.Interface) bool {
+	serverVersion, err := ServerVersion(c)
+	if err != nil {
+		common.LOG.Warn(fmt.Sprintf("获取集群版本失败, HPA使用autoscaling/v2: %v", err))
+		return true
+	}
+	return serverVersion.AtLeast(hpaV2Version)
+}
@@ -0,0 +1,148 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"sync"
+	"time"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// informerSyncTimeout is how long a list call waits for an informer to fill its cache before
+// falling back to the apiserver. The informer keeps syncing in the background, so a later call
+// can use it.
+const informerSyncTimeout = 2 * time.Second
+
+// informerSyncBackoff is how long list calls go straight to the apiserver after an informer failed
+// to sync in time, so an informer that never syncs (e.g. missing list/watch permission) doesn't
+// delay every call.
+const informerSyncBackoff = 5 * time.Minute
+
+// InformerCache is a shared informer factory bound to a client. Informers are created lazily the
+// first time a resource is listed and run until StopCh is closed.
+type InformerCache struct {
+	ClusterID uint
+	Factory   informers.SharedInformerFactory
+	StopCh    <-chan struct{}
+
+	lock sync.Mutex
+	// unsynced records until when an informer that didn't sync in time is skipped.
+	unsynced map[cache.SharedIndexInformer]time.Time
+}
+
+// skip reports whether the informer failed to sync recently and lists should use the apiserver.
+func (ic *InformerCache) skip(informer cache.SharedIndexInformer) bool {
+	ic.lock.Lock()
+	defer ic.lock.Unlock()
+	until, ok := ic.unsynced[informer]
+	if ok && time.Now().After(until) {
+		delete(ic.unsynced, informer)
+		return false
+	}
+	return ok
+}
+
+func (ic *InformerCache) markUnsynced(informer cache.SharedIndexInformer) {
+	ic.lock.Lock()
+	defer ic.lock.Unlock()
+	if ic.unsynced == nil {
+		ic.unsynced = make(map[cache.SharedIndexInformer]time.Time)
+	}
+	ic.unsynced[informer] = time.Now().Add(informerSyncBackoff)
+}
+
+var (
+	informerCachesLock sync.RWMutex
+	informerCaches     = make(map[client.Interface]*InformerCache)
+)
+
+// RegisterInformerCache makes the list channels of the given client of cluster clusterId read from
+// the informer factory instead of the apiserver.
+func RegisterInformerCache(c client.Interface, clusterId uint, factory informers.SharedInformerFactory, stopCh <-chan struct{}) {
+	informerCachesLock.Lock()
+	defer informerCachesLock.Unlock()
+	informerCaches[c] = &InformerCache{ClusterID: clusterId, Factory: factory, StopCh: stopCh}
+}
+
+// UnregisterInformerCache drops the informer factory of the given client. Stopping the informers
+// is up to the caller, which owns StopCh.
+func UnregisterInformerCache(c client.Interface) {
+	informerCachesLock.Lock()
+	defer informerCachesLock.Unlock()
+	delete(informerCaches, c)
+}
+
+func getInformerCache(c client.Interface) *InformerCache {
+	informerCachesLock.RLock()
+	defer informerCachesLock.RUnlock()
+	return informerCaches[c]
+}
+
+// syncedInformerFactory returns the informer factory registered for the client once the informer
+// built by informerFunc has synced, together with the label selector parsed from options. ok is
+// false when the list has to go to the apiserver: the client has no cache, options carry a field
+// selector the listers can't evaluate, or the informer didn't sync in time. An informer that
+// didn't sync is not waited for again until informerSyncBackoff has passed.
+func syncedInformerFactory(c client.Interface, options metaV1.ListOptions,
+	informerFunc func(informers.SharedInformerFactory) cache.SharedIndexInformer) (informers.SharedInformerFactory, labels.Selector, bool) {
+	ic := getInformerCache(c)
+	if ic == nil {
+		return nil, nil, false
+	}
+
+	if options.FieldSelector != "" && options.FieldSelector != fields.Everything().String() {
+		return nil, nil, false
+	}
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	informer := informerFunc(ic.Factory)
+	if !informer.HasSynced() {
+		if ic.skip(informer) {
+			return nil, nil, false
+		}
+		// Start only launches informers that are not running yet.
+		ic.Factory.Start(ic.StopCh)
+
+		stopCh := make(chan struct{})
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			defer close(stopCh)
+			select {
+			case <-ic.StopCh:
+			case <-done:
+			case <-time.After(informerSyncTimeout):
+			}
+		}()
+		synced := cache.WaitForCacheSync(stopCh, informer.HasSynced)
+		if !synced {
+			ic.markUnsynced(informer)
+			return nil, nil, false
+		}
+	}
+
+	return ic.Factory, selector, true
+}
@@ -0,0 +1,124 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newLabeledPod(name, namespace string, labels map[string]string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+}
+
+func TestGetPodListChannelFromInformerCache(t *testing.T) {
+	cli := fake.NewSimpleClientset(
+		newLabeledPod("a", "default", map[string]string{"app": "a"}),
+		newLabeledPod("b", "default", map[string]string{"app": "b"}),
+		newLabeledPod("c", "kube-system", nil),
+	)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	RegisterInformerCache(cli, 1, informers.NewSharedInformerFactory(cli, 0), stopCh)
+	defer UnregisterInformerCache(cli)
+
+	cases := []struct {
+		nsQuery  *NamespaceQuery
+		options  metaV1.ListOptions
+		expected int
+	}{
+		{NewNamespaceQuery(nil), metaV1.ListOptions{}, 3},
+		{NewSameNamespaceQuery("default"), metaV1.ListOptions{}, 2},
+		{NewNamespaceQuery([]string{"default", "kube-system"}), metaV1.ListOptions{}, 3},
+		{NewSameNamespaceQuery("default"), metaV1.ListOptions{LabelSelector: "app=a"}, 1},
+		// field selectors can't be served by the lister and go to the apiserver
+		{NewSameNamespaceQuery("default"), metaV1.ListOptions{FieldSelector: "spec.nodeName=n1"}, 2},
+	}
+
+	for _, c := range cases {
+		channel := GetPodListChannelWithOptions(cli, c.nsQuery, c.options, 1)
+		list := <-channel.List
+		if err := <-channel.Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Items) != c.expected {
+			t.Errorf("GetPodListChannelWithOptions(%+v, %+v) returned %d pods, expected %d",
+				c.nsQuery, c.options, len(list.Items), c.expected)
+		}
+	}
+
+	if _, _, ok := syncedInformerFactory(cli, metaV1.ListOptions{}, func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Pods().Informer()
+	}); !ok {
+		t.Error("expected the pod informer to be synced")
+	}
+}
+
+func TestListWithoutInformerCache(t *testing.T) {
+	cli := fake.NewSimpleClientset(newLabeledPod("a", "default", nil))
+	if getInformerCache(cli) != nil {
+		t.Fatal("expected no informer cache for an unregistered client")
+	}
+	list, err := listPods(cli, "default", metaV1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 {
+		t.Errorf("listPods returned %d pods, expected 1", len(list.Items))
+	}
+}
+
+func TestUnsyncedInformerBackoff(t *testing.T) {
+	cli := fake.NewSimpleClientset()
+	// the pod informer never syncs, e.g. the user may not list pods cluster-wide
+	cli.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	RegisterInformerCache(cli, 1, informers.NewSharedInformerFactory(cli, 0), stopCh)
+	defer UnregisterInformerCache(cli)
+
+	podInformer := func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Pods().Informer()
+	}
+	start := time.Now()
+	if _, _, ok := syncedInformerFactory(cli, metaV1.ListOptions{}, podInformer); ok {
+		t.Fatal("expected the pod informer not to sync")
+	}
+	if elapsed := time.Since(start); elapsed > informerSyncTimeout+time.Second {
+		t.Errorf("first call took %v, expected about %v", elapsed, informerSyncTimeout)
+	}
+
+	// later calls go to the apiserver without waiting again
+	start = time.Now()
+	if _, _, ok := syncedInformerFactory(cli, metaV1.ListOptions{}, podInformer); ok {
+		t.Fatal("expected the pod informer not to sync")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("call after a failed sync took %v, expected no wait", elapsed)
+	}
+}
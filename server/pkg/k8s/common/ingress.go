@@ -0,0 +1,37 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/util/version"
+	client "k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+)
+
+// ingressV1Version networking.k8s.io/v1 Ingress从1.19开始提供, 1.22起不再提供v1beta1
+var ingressV1Version = version.MustParseGeneric("v1.19.0")
+
+// IngressUseV1 根据集群版本判断是否使用networking/v1访问Ingress, 无法获取版本时使用networking/v1
+func IngressUseV1(c client.Interface) bool {
+	serverVersion, err := ServerVersion(c)
+	if err != nil {
+		common.LOG.Warn(fmt.Sprintf("获取集群版本失败, Ingress使用networking/v1: %v", err))
+		return true
+	}
+	return serverVersion.AtLeast(ingressV1Version)
+}
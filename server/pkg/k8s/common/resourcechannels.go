@@ -19,7 +19,6 @@ package common
 import (
 	"context"
 	batch "k8s.io/api/batch/v1"
-	batch2 "k8s.io/api/batch/v1beta1"
 
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -115,6 +114,7 @@ func GetEventListChannelWithOptions(client *client.Clientset, nsQuery *Namespace
 		// 原options是根据label过滤来过滤Event事件信息, 代码deployment_detail + L78
 		// TODO 改成field过滤
 		//options.FieldSelector = fmt.Sprintf("involvedObject.name=%v", deploymentName)
+		// Events和Secrets不使用全集群的informer缓存, 直接从apiserver按命名空间查询
 		list, err := client.CoreV1().Events(nsQuery.ToRequestParam()).List(context.TODO(), options)
 		var filteredItems []v1.Event
 		for _, item := range list.Items {
@@ -153,7 +153,7 @@ func GetPodListChannelWithOptions(client client.Interface, nsQuery *NamespaceQue
 	}
 
 	go func() {
-		list, err := client.CoreV1().Pods(nsQuery.ToRequestParam()).List(context.TODO(), options)
+		list, err := listPods(client, nsQuery.ToRequestParam(), options)
 		var filteredItems []v1.Pod
 		for _, item := range list.Items {
 			if nsQuery.Matches(item.ObjectMeta.Namespace) {
@@ -186,7 +186,7 @@ func GetDeploymentListChannel(client client.Interface, nsQuery *NamespaceQuery,
 	}
 
 	go func() {
-		list, err := client.AppsV1().Deployments(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+		list, err := listDeployments(client, nsQuery.ToRequestParam(), k8s.ListEverything)
 		var filteredItems []apps.Deployment
 		for _, item := range list.Items {
 			if nsQuery.Matches(item.ObjectMeta.Namespace) {
@@ -224,8 +224,7 @@ func GetReplicaSetListChannelWithOptions(client client.Interface, nsQuery *Names
 	}
 
 	go func() {
-		list, err := client.AppsV1().ReplicaSets(nsQuery.ToRequestParam()).
-			List(context.TODO(), options)
+		list, err := listReplicaSets(client, nsQuery.ToRequestParam(), options)
 		var filteredItems []apps.ReplicaSet
 		for _, item := range list.Items {
 			if nsQuery.Matches(item.ObjectMeta.Namespace) {
@@ -258,7 +257,7 @@ func GetConfigMapListChannel(client client.Interface, nsQuery *NamespaceQuery, n
 	}
 
 	go func() {
-		list, err := client.CoreV1().ConfigMaps(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+		list, err := listConfigMaps(client, nsQuery.ToRequestParam(), k8s.ListEverything)
 		var filteredItems []v1.ConfigMap
 		for _, item := range list.Items {
 			if nsQuery.Matches(item.ObjectMeta.Namespace) {
@@ -323,7 +322,7 @@ func GetPersistentVolumeListChannel(client client.Interface, numReads int) Persi
 	}
 
 	go func() {
-		list, err := client.CoreV1().PersistentVolumes().List(context.TODO(), k8s.ListEverything)
+		list, err := listPersistentVolumes(client, k8s.ListEverything)
 		for i := 0; i < numReads; i++ {
 			channel.List <- list
 			channel.Error <- err
@@ -349,7 +348,7 @@ func GetPersistentVolumeClaimListChannel(client client.Interface, nsQuery *Names
 	}
 
 	go func() {
-		list, err := client.CoreV1().PersistentVolumeClaims(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+		list, err := listPersistentVolumeClaims(client, nsQuery.ToRequestParam(), k8s.ListEverything)
 		for i := 0; i < numReads; i++ {
 			channel.List <- list
 			channel.Error <- err
@@ -374,7 +373,7 @@ func GetStatefulSetListChannel(client client.Interface, nsQuery *NamespaceQuery,
 	}
 
 	go func() {
-		statefulSets, err := client.AppsV1().StatefulSets(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+		statefulSets, err := listStatefulSets(client, nsQuery.ToRequestParam(), k8s.ListEverything)
 		var filteredItems []apps.StatefulSet
 		for _, item := range statefulSets.Items {
 			if nsQuery.Matches(item.ObjectMeta.Namespace) {
@@ -406,7 +405,7 @@ func GetDaemonSetListChannel(client client.Interface, nsQuery *NamespaceQuery, n
 	}
 
 	go func() {
-		list, err := client.AppsV1().DaemonSets(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+		list, err := listDaemonSets(client, nsQuery.ToRequestParam(), k8s.ListEverything)
 		var filteredItems []apps.DaemonSet
 		for _, item := range list.Items {
 			if nsQuery.Matches(item.ObjectMeta.Namespace) {
@@ -438,7 +437,7 @@ func GetServiceListChannel(client client.Interface, nsQuery *NamespaceQuery, num
 		Error: make(chan error, numReads),
 	}
 	go func() {
-		list, err := client.CoreV1().Services(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+		list, err := listServices(client, nsQuery.ToRequestParam(), k8s.ListEverything)
 		var filteredItems []v1.Service
 		for _, item := range list.Items {
 			if nsQuery.Matches(item.ObjectMeta.Namespace) {
@@ -469,7 +468,7 @@ func GetJobListChannel(client client.Interface, nsQuery *NamespaceQuery, numRead
 	}
 
 	go func() {
-		list, err := client.BatchV1().Jobs(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+		list, err := listJobs(client, nsQuery.ToRequestParam(), k8s.ListEverything)
 		var filteredItems []batch.Job
 		for _, item := range list.Items {
 			if nsQuery.Matches(item.ObjectMeta.Namespace) {
@@ -488,26 +487,28 @@ func GetJobListChannel(client client.Interface, nsQuery *NamespaceQuery, numRead
 
 // CronJobListChannel is a list and error channels to Cron Jobs.
 type CronJobListChannel struct {
-	List  chan *batch2.CronJobList
+	List  chan *batch.CronJobList
 	Error chan error
 }
 
 // GetCronJobListChannel returns a pair of channels to a Cron Job list and errors that both must be read numReads times.
 func GetCronJobListChannel(client client.Interface, nsQuery *NamespaceQuery, numReads int) CronJobListChannel {
 	channel := CronJobListChannel{
-		List:  make(chan *batch2.CronJobList, numReads),
+		List:  make(chan *batch.CronJobList, numReads),
 		Error: make(chan error, numReads),
 	}
 
 	go func() {
-		list, err := client.BatchV1beta1().CronJobs(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
-		var filteredItems []batch2.CronJob
-		for _, item := range list.Items {
-			if nsQuery.Matches(item.ObjectMeta.Namespace) {
-				filteredItems = append(filteredItems, item)
+		list, err := listCronJobs(client, nsQuery.ToRequestParam(), k8s.ListEverything)
+		if err == nil {
+			var filteredItems []batch.CronJob
+			for _, item := range list.Items {
+				if nsQuery.Matches(item.ObjectMeta.Namespace) {
+					filteredItems = append(filteredItems, item)
+				}
 			}
+			list.Items = filteredItems
 		}
-		list.Items = filteredItems
 		for i := 0; i < numReads; i++ {
 			channel.List <- list
 			channel.Error <- err
@@ -532,7 +533,7 @@ func GetStorageClassListChannel(client client.Interface, numReads int) StorageCl
 	}
 
 	go func() {
-		list, err := client.StorageV1().StorageClasses().List(context.TODO(), k8s.ListEverything)
+		list, err := listStorageClasses(client, k8s.ListEverything)
 		for i := 0; i < numReads; i++ {
 			channel.List <- list
 			channel.Error <- err
@@ -561,7 +562,7 @@ func GetEndpointListChannelWithOptions(client client.Interface,
 	}
 
 	go func() {
-		list, err := client.CoreV1().Endpoints(nsQuery.ToRequestParam()).List(context.TODO(), opt)
+		list, err := listEndpoints(client, nsQuery.ToRequestParam(), opt)
 
 		for i := 0; i < numReads; i++ {
 			channel.List <- list
@@ -587,7 +588,7 @@ func GetIngressListChannel(client client.Interface, nsQuery *NamespaceQuery, num
 		Error: make(chan error, numReads),
 	}
 	go func() {
-		list, err := client.ExtensionsV1beta1().Ingresses(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+		list, err := listIngresses(client, nsQuery.ToRequestParam(), k8s.ListEverything)
 		var filteredItems []extensions.Ingress
 		for _, item := range list.Items {
 			if nsQuery.Matches(item.ObjectMeta.Namespace) {
@@ -0,0 +1,53 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+	client "k8s.io/client-go/kubernetes"
+	"sync"
+)
+
+// serverVersions 按集群ID缓存集群版本, 集群记录变更或删除时清除, 集群升级后更新集群记录即可刷新
+var serverVersions sync.Map
+
+// ServerVersion 返回集群的Kubernetes版本, 用于协商资源的API版本. 只缓存ClusterManager注册的Client的版本
+func ServerVersion(c client.Interface) (*version.Version, error) {
+	ic := getInformerCache(c)
+	if ic != nil {
+		if v, ok := serverVersions.Load(ic.ClusterID); ok {
+			return v.(*version.Version), nil
+		}
+	}
+	info, err := c.Discovery().ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	v, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return nil, err
+	}
+	if ic != nil {
+		serverVersions.Store(ic.ClusterID, v)
+	}
+	return v, nil
+}
+
+// ForgetServerVersion 清除集群的版本缓存
+func ForgetServerVersion(clusterId uint) {
+	serverVersions.Delete(clusterId)
+}
@@ -0,0 +1,55 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServerVersionCache(t *testing.T) {
+	cli := fake.NewSimpleClientset()
+	discovery := cli.Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{GitVersion: "v1.20.4"}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	RegisterInformerCache(cli, 7, informers.NewSharedInformerFactory(cli, 0), stopCh)
+	defer UnregisterInformerCache(cli)
+	defer ForgetServerVersion(7)
+
+	expect := func(want string) {
+		t.Helper()
+		v, err := ServerVersion(cli)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.String() != want {
+			t.Errorf("ServerVersion = %s, want %s", v, want)
+		}
+	}
+	expect("1.20.4")
+
+	// cached until the cluster is updated or deleted
+	discovery.FakedServerVersion = &version.Info{GitVersion: "v1.25.0"}
+	expect("1.20.4")
+	ForgetServerVersion(7)
+	expect("1.25.0")
+}
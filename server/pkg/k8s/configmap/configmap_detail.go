@@ -34,6 +34,9 @@ type ConfigMapDetail struct {
 	// Data contains the configuration data.
 	// Each key must be a valid DNS_SUBDOMAIN with an optional leading dot.
 	Data map[string]string `json:"data,omitempty"`
+
+	// BinaryData contains the binary data, e.g. files uploaded that are not valid UTF-8.
+	BinaryData map[string][]byte `json:"binaryData,omitempty"`
 }
 
 // GetConfigMapDetail returns detailed information about a config map
@@ -51,7 +54,8 @@ func GetConfigMapDetail(client kubernetes.Interface, namespace, name string) (*C
 
 func getConfigMapDetail(rawConfigMap *v1.ConfigMap) *ConfigMapDetail {
 	return &ConfigMapDetail{
-		ConfigMap: toConfigMap(rawConfigMap.ObjectMeta),
-		Data:      rawConfigMap.Data,
+		ConfigMap:  toConfigMap(rawConfigMap.ObjectMeta),
+		Data:       rawConfigMap.Data,
+		BinaryData: rawConfigMap.BinaryData,
 	}
 }
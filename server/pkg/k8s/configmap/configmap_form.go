@@ -0,0 +1,149 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"context"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxConfigMapSize ConfigMap数据的总大小上限, 与apiserver的限制一致
+const MaxConfigMapSize = 1024 * 1024
+
+// ValidateConfigMapForm 校验ConfigMap表单, 错误信息可直接返回给前端
+func ValidateConfigMapForm(form k8s.ConfigMapForm) error {
+	if errs := validation.IsDNS1123Subdomain(form.Name); len(errs) > 0 {
+		return fmt.Errorf("ConfigMap名称不合法: %s", strings.Join(errs, "; "))
+	}
+	if err := k8scommon.ValidateLabels(form.Labels); err != nil {
+		return fmt.Errorf("labels不合法: %v", err)
+	}
+	keys := make([]string, 0, len(form.Data))
+	size := 0
+	for k, v := range form.Data {
+		keys = append(keys, k)
+		size += len(k) + len(v)
+	}
+	if size > MaxConfigMapSize {
+		return fmt.Errorf("ConfigMap数据总大小不能超过%d字节", MaxConfigMapSize)
+	}
+	return k8scommon.ValidateDataKeys(keys)
+}
+
+// CreateConfigMap 按表单创建ConfigMap
+func CreateConfigMap(client kubernetes.Interface, form k8s.ConfigMapForm) (*ConfigMapDetail, error) {
+	common.LOG.Info(fmt.Sprintf("创建ConfigMap: %v, namespace: %v", form.Name, form.Namespace))
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metaV1.ObjectMeta{Name: form.Name, Namespace: form.Namespace, Labels: form.Labels},
+		Data:       form.Data,
+	}
+	created, err := client.CoreV1().ConfigMaps(form.Namespace).Create(context.TODO(), configMap, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return getConfigMapDetail(created), nil
+}
+
+// UpdateConfigMap 按表单整体替换ConfigMap的data, binaryData保持不变, 未传labels时保留原值
+func UpdateConfigMap(client kubernetes.Interface, form k8s.ConfigMapForm) (*ConfigMapDetail, error) {
+	common.LOG.Info(fmt.Sprintf("更新ConfigMap: %v, namespace: %v", form.Name, form.Namespace))
+	configMap, err := client.CoreV1().ConfigMaps(form.Namespace).Get(context.TODO(), form.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if configMap.Immutable != nil && *configMap.Immutable {
+		return nil, fmt.Errorf("ConfigMap %s已设置为不可变, 不能修改", form.Name)
+	}
+	if form.Labels != nil {
+		configMap.Labels = form.Labels
+	}
+	configMap.Data = form.Data
+	updated, err := client.CoreV1().ConfigMaps(form.Namespace).Update(context.TODO(), configMap, metaV1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return getConfigMapDetail(updated), nil
+}
+
+// UploadConfigMapFile 将上传的文件写入ConfigMap的一个key, 文本写入data, 二进制写入binaryData, ConfigMap不存在时创建
+func UploadConfigMapFile(client kubernetes.Interface, namespace, name, key string, content []byte) (*ConfigMapDetail, error) {
+	common.LOG.Info(fmt.Sprintf("上传文件到ConfigMap: %v, namespace: %v, key: %v", name, namespace, key))
+	if err := k8scommon.ValidateDataKeys([]string{key}); err != nil {
+		return nil, err
+	}
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	notFound := errors.IsNotFound(err)
+	if err != nil && !notFound {
+		return nil, err
+	}
+	if notFound {
+		configMap = &v1.ConfigMap{ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: namespace}}
+	} else if configMap.Immutable != nil && *configMap.Immutable {
+		return nil, fmt.Errorf("ConfigMap %s已设置为不可变, 不能修改", name)
+	}
+	setConfigMapKey(configMap, key, content)
+	if size := configMapSize(configMap); size > MaxConfigMapSize {
+		return nil, fmt.Errorf("写入后ConfigMap数据总大小为%d字节, 超过上限%d字节", size, MaxConfigMapSize)
+	}
+	var saved *v1.ConfigMap
+	if notFound {
+		saved, err = client.CoreV1().ConfigMaps(namespace).Create(context.TODO(), configMap, metaV1.CreateOptions{})
+	} else {
+		saved, err = client.CoreV1().ConfigMaps(namespace).Update(context.TODO(), configMap, metaV1.UpdateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return getConfigMapDetail(saved), nil
+}
+
+func setConfigMapKey(configMap *v1.ConfigMap, key string, content []byte) {
+	delete(configMap.Data, key)
+	delete(configMap.BinaryData, key)
+	if utf8.Valid(content) {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[key] = string(content)
+		return
+	}
+	if configMap.BinaryData == nil {
+		configMap.BinaryData = map[string][]byte{}
+	}
+	configMap.BinaryData[key] = content
+}
+
+func configMapSize(configMap *v1.ConfigMap) int {
+	size := 0
+	for k, v := range configMap.Data {
+		size += len(k) + len(v)
+	}
+	for k, v := range configMap.BinaryData {
+		size += len(k) + len(v)
+	}
+	return size
+}
@@ -17,9 +17,8 @@ limitations under the License.
 package cronjob
 
 import (
-	"context"
 	"fmt"
-	"k8s.io/api/batch/v1beta1"
+	batch "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	client "k8s.io/client-go/kubernetes"
 	"kubespace/server/common"
@@ -76,7 +75,7 @@ func GetCronJobListFromChannels(channels *k8scommon.ResourceChannels, dsQuery *d
 	return cronJobList, nil
 }
 
-func toCronJobList(cronJobs []v1beta1.CronJob, dsQuery *dataselect.DataSelectQuery) *CronJobList {
+func toCronJobList(cronJobs []batch.CronJob, dsQuery *dataselect.DataSelectQuery) *CronJobList {
 
 	list := &CronJobList{
 		Items:    make([]CronJob, 0),
@@ -94,7 +93,7 @@ func toCronJobList(cronJobs []v1beta1.CronJob, dsQuery *dataselect.DataSelectQue
 	return list
 }
 
-func toCronJob(cj *v1beta1.CronJob) CronJob {
+func toCronJob(cj *batch.CronJob) CronJob {
 	return CronJob{
 		ObjectMeta:      k8s.NewObjectMeta(cj.ObjectMeta),
 		TypeMeta:        k8s.NewTypeMeta(k8s.ResourceKindCronJob),
@@ -107,21 +106,14 @@ func toCronJob(cj *v1beta1.CronJob) CronJob {
 }
 
 func DeleteCronJob(client *client.Clientset, namespace, name string) (err error) {
-	// for k8s version < 1.21.0, use batch/v1beta1
-	// if you use BatchV1  Will report an error: the server could not find the requested resource
-	// BatchV1beta1
-	return client.BatchV1beta1().CronJobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	return deleteCronJob(client, namespace, name)
 }
 
 func DeleteCollectionCronJob(client *client.Clientset, jobList []k8s.JobData) (err error) {
 	common.LOG.Info("批量删除cronjob开始")
 	for _, v := range jobList {
 		common.LOG.Info(fmt.Sprintf("delete cronjob：%v, ns: %v", v.Name, v.Namespace))
-		err := client.BatchV1beta1().CronJobs(v.Namespace).Delete(
-			context.TODO(),
-			v.Name,
-			metav1.DeleteOptions{},
-		)
+		err := deleteCronJob(client, v.Namespace, v.Name)
 		if err != nil {
 			common.LOG.Error(err.Error())
 			return err
@@ -0,0 +1,93 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	"context"
+	"fmt"
+	batch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	client "k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"strings"
+	"time"
+)
+
+// instantiateAnnotation 与kubectl create job --from=cronjob保持一致, 标记手动触发的Job
+const instantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+
+// SuspendCronJob 暂停或恢复CronJob的调度
+func SuspendCronJob(client client.Interface, namespace, name string, suspend bool) error {
+	common.LOG.Info(fmt.Sprintf("设置cronjob：%v, ns: %v, suspend: %v", name, namespace, suspend))
+	patch := []byte(fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend))
+	var err error
+	if k8scommon.CronJobUseV1(client) {
+		_, err = client.BatchV1().CronJobs(namespace).Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	} else {
+		_, err = client.BatchV1beta1().CronJobs(namespace).Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	return err
+}
+
+// TriggerCronJob 按CronJob的Job模板立即创建一个Job, Job归属于该CronJob
+func TriggerCronJob(client client.Interface, namespace, name string) (*batch.Job, error) {
+	cj, err := getCronJob(client, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	job := newJobFromCronJob(cj, k8scommon.CronJobAPIVersion(client), time.Now())
+	common.LOG.Info(fmt.Sprintf("手动触发cronjob：%v, ns: %v, job: %v", name, namespace, job.Name))
+	return client.BatchV1().Jobs(namespace).Create(context.TODO(), job, metav1.CreateOptions{})
+}
+
+func newJobFromCronJob(cj *batch.CronJob, apiVersion string, now time.Time) *batch.Job {
+	annotations := map[string]string{instantiateAnnotation: "manual"}
+	for k, v := range cj.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	labels := map[string]string{}
+	for k, v := range cj.Spec.JobTemplate.Labels {
+		labels[k] = v
+	}
+	// Job名称最长63个字符, 过长时截断CronJob名称以保留时间戳后缀
+	suffix := fmt.Sprintf("-manual-%d", now.Unix())
+	prefix := cj.Name
+	if len(prefix)+len(suffix) > validation.DNS1123LabelMaxLength {
+		prefix = strings.TrimRight(prefix[:validation.DNS1123LabelMaxLength-len(suffix)], "-.")
+	}
+	isController := true
+	return &batch.Job{
+		TypeMeta: metav1.TypeMeta{APIVersion: batch.SchemeGroupVersion.String(), Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        prefix + suffix,
+			Namespace:   cj.Namespace,
+			Annotations: annotations,
+			Labels:      labels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: apiVersion,
+				Kind:       "CronJob",
+				Name:       cj.Name,
+				UID:        cj.UID,
+				Controller: &isController,
+			}},
+		},
+		Spec: *cj.Spec.JobTemplate.Spec.DeepCopy(),
+	}
+}
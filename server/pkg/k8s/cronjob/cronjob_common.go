@@ -17,14 +17,17 @@ limitations under the License.
 package cronjob
 
 import (
-	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"context"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	client "k8s.io/client-go/kubernetes"
 	"kubespace/server/pkg/k8s/common"
 	"kubespace/server/pkg/k8s/dataselect"
 )
 
 // The code below allows to perform complex data section on []batch.CronJob
 
-type CronJobCell batchv1beta1.CronJob
+type CronJobCell batchv1.CronJob
 
 func (self CronJobCell) GetProperty(name dataselect.PropertyName) dataselect.ComparableValue {
 	switch name {
@@ -40,7 +43,7 @@ func (self CronJobCell) GetProperty(name dataselect.PropertyName) dataselect.Com
 	}
 }
 
-func ToCells(std []batchv1beta1.CronJob) []dataselect.DataCell {
+func ToCells(std []batchv1.CronJob) []dataselect.DataCell {
 	cells := make([]dataselect.DataCell, len(std))
 	for i := range std {
 		cells[i] = CronJobCell(std[i])
@@ -48,15 +51,15 @@ func ToCells(std []batchv1beta1.CronJob) []dataselect.DataCell {
 	return cells
 }
 
-func FromCells(cells []dataselect.DataCell) []batchv1beta1.CronJob {
-	std := make([]batchv1beta1.CronJob, len(cells))
+func FromCells(cells []dataselect.DataCell) []batchv1.CronJob {
+	std := make([]batchv1.CronJob, len(cells))
 	for i := range std {
-		std[i] = batchv1beta1.CronJob(cells[i].(CronJobCell))
+		std[i] = batchv1.CronJob(cells[i].(CronJobCell))
 	}
 	return std
 }
 
-func getStatus(list *batchv1beta1.CronJobList) common.ResourceStatus {
+func getStatus(list *batchv1.CronJobList) common.ResourceStatus {
 	info := common.ResourceStatus{}
 	if list == nil {
 		return info
@@ -73,7 +76,7 @@ func getStatus(list *batchv1beta1.CronJobList) common.ResourceStatus {
 	return info
 }
 
-func getContainerImages(cronJob *batchv1beta1.CronJob) []string {
+func getContainerImages(cronJob *batchv1.CronJob) []string {
 	podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
 	result := make([]string, 0)
 
@@ -83,3 +86,24 @@ func getContainerImages(cronJob *batchv1beta1.CronJob) []string {
 
 	return result
 }
+
+// getCronJob 按集群协商的版本获取CronJob, 统一转换为batch/v1
+func getCronJob(client client.Interface, namespace, name string) (*batchv1.CronJob, error) {
+	if common.CronJobUseV1(client) {
+		return client.BatchV1().CronJobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	}
+	cj, err := client.BatchV1beta1().CronJobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	converted := common.ConvertCronJobV1beta1(cj)
+	return &converted, nil
+}
+
+// deleteCronJob 按集群协商的版本删除CronJob
+func deleteCronJob(client client.Interface, namespace, name string) error {
+	if common.CronJobUseV1(client) {
+		return client.BatchV1().CronJobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+	return client.BatchV1beta1().CronJobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
@@ -17,14 +17,16 @@ limitations under the License.
 package cronjob
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
-	batch2 "k8s.io/api/batch/v1beta1"
-	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/robfig/cron/v3"
+	batch "k8s.io/api/batch/v1"
 	"k8s.io/client-go/kubernetes"
+	"time"
 )
 
+// nextScheduleCount 详情中展示的后续调度次数
+const nextScheduleCount = 5
+
 // CronJobDetail contains Cron Job details.
 type CronJobDetail struct {
 	// Extends list item structure.
@@ -34,28 +36,52 @@ type CronJobDetail struct {
 
 	StartingDeadLineSeconds *int64 `json:"startingDeadlineSeconds"`
 
+	// NextSchedules 按调度表达式计算的后续执行时间, 暂停时为空
+	NextSchedules []time.Time `json:"nextSchedules"`
+
 	JobList *JobList `json:"jobList"`
 }
 
 // GetCronJobDetail gets Cron Job details.
 func GetCronJobDetail(client *kubernetes.Clientset, namespace, name string) (*CronJobDetail, error) {
 
-	rawObject, err := client.BatchV1beta1().CronJobs(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	rawObject, err := getCronJob(client, namespace, name)
 	if err != nil {
 		return nil, err
 	}
-	j, _ := json.Marshal(rawObject)
-	fmt.Printf("cronJob: %s\n", j)
 	cj := toCronJobDetail(rawObject, client, name)
 	return &cj, nil
 }
 
-func toCronJobDetail(cj *batch2.CronJob, client *kubernetes.Clientset, name string) CronJobDetail {
-
-	return CronJobDetail{
+func toCronJobDetail(cj *batch.CronJob, client *kubernetes.Clientset, name string) CronJobDetail {
+	detail := CronJobDetail{
 		CronJob:                 toCronJob(cj),
 		ConcurrencyPolicy:       string(cj.Spec.ConcurrencyPolicy),
 		StartingDeadLineSeconds: cj.Spec.StartingDeadlineSeconds,
+		NextSchedules:           make([]time.Time, 0),
 		JobList:                 getJobList(client, cj, name),
 	}
+	if cj.Spec.Suspend == nil || !*cj.Spec.Suspend {
+		if next, err := NextSchedules(cj.Spec.Schedule, time.Now(), nextScheduleCount); err == nil {
+			detail.NextSchedules = next
+		}
+	}
+	return detail
+}
+
+// NextSchedules 计算调度表达式在from之后的count次执行时间
+func NextSchedules(schedule string, from time.Time, count int) ([]time.Time, error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("解析调度表达式%q失败: %v", schedule, err)
+	}
+	result := make([]time.Time, 0, count)
+	for next := from; len(result) < count; {
+		next = sched.Next(next)
+		if next.IsZero() {
+			break
+		}
+		result = append(result, next)
+	}
+	return result, nil
 }
@@ -0,0 +1,79 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"kubespace/server/common"
+	"testing"
+	"time"
+)
+
+func TestTriggerCronJobV1beta1(t *testing.T) {
+	common.LOG = zap.NewNop()
+	client := fake.NewSimpleClientset(&v1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default", UID: "cj-uid"},
+		Spec:       v1beta1.CronJobSpec{Schedule: "*/5 * * * *"},
+	})
+	client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.20.4"}
+
+	if err := SuspendCronJob(client, "default", "backup", true); err != nil {
+		t.Fatal(err)
+	}
+	cj, err := client.BatchV1beta1().CronJobs("default").Get(context.TODO(), "backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cj.Spec.Suspend == nil || !*cj.Spec.Suspend {
+		t.Fatalf("cronjob not suspended: %v", cj.Spec.Suspend)
+	}
+
+	job, err := TriggerCronJob(client, "default", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := job.OwnerReferences
+	if len(owner) != 1 || owner[0].APIVersion != "batch/v1beta1" || owner[0].UID != "cj-uid" {
+		t.Fatalf("unexpected owner references: %+v", owner)
+	}
+	if job.Annotations[instantiateAnnotation] != "manual" {
+		t.Fatalf("missing instantiate annotation: %v", job.Annotations)
+	}
+}
+
+func TestNextSchedules(t *testing.T) {
+	from := time.Date(2021, 1, 1, 10, 7, 0, 0, time.UTC)
+	next, err := NextSchedules("*/15 * * * *", from, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{15, 30, 45}
+	for i, n := range next {
+		if n.Minute() != want[i] {
+			t.Fatalf("schedule %d: want minute %d, got %v", i, want[i], n)
+		}
+	}
+	if _, err := NextSchedules("bad", from, 3); err == nil {
+		t.Fatal("expected error for invalid schedule")
+	}
+}
@@ -20,7 +20,6 @@ import (
 	"context"
 	"go.uber.org/zap"
 	batch "k8s.io/api/batch/v1"
-	batch2 "k8s.io/api/batch/v1beta1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"kubespace/server/common"
@@ -40,7 +39,7 @@ type JobList struct {
 	Jobs []job.Job `json:"jobs"`
 }
 
-func getJobList(client *kubernetes.Clientset, cj *batch2.CronJob, name string) (jo *JobList) {
+func getJobList(client *kubernetes.Clientset, cj *batch.CronJob, name string) (jo *JobList) {
 
 	jobData, err := client.BatchV1().Jobs(cj.Namespace).List(context.TODO(), metaV1.ListOptions{})
 	if err != nil {
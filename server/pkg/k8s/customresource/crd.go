@@ -0,0 +1,194 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"context"
+	"fmt"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+// crdResource CRD本身的GVR, 通过dynamic client读取, 避免引入apiextensions依赖
+var crdResource = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// CustomResourceDefinitionList contains a list of Custom Resource Definitions in the cluster.
+type CustomResourceDefinitionList struct {
+	ListMeta k8s.ListMeta `json:"listMeta"`
+
+	// Unordered list of Custom Resource Definitions
+	Items []CustomResourceDefinition `json:"items"`
+}
+
+// CustomResourceDefinition CRD列表展示结构
+type CustomResourceDefinition struct {
+	ObjectMeta k8s.ObjectMeta `json:"objectMeta"`
+	TypeMeta   k8s.TypeMeta   `json:"typeMeta"`
+
+	Group    string   `json:"group"`
+	Kind     string   `json:"kind"`
+	Plural   string   `json:"plural"`
+	Scope    string   `json:"scope"`
+	Versions []string `json:"versions"`
+
+	// Version 浏览实例时使用的版本, 优先使用存储版本
+	Version     string `json:"version"`
+	Established bool   `json:"established"`
+}
+
+// CustomResourceDefinitionDetail CRD详情, 包含当前版本的打印列
+type CustomResourceDefinitionDetail struct {
+	// Extends list item structure.
+	CustomResourceDefinition `json:",inline"`
+
+	PrinterColumns []PrinterColumn `json:"printerColumns"`
+}
+
+// PrinterColumn 对应CRD版本中的additionalPrinterColumns
+type PrinterColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+	Priority    int64  `json:"priority"`
+	JSONPath    string `json:"jsonPath"`
+}
+
+// GroupVersionResource 实例所在的GVR
+func (crd CustomResourceDefinitionDetail) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: crd.Group, Version: crd.Version, Resource: crd.Plural}
+}
+
+// Namespaced 实例是否属于命名空间
+func (crd CustomResourceDefinitionDetail) Namespaced() bool {
+	return crd.Scope == "Namespaced"
+}
+
+// toCustomResourceDefinitionDetail 解析unstructured格式的CRD
+func toCustomResourceDefinitionDetail(obj *unstructured.Unstructured) *CustomResourceDefinitionDetail {
+	group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+	plural, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "plural")
+	scope, _, _ := unstructured.NestedString(obj.Object, "spec", "scope")
+	versions, _, _ := unstructured.NestedSlice(obj.Object, "spec", "versions")
+
+	detail := &CustomResourceDefinitionDetail{
+		CustomResourceDefinition: CustomResourceDefinition{
+			ObjectMeta:  toObjectMeta(obj),
+			TypeMeta:    k8s.NewTypeMeta(k8s.ResourceKindCustomResourceDefinition),
+			Group:       group,
+			Kind:        kind,
+			Plural:      plural,
+			Scope:       scope,
+			Versions:    make([]string, 0, len(versions)),
+			Established: isEstablished(obj),
+		},
+		PrinterColumns: make([]PrinterColumn, 0),
+	}
+
+	var chosen map[string]interface{}
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		served, _, _ := unstructured.NestedBool(version, "served")
+		storage, _, _ := unstructured.NestedBool(version, "storage")
+		detail.Versions = append(detail.Versions, name)
+		if served && (chosen == nil || storage) {
+			chosen = version
+			detail.Version = name
+		}
+	}
+	if chosen == nil {
+		return detail
+	}
+
+	columns, _, _ := unstructured.NestedSlice(chosen, "additionalPrinterColumns")
+	for _, c := range columns {
+		column, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var printerColumn PrinterColumn
+		printerColumn.Name, _, _ = unstructured.NestedString(column, "name")
+		printerColumn.Type, _, _ = unstructured.NestedString(column, "type")
+		printerColumn.Format, _, _ = unstructured.NestedString(column, "format")
+		printerColumn.Description, _, _ = unstructured.NestedString(column, "description")
+		printerColumn.Priority, _, _ = unstructured.NestedInt64(column, "priority")
+		printerColumn.JSONPath, _, _ = unstructured.NestedString(column, "jsonPath")
+		detail.PrinterColumns = append(detail.PrinterColumns, printerColumn)
+	}
+	return detail
+}
+
+func isEstablished(obj *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCustomResourceDefinitionList returns all the custom resource definitions in the cluster.
+func GetCustomResourceDefinitionList(client dynamic.Interface, dsQuery *dataselect.DataSelectQuery) (*CustomResourceDefinitionList, error) {
+	common.LOG.Info("Getting list of all custom resource definitions in the cluster")
+	list, err := client.Resource(crdResource).List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	crds := make([]CustomResourceDefinition, 0, len(list.Items))
+	for i := range list.Items {
+		crds = append(crds, toCustomResourceDefinitionDetail(&list.Items[i]).CustomResourceDefinition)
+	}
+
+	crdCells, filteredTotal := dataselect.GenericDataSelectWithFilter(toDefinitionCells(crds), dsQuery)
+	return &CustomResourceDefinitionList{
+		ListMeta: k8s.ListMeta{TotalItems: filteredTotal},
+		Items:    fromDefinitionCells(crdCells),
+	}, nil
+}
+
+// GetCustomResourceDefinitionDetail returns detailed information about a custom resource definition.
+func GetCustomResourceDefinitionDetail(client dynamic.Interface, name string) (*CustomResourceDefinitionDetail, error) {
+	obj, err := client.Resource(crdResource).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	detail := toCustomResourceDefinitionDetail(obj)
+	if detail.Version == "" {
+		return nil, fmt.Errorf("CRD %s 没有可用的版本", name)
+	}
+	return detail, nil
+}
@@ -0,0 +1,85 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+// The code below allows to perform complex data section on []CustomResourceDefinition and []CustomResourceObject
+
+type CustomResourceDefinitionCell CustomResourceDefinition
+
+func (self CustomResourceDefinitionCell) GetProperty(name dataselect.PropertyName) dataselect.ComparableValue {
+	switch name {
+	case dataselect.NameProperty:
+		return dataselect.StdComparableString(self.ObjectMeta.Name)
+	case dataselect.CreationTimestampProperty:
+		return dataselect.StdComparableTime(self.ObjectMeta.CreationTimestamp.Time)
+	default:
+		// if name is not supported then just return a constant dummy value, sort will have no effect.
+		return nil
+	}
+}
+
+func toDefinitionCells(std []CustomResourceDefinition) []dataselect.DataCell {
+	cells := make([]dataselect.DataCell, len(std))
+	for i := range std {
+		cells[i] = CustomResourceDefinitionCell(std[i])
+	}
+	return cells
+}
+
+func fromDefinitionCells(cells []dataselect.DataCell) []CustomResourceDefinition {
+	std := make([]CustomResourceDefinition, len(cells))
+	for i := range std {
+		std[i] = CustomResourceDefinition(cells[i].(CustomResourceDefinitionCell))
+	}
+	return std
+}
+
+type CustomResourceObjectCell CustomResourceObject
+
+func (self CustomResourceObjectCell) GetProperty(name dataselect.PropertyName) dataselect.ComparableValue {
+	switch name {
+	case dataselect.NameProperty:
+		return dataselect.StdComparableString(self.ObjectMeta.Name)
+	case dataselect.CreationTimestampProperty:
+		return dataselect.StdComparableTime(self.ObjectMeta.CreationTimestamp.Time)
+	case dataselect.NamespaceProperty:
+		return dataselect.StdComparableString(self.ObjectMeta.Namespace)
+	default:
+		// if name is not supported then just return a constant dummy value, sort will have no effect.
+		return nil
+	}
+}
+
+func toObjectCells(std []CustomResourceObject) []dataselect.DataCell {
+	cells := make([]dataselect.DataCell, len(std))
+	for i := range std {
+		cells[i] = CustomResourceObjectCell(std[i])
+	}
+	return cells
+}
+
+func fromObjectCells(cells []dataselect.DataCell) []CustomResourceObject {
+	std := make([]CustomResourceObject, len(cells))
+	for i := range std {
+		std[i] = CustomResourceObject(cells[i].(CustomResourceObjectCell))
+	}
+	return std
+}
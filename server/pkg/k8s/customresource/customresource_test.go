@@ -0,0 +1,116 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"kubespace/server/common"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+var certificateResource = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+func newCertificateCRD() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "certificates.cert-manager.io"},
+		"spec": map[string]interface{}{
+			"group": "cert-manager.io",
+			"scope": "Namespaced",
+			"names": map[string]interface{}{"kind": "Certificate", "plural": "certificates"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1alpha2", "served": true, "storage": false},
+				map[string]interface{}{
+					"name": "v1", "served": true, "storage": true,
+					"additionalPrinterColumns": []interface{}{
+						map[string]interface{}{"name": "Ready", "type": "string", "jsonPath": `.status.conditions[?(@.type=="Ready")].status`},
+						map[string]interface{}{"name": "Secret", "type": "string", "jsonPath": ".spec.secretName"},
+						map[string]interface{}{"name": "Issuer", "type": "string", "jsonPath": ".spec.issuerRef.name", "priority": int64(1)},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Established", "status": "True"}},
+		},
+	}}
+}
+
+func newCertificate(namespace, name string, ready bool) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       map[string]interface{}{"secretName": name + "-tls"},
+	}}
+	if ready {
+		obj.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		}
+	}
+	return obj
+}
+
+func TestGetCustomResourceObjectList(t *testing.T) {
+	common.LOG = zap.NewNop()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			crdResource:         "CustomResourceDefinitionList",
+			certificateResource: "CertificateList",
+		},
+		newCertificateCRD(),
+		newCertificate("default", "web", true),
+		newCertificate("default", "api", false),
+		newCertificate("prod", "shop", true),
+	)
+
+	crd, err := GetCustomResourceDefinitionDetail(client, "certificates.cert-manager.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crd.Version != "v1" || !crd.Established || len(crd.PrinterColumns) != 3 {
+		t.Fatalf("unexpected crd detail: %+v", crd)
+	}
+
+	list, err := GetCustomResourceObjectList(client, "certificates.cert-manager.io",
+		k8scommon.NewSameNamespaceQuery("default"), dataselect.NewDataSelectQuery(dataselect.NoPagination, dataselect.NoSort, dataselect.NoFilter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.ListMeta.TotalItems != 2 {
+		t.Fatalf("total = %d, want 2", list.ListMeta.TotalItems)
+	}
+
+	values := make(map[string][]interface{})
+	for _, item := range list.Items {
+		values[item.ObjectMeta.Name] = item.Values
+	}
+	if v := values["web"]; v[0] != "True" || v[1] != "web-tls" || v[2] != nil {
+		t.Errorf("web values = %v", v)
+	}
+	if v := values["api"]; v[0] != nil || v[1] != "api-tls" {
+		t.Errorf("api values = %v", v)
+	}
+}
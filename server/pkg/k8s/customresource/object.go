@@ -0,0 +1,167 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/jsonpath"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"kubespace/server/pkg/k8s/dataselect"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// CustomResourceObjectList 自定义资源实例列表, Columns为CRD定义的打印列
+type CustomResourceObjectList struct {
+	ListMeta k8s.ListMeta `json:"listMeta"`
+
+	Columns []PrinterColumn        `json:"columns"`
+	Items   []CustomResourceObject `json:"items"`
+}
+
+// CustomResourceObject 自定义资源实例, Values与打印列一一对应
+type CustomResourceObject struct {
+	ObjectMeta k8s.ObjectMeta `json:"objectMeta"`
+	TypeMeta   k8s.TypeMeta   `json:"typeMeta"`
+
+	Values []interface{} `json:"values"`
+}
+
+// resourceInterface 根据CRD定义获取实例的dynamic client
+func resourceInterface(client dynamic.Interface, crd *CustomResourceDefinitionDetail, namespace string) dynamic.ResourceInterface {
+	if crd.Namespaced() {
+		return client.Resource(crd.GroupVersionResource()).Namespace(namespace)
+	}
+	return client.Resource(crd.GroupVersionResource())
+}
+
+// GetCustomResourceObjectList 列出CRD的实例, 打印列取自additionalPrinterColumns
+func GetCustomResourceObjectList(client dynamic.Interface, crdName string, nsQuery *k8scommon.NamespaceQuery, dsQuery *dataselect.DataSelectQuery) (*CustomResourceObjectList, error) {
+	crd, err := GetCustomResourceDefinitionDetail(client, crdName)
+	if err != nil {
+		return nil, err
+	}
+	common.LOG.Info(fmt.Sprintf("Getting list of %s in the namespace %s", crd.Plural, nsQuery.ToRequestParam()))
+	list, err := resourceInterface(client, crd, nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := parsePrinterColumns(crd.PrinterColumns)
+	objects := make([]CustomResourceObject, 0, len(list.Items))
+	for i := range list.Items {
+		if crd.Namespaced() && !nsQuery.Matches(list.Items[i].GetNamespace()) {
+			continue
+		}
+		objects = append(objects, toCustomResourceObject(&list.Items[i], crd.Kind, columns))
+	}
+
+	objectCells, filteredTotal := dataselect.GenericDataSelectWithFilter(toObjectCells(objects), dsQuery)
+	return &CustomResourceObjectList{
+		ListMeta: k8s.ListMeta{TotalItems: filteredTotal},
+		Columns:  crd.PrinterColumns,
+		Items:    fromObjectCells(objectCells),
+	}, nil
+}
+
+func toCustomResourceObject(obj *unstructured.Unstructured, kind string, columns []*jsonpath.JSONPath) CustomResourceObject {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = columnValue(column, obj.Object)
+	}
+	return CustomResourceObject{
+		ObjectMeta: toObjectMeta(obj),
+		TypeMeta:   k8s.NewTypeMeta(k8s.ResourceKind(kind)),
+		Values:     values,
+	}
+}
+
+func toObjectMeta(obj *unstructured.Unstructured) k8s.ObjectMeta {
+	return k8s.NewObjectMeta(metaV1.ObjectMeta{
+		Name:              obj.GetName(),
+		Namespace:         obj.GetNamespace(),
+		Labels:            obj.GetLabels(),
+		Annotations:       obj.GetAnnotations(),
+		CreationTimestamp: obj.GetCreationTimestamp(),
+	})
+}
+
+// parsePrinterColumns 按kubectl的方式解析打印列的JSONPath, 解析失败的列取值为空
+func parsePrinterColumns(columns []PrinterColumn) []*jsonpath.JSONPath {
+	parsers := make([]*jsonpath.JSONPath, len(columns))
+	for i, column := range columns {
+		parser := jsonpath.New(column.Name).AllowMissingKeys(true)
+		if err := parser.Parse(fmt.Sprintf("{%s}", column.JSONPath)); err != nil {
+			continue
+		}
+		parsers[i] = parser
+	}
+	return parsers
+}
+
+func columnValue(parser *jsonpath.JSONPath, obj map[string]interface{}) interface{} {
+	if parser == nil {
+		return nil
+	}
+	results, err := parser.FindResults(obj)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		return nil
+	}
+	if len(results[0]) == 1 {
+		return results[0][0].Interface()
+	}
+	var buf bytes.Buffer
+	if err := parser.PrintResults(&buf, results[0]); err != nil {
+		return nil
+	}
+	return buf.String()
+}
+
+// GetCustomResourceObjectYAML 获取实例的YAML
+func GetCustomResourceObjectYAML(client dynamic.Interface, crdName, namespace, name string) (string, error) {
+	crd, err := GetCustomResourceDefinitionDetail(client, crdName)
+	if err != nil {
+		return "", err
+	}
+	obj, err := resourceInterface(client, crd, namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	obj.SetManagedFields(nil)
+
+	data, err := sigsyaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DeleteCustomResourceObject 删除自定义资源实例
+func DeleteCustomResourceObject(client dynamic.Interface, crdName, namespace, name string) error {
+	crd, err := GetCustomResourceDefinitionDetail(client, crdName)
+	if err != nil {
+		return err
+	}
+	common.LOG.Info(fmt.Sprintf("请求删除%s: %v, namespace: %v", crd.Kind, name, namespace))
+	return resourceInterface(client, crd, namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+}
@@ -32,6 +32,7 @@ import (
 	k8scommon "kubespace/server/pkg/k8s/common"
 	"kubespace/server/pkg/k8s/dataselect"
 	"kubespace/server/pkg/k8s/event"
+	"kubespace/server/pkg/k8s/horizontalpodautoscaler"
 	"kubespace/server/tools"
 	"time"
 )
@@ -211,6 +212,10 @@ func ScaleDeployment(client *kubernetes.Clientset, ns string, deploymentName str
 
 	common.LOG.Info(fmt.Sprintf("start scale of %v deployment in %v namespace", deploymentName, ns))
 
+	if err = horizontalpodautoscaler.CheckManualScale(client, ns, "Deployment", deploymentName); err != nil {
+		return err
+	}
+
 	scaleData, err := client.AppsV1().Deployments(ns).GetScale(
 		context.TODO(),
 		deploymentName,
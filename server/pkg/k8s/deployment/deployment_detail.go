@@ -27,6 +27,7 @@ import (
 	"kubespace/server/common"
 	k8scommon "kubespace/server/pkg/k8s/common"
 	"kubespace/server/pkg/k8s/event"
+	"kubespace/server/pkg/k8s/horizontalpodautoscaler"
 	"kubespace/server/pkg/k8s/service"
 	"kubespace/server/tools"
 	"sort"
@@ -90,6 +91,9 @@ type DeploymentDetail struct {
 	PodList *PodList `json:"podList"`
 
 	SvcList *service.ServiceList `json:"svcList"`
+
+	// HPAList 以该Deployment为伸缩目标的HPA, 包含当前和期望的指标
+	HPAList []horizontalpodautoscaler.HorizontalPodAutoscaler `json:"hpaList"`
 }
 
 // GetDeploymentDetail returns model object of deployment and error, if any.
@@ -145,6 +149,7 @@ func GetDeploymentDetail(client *kubernetes.Clientset, namespace string, deploym
 	}
 	events, _ := event.GetEvents(client, namespace, fmt.Sprintf("involvedObject.name=%v", deploymentName))
 	serviceList, _ := service.GetToService(client, namespace, deploymentName)
+	hpaList, _ := horizontalpodautoscaler.GetTargetHorizontalPodAutoscalers(client, namespace, "Deployment", deploymentName)
 
 	return &DeploymentDetail{
 		Deployment:            toDeployment(deployment, rawRs.Items, rawPods.Items, rawEvents.Items),
@@ -158,6 +163,7 @@ func GetDeploymentDetail(client *kubernetes.Clientset, namespace string, deploym
 		Events:                events,
 		PodList:               getDeploymentToPod(client, deployment),
 		SvcList:               serviceList,
+		HPAList:               hpaList,
 		HistoryVersion:        getDeploymentHistory(namespace, deploymentName, rawRs.Items),
 	}, nil
 }
@@ -178,6 +184,8 @@ type HistoryVersion struct {
 	Version    int64       `json:"version"`
 	Namespace  string      `json:"namespace"`
 	Name       string      `json:"name"`
+	// ChangeCause 变更原因, 取自ReplicaSet的kubernetes.io/change-cause注解
+	ChangeCause string `json:"change_cause"`
 }
 
 func getDeploymentHistory(namespace string, deploymentName string, rs []apps.ReplicaSet) []HistoryVersion {
@@ -187,11 +195,12 @@ func getDeploymentHistory(namespace string, deploymentName string, rs []apps.Rep
 	for _, v := range rs {
 		if namespace == v.Namespace && deploymentName == v.OwnerReferences[0].Name {
 			history := HistoryVersion{
-				CreateTime: v.CreationTimestamp,
-				Image:      v.Spec.Template.Spec.Containers[0].Image,
-				Version:    tools.ParseStringToInt64(v.Annotations["deployment.kubernetes.io/revision"]),
-				Namespace:  v.Namespace,
-				Name:       v.OwnerReferences[0].Name,
+				CreateTime:  v.CreationTimestamp,
+				Image:       v.Spec.Template.Spec.Containers[0].Image,
+				Version:     tools.ParseStringToInt64(v.Annotations["deployment.kubernetes.io/revision"]),
+				Namespace:   v.Namespace,
+				Name:        v.OwnerReferences[0].Name,
+				ChangeCause: v.Annotations["kubernetes.io/change-cause"],
 			}
 			historyVersion = append(historyVersion, history)
 
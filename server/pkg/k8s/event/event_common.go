@@ -23,6 +23,7 @@ import (
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 	"kubespace/server/models/k8s"
 	k8scommon "kubespace/server/pkg/k8s/common"
@@ -184,6 +185,34 @@ func GetEvents(client *kubernetes.Clientset, namespace, resourceName string) ([]
 		return nil, err
 	}
 
+	return listEvents(client, namespace, fieldSelector)
+}
+
+// GetObjectEvents 按名称、kind和API组获取资源的事件, 用于自定义资源等可能与其他资源同名的对象
+func GetObjectEvents(client *kubernetes.Clientset, dsQuery *dataselect.DataSelectQuery, namespace, name, kind, group string) (*k8scommon.EventList, error) {
+	fieldSelector := fields.Set{"involvedObject.name": name, "involvedObject.kind": kind}.AsSelector()
+	resourceEvents, err := listEvents(client, namespace, fieldSelector)
+	if err != nil {
+		return EmptyEventList, err
+	}
+
+	events := CreateEventList(filterEventsByGroup(resourceEvents, group), dsQuery)
+	return &events, nil
+}
+
+// filterEventsByGroup 按involvedObject的API组过滤事件, 同一个组的不同版本都保留
+func filterEventsByGroup(events []v1.Event, group string) []v1.Event {
+	result := make([]v1.Event, 0, len(events))
+	for _, event := range events {
+		gv, err := schema.ParseGroupVersion(event.InvolvedObject.APIVersion)
+		if err == nil && gv.Group == group {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func listEvents(client *kubernetes.Clientset, namespace string, fieldSelector fields.Selector) ([]v1.Event, error) {
 	channels := &k8scommon.ResourceChannels{
 		EventList: k8scommon.GetEventListChannelWithOptions(client, k8scommon.NewSameNamespaceQuery(namespace),
 			metaV1.ListOptions{
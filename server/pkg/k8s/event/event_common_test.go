@@ -0,0 +1,39 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestFilterEventsByGroup(t *testing.T) {
+	event := func(apiVersion string) v1.Event {
+		return v1.Event{InvolvedObject: v1.ObjectReference{Kind: "Certificate", Name: "web", APIVersion: apiVersion}}
+	}
+	events := []v1.Event{
+		event("cert-manager.io/v1"),
+		event("cert-manager.io/v1alpha2"),
+		event("certmanager.k8s.io/v1alpha1"), // 其他组的同名kind
+		event("v1"),
+	}
+	got := filterEventsByGroup(events, "cert-manager.io")
+	if len(got) != 2 || got[0].InvolvedObject.APIVersion != "cert-manager.io/v1" || got[1].InvolvedObject.APIVersion != "cert-manager.io/v1alpha2" {
+		t.Fatalf("unexpected events: %+v", got)
+	}
+}
@@ -0,0 +1,286 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evict
+
+import (
+	"context"
+	"fmt"
+	uuid "github.com/satori/go.uuid"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/drain"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 排空任务状态
+const (
+	DrainRunning   = "Running"
+	DrainSucceeded = "Succeeded"
+	DrainFailed    = "Failed"
+)
+
+// 待驱逐Pod状态
+const (
+	DrainPodPending = "Pending"
+	DrainPodEvicted = "Evicted"
+)
+
+const (
+	// defaultDrainTimeout 未指定超时时间时的默认值
+	defaultDrainTimeout = 10 * time.Minute
+	// drainTaskRetain 已结束的排空任务保留时间, 供前端轮询结果
+	drainTaskRetain = time.Hour
+)
+
+// DrainPod 待驱逐的Pod
+type DrainPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+}
+
+// DrainTask 异步排空任务, 前端通过任务ID轮询进度
+type DrainTask struct {
+	ID        string     `json:"id"`
+	NodeName  string     `json:"nodeName"`
+	Status    string     `json:"status"`
+	Message   string     `json:"message"`
+	Warnings  string     `json:"warnings"`
+	Total     int        `json:"total"`
+	Evicted   int        `json:"evicted"`
+	Pods      []DrainPod `json:"pods"`
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime"`
+
+	key string
+	mu  sync.RWMutex
+}
+
+func (t *DrainTask) setPods(pods []corev1.Pod, warnings string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Warnings = warnings
+	t.Total = len(pods)
+	t.Pods = make([]DrainPod, 0, len(pods))
+	for _, p := range pods {
+		t.Pods = append(t.Pods, DrainPod{Namespace: p.Namespace, Name: p.Name, Status: DrainPodPending})
+	}
+}
+
+func (t *DrainTask) podEvicted(pod *corev1.Pod) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.Pods {
+		if t.Pods[i].Namespace == pod.Namespace && t.Pods[i].Name == pod.Name && t.Pods[i].Status != DrainPodEvicted {
+			t.Pods[i].Status = DrainPodEvicted
+			t.Evicted++
+			return
+		}
+	}
+}
+
+func (t *DrainTask) setMessage(msg string) {
+	t.mu.Lock()
+	t.Message = msg
+	t.mu.Unlock()
+}
+
+func (t *DrainTask) finish(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.EndTime = &now
+	if err != nil {
+		t.Status = DrainFailed
+		t.Message = err.Error()
+		return
+	}
+	t.Status = DrainSucceeded
+}
+
+// snapshot 返回任务当前进度的副本
+func (t *DrainTask) snapshot() *DrainTask {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return &DrainTask{
+		ID:        t.ID,
+		NodeName:  t.NodeName,
+		Status:    t.Status,
+		Message:   t.Message,
+		Warnings:  t.Warnings,
+		Total:     t.Total,
+		Evicted:   t.Evicted,
+		Pods:      append([]DrainPod(nil), t.Pods...),
+		StartTime: t.StartTime,
+		EndTime:   t.EndTime,
+	}
+}
+
+func newDrainTask(key, nodeName string) *DrainTask {
+	return &DrainTask{
+		ID:        uuid.NewV4().String(),
+		NodeName:  nodeName,
+		Status:    DrainRunning,
+		Pods:      make([]DrainPod, 0),
+		StartTime: time.Now(),
+		key:       key,
+	}
+}
+
+type drainTaskMap struct {
+	sync.Mutex
+	tasks map[string]*DrainTask
+}
+
+// drainTasks 全局排空任务, 同一个节点同时只允许一个排空任务
+var drainTasks = &drainTaskMap{tasks: make(map[string]*DrainTask)}
+
+func (m *drainTaskMap) add(task *DrainTask) error {
+	m.Lock()
+	defer m.Unlock()
+	for id, t := range m.tasks {
+		t.mu.RLock()
+		status, end := t.Status, t.EndTime
+		t.mu.RUnlock()
+		if status == DrainRunning && t.key == task.key {
+			return fmt.Errorf("节点%s正在排空中, 任务ID: %s", task.NodeName, t.ID)
+		}
+		if end != nil && time.Since(*end) > drainTaskRetain {
+			delete(m.tasks, id)
+		}
+	}
+	m.tasks[task.ID] = task
+	return nil
+}
+
+// GetDrainTask 获取排空任务进度
+func GetDrainTask(id string) (*DrainTask, error) {
+	drainTasks.Lock()
+	task, ok := drainTasks.tasks[id]
+	drainTasks.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("排空任务%s不存在或已过期", id)
+	}
+	return task.snapshot(), nil
+}
+
+// StartDrain 创建异步排空任务, key用于区分不同集群的同名节点
+func StartDrain(client kubernetes.Interface, key string, opts k8s.NodeDrain) (*DrainTask, error) {
+	task := newDrainTask(key, opts.NodeName)
+	if err := drainTasks.add(task); err != nil {
+		return nil, err
+	}
+	go func() {
+		startTime := time.Now()
+		common.LOG.Info(fmt.Sprintf("排空Node节点:%v, 异步任务已开始", opts.NodeName))
+		err := runDrain(client, opts, task)
+		if err == nil && opts.DeleteNode {
+			task.setMessage("排空完成, 正在移除节点")
+			err = client.CoreV1().Nodes().Delete(context.TODO(), opts.NodeName, metav1.DeleteOptions{})
+		}
+		task.finish(err)
+		if err != nil {
+			common.LOG.Error(fmt.Sprintf("排空节点%v失败: %v", opts.NodeName, err))
+			return
+		}
+		common.LOG.Info(fmt.Sprintf("已排空节点：%v, 异步任务已完成,任务耗时：%v", opts.NodeName, time.Since(startTime)))
+	}()
+	return task.snapshot(), nil
+}
+
+// DrainNode 同步排空节点, 等同于 kubectl drain --ignore-daemonsets
+func DrainNode(client kubernetes.Interface, opts k8s.NodeDrain) error {
+	task := newDrainTask("", opts.NodeName)
+	err := runDrain(client, opts, task)
+	task.finish(err)
+	return err
+}
+
+// logWriter 将kubectl drain的输出写入日志
+type logWriter struct {
+	nodeName string
+	error    bool
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	msg := fmt.Sprintf("排空节点%s: %s", w.nodeName, strings.TrimSpace(string(p)))
+	if w.error {
+		common.LOG.Warn(msg)
+	} else {
+		common.LOG.Info(msg)
+	}
+	return len(p), nil
+}
+
+// runDrain 设置节点不可调度并驱逐节点上的Pod:
+// 跳过DaemonSet管理的Pod和静态Pod, PDB拒绝(429)时退避重试, 按Pod自身的优雅退出时间等待删除
+func runDrain(client kubernetes.Interface, opts k8s.NodeDrain, task *DrainTask) error {
+	timeout := defaultDrainTimeout
+	if opts.TimeoutSeconds > 0 {
+		timeout = time.Duration(opts.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	helper := &drain.Helper{
+		Ctx:                 ctx,
+		Client:              client,
+		Force:               opts.Force,
+		GracePeriodSeconds:  -1,
+		IgnoreAllDaemonSets: true,
+		DeleteEmptyDirData:  opts.DeleteEmptyDirData,
+		Timeout:             timeout,
+		Out:                 logWriter{nodeName: opts.NodeName},
+		ErrOut:              logWriter{nodeName: opts.NodeName, error: true},
+		OnPodDeletedOrEvicted: func(pod *corev1.Pod, usingEviction bool) {
+			task.podEvicted(pod)
+		},
+	}
+	if opts.GracePeriodSeconds != nil {
+		helper.GracePeriodSeconds = *opts.GracePeriodSeconds
+	}
+
+	node, err := client.CoreV1().Nodes().Get(ctx, opts.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	task.setMessage("设置节点不可调度")
+	if err := drain.RunCordonOrUncordon(helper, node, true); err != nil {
+		return err
+	}
+
+	list, errs := helper.GetPodsForDeletion(opts.NodeName)
+	if errs != nil {
+		return utilerrors.NewAggregate(errs)
+	}
+	task.setPods(list.Pods(), list.Warnings())
+	task.setMessage("正在驱逐Pod")
+	if err := helper.DeleteOrEvictPods(list.Pods()); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("排空节点超时(%v): %v", timeout, err)
+		}
+		return err
+	}
+	task.setMessage("")
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evict
+
+import (
+	"context"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	"testing"
+	"time"
+)
+
+func newDrainTestPod(name string, owner string, mirror bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+	}
+	if owner != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: owner, Name: name, Controller: &controller}}
+	}
+	if mirror {
+		pod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "mirror"}
+	}
+	return pod
+}
+
+func TestDrain(t *testing.T) {
+	common.LOG = zap.NewNop()
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"}}
+	client := fake.NewSimpleClientset(node, daemonSet,
+		newDrainTestPod("web", "ReplicaSet", false),
+		newDrainTestPod("agent", "DaemonSet", false),
+		newDrainTestPod("static", "", true),
+		newDrainTestPod("bare", "", false),
+	)
+	// fake客户端不会执行驱逐, 收到驱逐请求时删除Pod
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods/eviction", Kind: "Eviction", Group: "policy", Version: "v1beta1"}},
+	}}
+	client.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(clienttesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		return true, nil, client.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
+
+	// 不受控制器管理的Pod需要force
+	if err := DrainNode(client, k8s.NodeDrain{NodeName: "node-1"}); err == nil {
+		t.Fatal("expected drain to refuse unmanaged pod without force")
+	}
+	if _, err := client.CoreV1().Pods("default").Get(context.TODO(), "web", metav1.GetOptions{}); err != nil {
+		t.Fatalf("no pod should be evicted when drain is refused: %v", err)
+	}
+
+	task, err := StartDrain(client, "1/node-1", k8s.NodeDrain{NodeName: "node-1", Force: true, TimeoutSeconds: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StartDrain(client, "1/node-1", k8s.NodeDrain{NodeName: "node-1"}); err == nil {
+		t.Fatal("expected concurrent drain of the same node to be rejected")
+	}
+
+	deadline := time.Now().Add(20 * time.Second)
+	for task.Status == DrainRunning && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		if task, err = GetDrainTask(task.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if task.Status != DrainSucceeded {
+		t.Fatalf("expected drain to succeed, got %s: %s", task.Status, task.Message)
+	}
+	if task.Total != 2 || task.Evicted != 2 {
+		t.Fatalf("expected 2 evicted pods, got %d/%d: %+v", task.Evicted, task.Total, task.Pods)
+	}
+
+	pods, _ := client.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{})
+	remaining := map[string]bool{}
+	for _, p := range pods.Items {
+		remaining[p.Name] = true
+	}
+	if !remaining["agent"] || !remaining["static"] || remaining["web"] || remaining["bare"] {
+		t.Fatalf("unexpected remaining pods %v", remaining)
+	}
+	node, _ = client.CoreV1().Nodes().Get(context.TODO(), "node-1", metav1.GetOptions{})
+	if !node.Spec.Unschedulable {
+		t.Fatal("expected node to be cordoned")
+	}
+}
@@ -0,0 +1,211 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package horizontalpodautoscaler
+
+import (
+	"fmt"
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+// HorizontalPodAutoscalerList contains a list of Horizontal Pod Autoscalers in the cluster.
+type HorizontalPodAutoscalerList struct {
+	ListMeta k8s.ListMeta `json:"listMeta"`
+
+	// Unordered list of Horizontal Pod Autoscalers.
+	HorizontalPodAutoscalers []HorizontalPodAutoscaler `json:"horizontalpodautoscalers"`
+}
+
+// HorizontalPodAutoscaler (aka. Horizontal Pod Autoscaler)
+type HorizontalPodAutoscaler struct {
+	ObjectMeta k8s.ObjectMeta `json:"objectMeta"`
+	TypeMeta   k8s.TypeMeta   `json:"typeMeta"`
+
+	// ScaleTargetRef 伸缩的目标工作负载
+	ScaleTargetRef autoscaling.CrossVersionObjectReference `json:"scaleTargetRef"`
+
+	MinReplicas     *int32 `json:"minReplicas"`
+	MaxReplicas     int32  `json:"maxReplicas"`
+	CurrentReplicas int32  `json:"currentReplicas"`
+	DesiredReplicas int32  `json:"desiredReplicas"`
+
+	// Metrics 期望的指标
+	Metrics []autoscaling.MetricSpec `json:"metrics"`
+
+	// CurrentMetrics 当前的指标
+	CurrentMetrics []autoscaling.MetricStatus `json:"currentMetrics"`
+
+	LastScaleTime *metaV1.Time `json:"lastScaleTime"`
+}
+
+// HorizontalPodAutoscalerDetail provides the presentation layer view of Kubernetes Horizontal Pod Autoscaler resource.
+type HorizontalPodAutoscalerDetail struct {
+	// Extends list item structure.
+	HorizontalPodAutoscaler `json:",inline"`
+
+	Behavior *autoscaling.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+
+	Conditions []autoscaling.HorizontalPodAutoscalerCondition `json:"conditions"`
+}
+
+// ToHorizontalPodAutoscaler 转换为列表展示结构
+func ToHorizontalPodAutoscaler(hpa *autoscaling.HorizontalPodAutoscaler) HorizontalPodAutoscaler {
+	return HorizontalPodAutoscaler{
+		ObjectMeta:      k8s.NewObjectMeta(hpa.ObjectMeta),
+		TypeMeta:        k8s.NewTypeMeta(k8s.ResourceKindHorizontalPodAutoscaler),
+		ScaleTargetRef:  hpa.Spec.ScaleTargetRef,
+		MinReplicas:     hpa.Spec.MinReplicas,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		Metrics:         hpa.Spec.Metrics,
+		CurrentMetrics:  hpa.Status.CurrentMetrics,
+		LastScaleTime:   hpa.Status.LastScaleTime,
+	}
+}
+
+func toHorizontalPodAutoscalerDetail(hpa *autoscaling.HorizontalPodAutoscaler) *HorizontalPodAutoscalerDetail {
+	return &HorizontalPodAutoscalerDetail{
+		HorizontalPodAutoscaler: ToHorizontalPodAutoscaler(hpa),
+		Behavior:                hpa.Spec.Behavior,
+		Conditions:              hpa.Status.Conditions,
+	}
+}
+
+// GetHorizontalPodAutoscalerList returns a list of all Horizontal Pod Autoscalers in the cluster.
+func GetHorizontalPodAutoscalerList(client kubernetes.Interface, nsQuery *k8scommon.NamespaceQuery, dsQuery *dataselect.DataSelectQuery) (*HorizontalPodAutoscalerList, error) {
+	common.LOG.Info(fmt.Sprintf("Getting list of horizontal pod autoscalers in the namespace %s", nsQuery.ToRequestParam()))
+	items, err := newHPAClient(client).List(nsQuery.ToRequestParam())
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredItems []autoscaling.HorizontalPodAutoscaler
+	for _, item := range items {
+		if nsQuery.Matches(item.ObjectMeta.Namespace) {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+	return toHorizontalPodAutoscalerList(filteredItems, dsQuery), nil
+}
+
+func toHorizontalPodAutoscalerList(hpas []autoscaling.HorizontalPodAutoscaler, dsQuery *dataselect.DataSelectQuery) *HorizontalPodAutoscalerList {
+	result := &HorizontalPodAutoscalerList{
+		HorizontalPodAutoscalers: make([]HorizontalPodAutoscaler, 0),
+		ListMeta:                 k8s.ListMeta{TotalItems: len(hpas)},
+	}
+
+	hpaCells, filteredTotal := dataselect.GenericDataSelectWithFilter(toCells(hpas), dsQuery)
+	hpas = fromCells(hpaCells)
+	result.ListMeta = k8s.ListMeta{TotalItems: filteredTotal}
+
+	for i := range hpas {
+		result.HorizontalPodAutoscalers = append(result.HorizontalPodAutoscalers, ToHorizontalPodAutoscaler(&hpas[i]))
+	}
+	return result
+}
+
+// GetHorizontalPodAutoscalerDetail returns detailed information about a horizontal pod autoscaler
+func GetHorizontalPodAutoscalerDetail(client kubernetes.Interface, namespace, name string) (*HorizontalPodAutoscalerDetail, error) {
+	hpa, err := newHPAClient(client).Get(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return toHorizontalPodAutoscalerDetail(hpa), nil
+}
+
+// GetTargetHorizontalPodAutoscalers 查询以指定工作负载为伸缩目标的HPA
+func GetTargetHorizontalPodAutoscalers(client kubernetes.Interface, namespace, kind, name string) ([]HorizontalPodAutoscaler, error) {
+	items, err := newHPAClient(client).List(namespace)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]HorizontalPodAutoscaler, 0)
+	for i := range items {
+		ref := items[i].Spec.ScaleTargetRef
+		if ref.Kind == kind && ref.Name == name {
+			result = append(result, ToHorizontalPodAutoscaler(&items[i]))
+		}
+	}
+	return result, nil
+}
+
+// CheckManualScale 工作负载被HPA管理时, 手动扩缩容会被HPA覆盖, 返回错误拒绝操作.
+// 集群未提供HPA接口时视为没有HPA
+func CheckManualScale(client kubernetes.Interface, namespace, kind, name string) error {
+	hpas, err := GetTargetHorizontalPodAutoscalers(client, namespace, kind, name)
+	if err != nil {
+		if isHPAUnavailable(err) {
+			common.LOG.Warn(fmt.Sprintf("集群未提供HPA接口, 跳过HPA检查: %v", err))
+			return nil
+		}
+		return err
+	}
+	if len(hpas) > 0 {
+		return fmt.Errorf("%s %s 已被HPA %s 管理, 副本数范围为 %d-%d, 请通过修改HPA调整副本数",
+			kind, name, hpas[0].ObjectMeta.Name, minReplicas(hpas[0].MinReplicas), hpas[0].MaxReplicas)
+	}
+	return nil
+}
+
+func minReplicas(min *int32) int32 {
+	if min == nil {
+		return 1
+	}
+	return *min
+}
+
+// CreateHorizontalPodAutoscaler 创建HPA
+func CreateHorizontalPodAutoscaler(client kubernetes.Interface, data k8s.HorizontalPodAutoscalerData) (*HorizontalPodAutoscalerDetail, error) {
+	common.LOG.Info(fmt.Sprintf("创建HPA: %v, namespace: %v", data.Name, data.Namespace))
+	hpa := &autoscaling.HorizontalPodAutoscaler{
+		ObjectMeta: metaV1.ObjectMeta{Name: data.Name, Namespace: data.Namespace},
+		Spec:       data.Spec,
+	}
+	created, err := newHPAClient(client).Create(hpa)
+	if err != nil {
+		return nil, err
+	}
+	return toHorizontalPodAutoscalerDetail(created), nil
+}
+
+// UpdateHorizontalPodAutoscaler 更新HPA的spec
+func UpdateHorizontalPodAutoscaler(client kubernetes.Interface, data k8s.HorizontalPodAutoscalerData) (*HorizontalPodAutoscalerDetail, error) {
+	common.LOG.Info(fmt.Sprintf("更新HPA: %v, namespace: %v", data.Name, data.Namespace))
+	hpaClient := newHPAClient(client)
+	hpa, err := hpaClient.Get(data.Namespace, data.Name)
+	if err != nil {
+		return nil, err
+	}
+	hpa.Spec = data.Spec
+	updated, err := hpaClient.Update(hpa)
+	if err != nil {
+		return nil, err
+	}
+	return toHorizontalPodAutoscalerDetail(updated), nil
+}
+
+// DeleteHorizontalPodAutoscaler 删除HPA
+func DeleteHorizontalPodAutoscaler(client kubernetes.Interface, namespace, name string) error {
+	common.LOG.Info(fmt.Sprintf("请求删除HPA: %v, namespace: %v", name, namespace))
+	return newHPAClient(client).Delete(namespace, name)
+}
@@ -0,0 +1,136 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package horizontalpodautoscaler
+
+import (
+	"context"
+	"encoding/json"
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+)
+
+// hpaV2GroupVersion client-go v0.22没有autoscaling/v2的类型, 其字段与v2beta2一致,
+// 使用v2beta2的类型通过REST接口读写autoscaling/v2
+const hpaV2GroupVersion = "autoscaling/v2"
+
+// hpaClient 按集群版本使用autoscaling/v2或autoscaling/v2beta2访问HPA
+type hpaClient struct {
+	client kubernetes.Interface
+	v2     bool
+}
+
+func newHPAClient(client kubernetes.Interface) hpaClient {
+	return hpaClient{client: client, v2: k8scommon.HPAUseV2(client)}
+}
+
+func hpaPath(namespace, name string) []string {
+	path := []string{"/apis", hpaV2GroupVersion}
+	if namespace != "" {
+		path = append(path, "namespaces", namespace)
+	}
+	path = append(path, "horizontalpodautoscalers")
+	if name != "" {
+		path = append(path, name)
+	}
+	return path
+}
+
+// encodeV2 以autoscaling/v2的apiVersion序列化
+func encodeV2(hpa *autoscaling.HorizontalPodAutoscaler) ([]byte, error) {
+	hpa = hpa.DeepCopy()
+	hpa.APIVersion = hpaV2GroupVersion
+	hpa.Kind = "HorizontalPodAutoscaler"
+	return json.Marshal(hpa)
+}
+
+func decodeV2(raw []byte, err error) (*autoscaling.HorizontalPodAutoscaler, error) {
+	if err != nil {
+		return nil, err
+	}
+	hpa := &autoscaling.HorizontalPodAutoscaler{}
+	if err := json.Unmarshal(raw, hpa); err != nil {
+		return nil, err
+	}
+	return hpa, nil
+}
+
+func (h hpaClient) List(namespace string) ([]autoscaling.HorizontalPodAutoscaler, error) {
+	if !h.v2 {
+		list, err := h.client.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).List(context.TODO(), k8s.ListEverything)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+	raw, err := h.client.Discovery().RESTClient().Get().AbsPath(hpaPath(namespace, "")...).Do(context.TODO()).Raw()
+	if err != nil {
+		return nil, err
+	}
+	list := &autoscaling.HorizontalPodAutoscalerList{}
+	if err := json.Unmarshal(raw, list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (h hpaClient) Get(namespace, name string) (*autoscaling.HorizontalPodAutoscaler, error) {
+	if !h.v2 {
+		return h.client.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	}
+	return decodeV2(h.client.Discovery().RESTClient().Get().AbsPath(hpaPath(namespace, name)...).Do(context.TODO()).Raw())
+}
+
+func (h hpaClient) Create(hpa *autoscaling.HorizontalPodAutoscaler) (*autoscaling.HorizontalPodAutoscaler, error) {
+	if !h.v2 {
+		return h.client.AutoscalingV2beta2().HorizontalPodAutoscalers(hpa.Namespace).Create(context.TODO(), hpa, metaV1.CreateOptions{})
+	}
+	body, err := encodeV2(hpa)
+	if err != nil {
+		return nil, err
+	}
+	return decodeV2(h.client.Discovery().RESTClient().Post().AbsPath(hpaPath(hpa.Namespace, "")...).
+		SetHeader("Content-Type", "application/json").Body(body).Do(context.TODO()).Raw())
+}
+
+func (h hpaClient) Update(hpa *autoscaling.HorizontalPodAutoscaler) (*autoscaling.HorizontalPodAutoscaler, error) {
+	if !h.v2 {
+		return h.client.AutoscalingV2beta2().HorizontalPodAutoscalers(hpa.Namespace).Update(context.TODO(), hpa, metaV1.UpdateOptions{})
+	}
+	body, err := encodeV2(hpa)
+	if err != nil {
+		return nil, err
+	}
+	return decodeV2(h.client.Discovery().RESTClient().Put().AbsPath(hpaPath(hpa.Namespace, hpa.Name)...).
+		SetHeader("Content-Type", "application/json").Body(body).Do(context.TODO()).Raw())
+}
+
+func (h hpaClient) Delete(namespace, name string) error {
+	if !h.v2 {
+		return h.client.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+	}
+	return h.client.Discovery().RESTClient().Delete().AbsPath(hpaPath(namespace, name)...).Do(context.TODO()).Error()
+}
+
+// isHPAUnavailable 集群未提供HPA接口
+func isHPAUnavailable(err error) bool {
+	return errors.IsNotFound(err) || meta.IsNoMatchError(err)
+}
@@ -0,0 +1,56 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package horizontalpodautoscaler
+
+import (
+	api "k8s.io/api/autoscaling/v2beta2"
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+// The code below allows to perform complex data section on []api.HorizontalPodAutoscaler
+
+type HorizontalPodAutoscalerCell api.HorizontalPodAutoscaler
+
+func (self HorizontalPodAutoscalerCell) GetProperty(name dataselect.PropertyName) dataselect.ComparableValue {
+	switch name {
+	case dataselect.NameProperty:
+		return dataselect.StdComparableString(self.ObjectMeta.Name)
+	case dataselect.CreationTimestampProperty:
+		return dataselect.StdComparableTime(self.ObjectMeta.CreationTimestamp.Time)
+	case dataselect.NamespaceProperty:
+		return dataselect.StdComparableString(self.ObjectMeta.Namespace)
+	default:
+		// if name is not supported then just return a constant dummy value, sort will have no effect.
+		return nil
+	}
+}
+
+func toCells(std []api.HorizontalPodAutoscaler) []dataselect.DataCell {
+	cells := make([]dataselect.DataCell, len(std))
+	for i := range std {
+		cells[i] = HorizontalPodAutoscalerCell(std[i])
+	}
+	return cells
+}
+
+func fromCells(cells []dataselect.DataCell) []api.HorizontalPodAutoscaler {
+	std := make([]api.HorizontalPodAutoscaler, len(cells))
+	for i := range std {
+		std[i] = api.HorizontalPodAutoscaler(cells[i].(HorizontalPodAutoscalerCell))
+	}
+	return std
+}
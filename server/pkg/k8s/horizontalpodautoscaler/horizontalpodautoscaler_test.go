@@ -0,0 +1,106 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package horizontalpodautoscaler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"kubespace/server/common"
+)
+
+func TestCheckManualScale(t *testing.T) {
+	client := fake.NewSimpleClientset(&autoscaling.HorizontalPodAutoscaler{
+		ObjectMeta: metaV1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscaling.CrossVersionObjectReference{Kind: "Deployment", Name: "web", APIVersion: "apps/v1"},
+			MaxReplicas:    5,
+		},
+	})
+	// 1.23之前的集群使用autoscaling/v2beta2
+	client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.22.3"}
+
+	cases := []struct {
+		namespace, kind, name string
+		refused               bool
+	}{
+		{"default", "Deployment", "web", true},
+		{"default", "StatefulSet", "web", false},
+		{"default", "Deployment", "api", false},
+		{"other", "Deployment", "web", false},
+	}
+	for _, c := range cases {
+		err := CheckManualScale(client, c.namespace, c.kind, c.name)
+		if (err != nil) != c.refused {
+			t.Errorf("CheckManualScale(%s, %s, %s) = %v, refused want %v", c.namespace, c.kind, c.name, err, c.refused)
+		}
+	}
+}
+
+// newHPAServer 模拟autoscaling/v2的集群, hpas为nil时集群不提供HPA接口
+func newHPAServer(t *testing.T, hpas []autoscaling.HorizontalPodAutoscaler) kubernetes.Interface {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/version":
+			_ = json.NewEncoder(w).Encode(version.Info{GitVersion: "v1.27.1"})
+		case r.URL.Path == "/apis/autoscaling/v2/namespaces/default/horizontalpodautoscalers" && hpas != nil:
+			_ = json.NewEncoder(w).Encode(autoscaling.HorizontalPodAutoscalerList{Items: hpas})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(metaV1.Status{Status: metaV1.StatusFailure, Reason: metaV1.StatusReasonNotFound, Code: http.StatusNotFound})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCheckManualScaleV2(t *testing.T) {
+	common.LOG = zap.NewNop()
+	client := newHPAServer(t, []autoscaling.HorizontalPodAutoscaler{{
+		ObjectMeta: metaV1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscaling.CrossVersionObjectReference{Kind: "Deployment", Name: "web", APIVersion: "apps/v1"},
+			MaxReplicas:    5,
+		},
+	}})
+	if err := CheckManualScale(client, "default", "Deployment", "web"); err == nil {
+		t.Error("scale of a workload managed by an autoscaling/v2 HPA must be refused")
+	}
+	if err := CheckManualScale(client, "default", "Deployment", "api"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// 集群未提供HPA接口时不阻止手动扩缩容
+	if err := CheckManualScale(newHPAServer(t, nil), "default", "Deployment", "web"); err != nil {
+		t.Errorf("missing HPA API must not block scaling: %v", err)
+	}
+}
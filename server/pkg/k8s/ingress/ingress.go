@@ -17,15 +17,12 @@ limitations under the License.
 package ingress
 
 import (
-	"context"
 	"fmt"
 	"kubespace/server/common"
 	"kubespace/server/models/k8s"
 	k8scommon "kubespace/server/pkg/k8s/common"
 	"kubespace/server/pkg/k8s/dataselect"
-	//v1 "k8s.io/api/extensions/v1beta1"
 	v1 "k8s.io/api/networking/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	client "k8s.io/client-go/kubernetes"
 )
 
@@ -51,13 +48,11 @@ type IngressList struct {
 
 // GetIngressList returns all ingresses in the given namespace.
 func GetIngressList(client *client.Clientset, namespace *k8scommon.NamespaceQuery, dsQuery *dataselect.DataSelectQuery) (*IngressList, error) {
-	//ingressList, err := client.ExtensionsV1beta1().Ingresses(namespace.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
-	ingressList, err := client.NetworkingV1().Ingresses(namespace.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
-
+	ingresses, err := listIngresses(client, namespace.ToRequestParam())
 	if err != nil {
 		return nil, err
 	}
-	return toIngressList(ingressList.Items, dsQuery), nil
+	return toIngressList(ingresses, dsQuery), nil
 
 }
 
@@ -122,22 +117,14 @@ func toIngressList(ingresses []v1.Ingress, dsQuery *dataselect.DataSelectQuery)
 
 func DeleteIngress(client *client.Clientset, namespace string, name string) error {
 	common.LOG.Info(fmt.Sprintf("请求删除Ingress: %v, namespace: %v", name, namespace))
-	return client.ExtensionsV1beta1().Ingresses(namespace).Delete(
-		context.TODO(),
-		name,
-		metav1.DeleteOptions{},
-	)
+	return deleteIngress(client, namespace, name)
 }
 
 func DeleteCollectionIngress(client *client.Clientset, ingressList []k8s.ServiceData) (err error) {
 	common.LOG.Info("批量删除Ingress开始")
 	for _, v := range ingressList {
 		common.LOG.Info(fmt.Sprintf("delete ingress：%v, ns: %v", v.Name, v.Namespace))
-		err := client.ExtensionsV1beta1().Ingresses(v.Namespace).Delete(
-			context.TODO(),
-			v.Name,
-			metav1.DeleteOptions{},
-		)
+		err := deleteIngress(client, v.Namespace, v.Name)
 		if err != nil {
 			common.LOG.Error(err.Error())
 			return err
@@ -17,12 +17,9 @@ limitations under the License.
 package ingress
 
 import (
-	"context"
 	"fmt"
 	"kubespace/server/common"
-	//v1 "k8s.io/api/extensions/v1beta1"
 	v1 "k8s.io/api/networking/v1"
-	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	client "k8s.io/client-go/kubernetes"
 )
 
@@ -43,8 +40,7 @@ type IngressDetail struct {
 func GetIngressDetail(client *client.Clientset, namespace, name string) (*IngressDetail, error) {
 	common.LOG.Info(fmt.Sprintf("Getting details of %s ingress in %s namespace", name, namespace))
 
-	//rawIngress, err := client.ExtensionsV1beta1().Ingresses(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
-	rawIngress, err := client.NetworkingV1().Ingresses(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	rawIngress, err := getIngress(client, namespace, name)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,190 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+	v1 "k8s.io/api/networking/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	client "k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"strconv"
+	"strings"
+)
+
+var pathTypes = map[v1.PathType]bool{
+	v1.PathTypeExact:                  true,
+	v1.PathTypePrefix:                 true,
+	v1.PathTypeImplementationSpecific: true,
+}
+
+// ValidateIngressForm 校验Ingress表单, 错误信息可直接返回给前端
+func ValidateIngressForm(form k8s.IngressForm) error {
+	if errs := validation.IsDNS1123Subdomain(form.Name); len(errs) > 0 {
+		return fmt.Errorf("Ingress名称不合法: %s", strings.Join(errs, "; "))
+	}
+	if form.IngressClassName != "" {
+		if errs := validation.IsDNS1123Subdomain(form.IngressClassName); len(errs) > 0 {
+			return fmt.Errorf("ingressClassName不合法: %s", strings.Join(errs, "; "))
+		}
+	}
+	if err := k8scommon.ValidateLabels(form.Labels); err != nil {
+		return fmt.Errorf("labels不合法: %v", err)
+	}
+	if len(form.Rules) == 0 {
+		return fmt.Errorf("至少需要配置一条规则")
+	}
+	for _, rule := range form.Rules {
+		if err := validateHost(rule.Host); err != nil {
+			return err
+		}
+		if len(rule.Paths) == 0 {
+			return fmt.Errorf("域名%q至少需要配置一个路径", rule.Host)
+		}
+		for _, path := range rule.Paths {
+			if path.Path != "" && !strings.HasPrefix(path.Path, "/") {
+				return fmt.Errorf("路径%q必须以/开头", path.Path)
+			}
+			if path.PathType != "" && !pathTypes[v1.PathType(path.PathType)] {
+				return fmt.Errorf("不支持的pathType: %s", path.PathType)
+			}
+			if errs := validation.IsDNS1035Label(path.ServiceName); len(errs) > 0 {
+				return fmt.Errorf("Service名称%q不合法: %s", path.ServiceName, strings.Join(errs, "; "))
+			}
+			if n, err := strconv.Atoi(path.ServicePort); err == nil {
+				if errs := validation.IsValidPortNum(n); len(errs) > 0 {
+					return fmt.Errorf("Service端口%d不合法: %s", n, strings.Join(errs, "; "))
+				}
+			} else if errs := validation.IsValidPortName(path.ServicePort); len(errs) > 0 {
+				return fmt.Errorf("Service端口名称%q不合法: %s", path.ServicePort, strings.Join(errs, "; "))
+			}
+		}
+	}
+	for _, tls := range form.TLS {
+		for _, host := range tls.Hosts {
+			if err := validateHost(host); err != nil {
+				return err
+			}
+		}
+		if tls.SecretName != "" {
+			if errs := validation.IsDNS1123Subdomain(tls.SecretName); len(errs) > 0 {
+				return fmt.Errorf("TLS证书Secret名称%q不合法: %s", tls.SecretName, strings.Join(errs, "; "))
+			}
+		}
+	}
+	return nil
+}
+
+func validateHost(host string) error {
+	if host == "" {
+		return nil
+	}
+	errs := validation.IsDNS1123Subdomain(host)
+	if strings.HasPrefix(host, "*.") {
+		errs = validation.IsWildcardDNS1123Subdomain(host)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("域名%q不合法: %s", host, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// toIngressSpec 将表单转换为networking/v1的IngressSpec
+func toIngressSpec(form k8s.IngressForm) v1.IngressSpec {
+	spec := v1.IngressSpec{}
+	if form.IngressClassName != "" {
+		className := form.IngressClassName
+		spec.IngressClassName = &className
+	}
+	for _, tls := range form.TLS {
+		spec.TLS = append(spec.TLS, v1.IngressTLS{Hosts: tls.Hosts, SecretName: tls.SecretName})
+	}
+	for _, rule := range form.Rules {
+		http := &v1.HTTPIngressRuleValue{}
+		for _, path := range rule.Paths {
+			p := path.Path
+			if p == "" {
+				p = "/"
+			}
+			pathType := v1.PathType(path.PathType)
+			if pathType == "" {
+				pathType = v1.PathTypePrefix
+			}
+			backend := v1.IngressServiceBackend{Name: path.ServiceName}
+			if n, err := strconv.Atoi(path.ServicePort); err == nil {
+				backend.Port.Number = int32(n)
+			} else {
+				backend.Port.Name = path.ServicePort
+			}
+			http.Paths = append(http.Paths, v1.HTTPIngressPath{
+				Path:     p,
+				PathType: &pathType,
+				Backend:  v1.IngressBackend{Service: &backend},
+			})
+		}
+		spec.Rules = append(spec.Rules, v1.IngressRule{
+			Host:             rule.Host,
+			IngressRuleValue: v1.IngressRuleValue{HTTP: http},
+		})
+	}
+	return spec
+}
+
+// CreateIngress 按表单创建Ingress
+func CreateIngress(client client.Interface, form k8s.IngressForm) (*IngressDetail, error) {
+	common.LOG.Info(fmt.Sprintf("创建Ingress: %v, namespace: %v", form.Name, form.Namespace))
+	ingress := &v1.Ingress{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:        form.Name,
+			Namespace:   form.Namespace,
+			Labels:      form.Labels,
+			Annotations: form.Annotations,
+		},
+		Spec: toIngressSpec(form),
+	}
+	created, err := createIngress(client, ingress)
+	if err != nil {
+		return nil, err
+	}
+	return getIngressDetail(created), nil
+}
+
+// UpdateIngress 按表单更新Ingress, 规则和TLS整体替换, 默认后端保持不变, 未传labels或annotations时保留原值
+func UpdateIngress(client client.Interface, form k8s.IngressForm) (*IngressDetail, error) {
+	common.LOG.Info(fmt.Sprintf("更新Ingress: %v, namespace: %v", form.Name, form.Namespace))
+	ingress, err := getIngress(client, form.Namespace, form.Name)
+	if err != nil {
+		return nil, err
+	}
+	defaultBackend := ingress.Spec.DefaultBackend
+	if form.Labels != nil {
+		ingress.Labels = form.Labels
+	}
+	if form.Annotations != nil {
+		ingress.Annotations = form.Annotations
+	}
+	ingress.Spec = toIngressSpec(form)
+	ingress.Spec.DefaultBackend = defaultBackend
+	updated, err := updateIngress(client, ingress)
+	if err != nil {
+		return nil, err
+	}
+	return getIngressDetail(updated), nil
+}
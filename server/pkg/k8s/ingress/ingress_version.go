@@ -0,0 +1,178 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	client "k8s.io/client-go/kubernetes"
+	k8scommon "kubespace/server/pkg/k8s/common"
+)
+
+// 1.19之前的集群只提供networking/v1beta1, 以下方法按集群版本选择API, 对外统一使用networking/v1的结构
+
+func listIngresses(client client.Interface, namespace string) ([]v1.Ingress, error) {
+	if k8scommon.IngressUseV1(client) {
+		list, err := client.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+	list, err := client.NetworkingV1beta1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]v1.Ingress, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, *fromV1beta1(&list.Items[i]))
+	}
+	return items, nil
+}
+
+func getIngress(client client.Interface, namespace, name string) (*v1.Ingress, error) {
+	if k8scommon.IngressUseV1(client) {
+		return client.NetworkingV1().Ingresses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	}
+	ingress, err := client.NetworkingV1beta1().Ingresses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fromV1beta1(ingress), nil
+}
+
+func createIngress(client client.Interface, ingress *v1.Ingress) (*v1.Ingress, error) {
+	if k8scommon.IngressUseV1(client) {
+		return client.NetworkingV1().Ingresses(ingress.Namespace).Create(context.TODO(), ingress, metav1.CreateOptions{})
+	}
+	created, err := client.NetworkingV1beta1().Ingresses(ingress.Namespace).Create(context.TODO(), toV1beta1(ingress), metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fromV1beta1(created), nil
+}
+
+func updateIngress(client client.Interface, ingress *v1.Ingress) (*v1.Ingress, error) {
+	if k8scommon.IngressUseV1(client) {
+		return client.NetworkingV1().Ingresses(ingress.Namespace).Update(context.TODO(), ingress, metav1.UpdateOptions{})
+	}
+	updated, err := client.NetworkingV1beta1().Ingresses(ingress.Namespace).Update(context.TODO(), toV1beta1(ingress), metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fromV1beta1(updated), nil
+}
+
+func deleteIngress(client client.Interface, namespace, name string) error {
+	if k8scommon.IngressUseV1(client) {
+		return client.NetworkingV1().Ingresses(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+	return client.NetworkingV1beta1().Ingresses(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+func fromV1beta1(in *v1beta1.Ingress) *v1.Ingress {
+	out := &v1.Ingress{
+		ObjectMeta: in.ObjectMeta,
+		Spec: v1.IngressSpec{
+			IngressClassName: in.Spec.IngressClassName,
+			DefaultBackend:   backendFromV1beta1(in.Spec.Backend),
+		},
+		Status: v1.IngressStatus{LoadBalancer: in.Status.LoadBalancer},
+	}
+	for _, tls := range in.Spec.TLS {
+		out.Spec.TLS = append(out.Spec.TLS, v1.IngressTLS{Hosts: tls.Hosts, SecretName: tls.SecretName})
+	}
+	for _, rule := range in.Spec.Rules {
+		r := v1.IngressRule{Host: rule.Host}
+		if rule.HTTP != nil {
+			r.HTTP = &v1.HTTPIngressRuleValue{}
+			for _, path := range rule.HTTP.Paths {
+				r.HTTP.Paths = append(r.HTTP.Paths, v1.HTTPIngressPath{
+					Path:     path.Path,
+					PathType: (*v1.PathType)(path.PathType),
+					Backend:  *backendFromV1beta1(&path.Backend),
+				})
+			}
+		}
+		out.Spec.Rules = append(out.Spec.Rules, r)
+	}
+	return out
+}
+
+func backendFromV1beta1(in *v1beta1.IngressBackend) *v1.IngressBackend {
+	if in == nil {
+		return nil
+	}
+	out := &v1.IngressBackend{Resource: in.Resource}
+	if in.ServiceName != "" {
+		out.Service = &v1.IngressServiceBackend{Name: in.ServiceName}
+		if in.ServicePort.Type == intstr.String {
+			out.Service.Port.Name = in.ServicePort.StrVal
+		} else {
+			out.Service.Port.Number = in.ServicePort.IntVal
+		}
+	}
+	return out
+}
+
+func toV1beta1(in *v1.Ingress) *v1beta1.Ingress {
+	out := &v1beta1.Ingress{
+		ObjectMeta: in.ObjectMeta,
+		Spec: v1beta1.IngressSpec{
+			IngressClassName: in.Spec.IngressClassName,
+			Backend:          backendToV1beta1(in.Spec.DefaultBackend),
+		},
+		Status: v1beta1.IngressStatus{LoadBalancer: in.Status.LoadBalancer},
+	}
+	for _, tls := range in.Spec.TLS {
+		out.Spec.TLS = append(out.Spec.TLS, v1beta1.IngressTLS{Hosts: tls.Hosts, SecretName: tls.SecretName})
+	}
+	for _, rule := range in.Spec.Rules {
+		r := v1beta1.IngressRule{Host: rule.Host}
+		if rule.HTTP != nil {
+			r.HTTP = &v1beta1.HTTPIngressRuleValue{}
+			for _, path := range rule.HTTP.Paths {
+				r.HTTP.Paths = append(r.HTTP.Paths, v1beta1.HTTPIngressPath{
+					Path:     path.Path,
+					PathType: (*v1beta1.PathType)(path.PathType),
+					Backend:  *backendToV1beta1(&path.Backend),
+				})
+			}
+		}
+		out.Spec.Rules = append(out.Spec.Rules, r)
+	}
+	return out
+}
+
+func backendToV1beta1(in *v1.IngressBackend) *v1beta1.IngressBackend {
+	if in == nil {
+		return nil
+	}
+	out := &v1beta1.IngressBackend{Resource: in.Resource}
+	if in.Service != nil {
+		out.ServiceName = in.Service.Name
+		if in.Service.Port.Name != "" {
+			out.ServicePort = intstr.FromString(in.Service.Port.Name)
+		} else {
+			out.ServicePort = intstr.FromInt(int(in.Service.Port.Number))
+		}
+	}
+	return out
+}
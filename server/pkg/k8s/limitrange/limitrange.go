@@ -0,0 +1,131 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limitrange
+
+import (
+	"context"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+// LimitRangeList contains a list of Limit Ranges in the cluster.
+type LimitRangeList struct {
+	ListMeta k8s.ListMeta `json:"listMeta"`
+
+	// Unordered list of Limit Ranges
+	Items []LimitRangeDetail `json:"items"`
+}
+
+// LimitRangeDetail LimitRange详情, Limits为每类资源(Container、Pod、PVC)的默认值和上下限
+type LimitRangeDetail struct {
+	ObjectMeta k8s.ObjectMeta `json:"objectMeta"`
+	TypeMeta   k8s.TypeMeta   `json:"typeMeta"`
+
+	Limits []v1.LimitRangeItem `json:"limits"`
+}
+
+// ToLimitRangeDetail 转换LimitRange
+func ToLimitRangeDetail(rawLimitRange *v1.LimitRange) *LimitRangeDetail {
+	return &LimitRangeDetail{
+		ObjectMeta: k8s.NewObjectMeta(rawLimitRange.ObjectMeta),
+		TypeMeta:   k8s.NewTypeMeta(k8s.ResourceKindLimitRange),
+		Limits:     rawLimitRange.Spec.Limits,
+	}
+}
+
+// GetLimitRangeList returns a list of all Limit Ranges in the cluster.
+func GetLimitRangeList(client kubernetes.Interface, nsQuery *k8scommon.NamespaceQuery, dsQuery *dataselect.DataSelectQuery) (*LimitRangeList, error) {
+	common.LOG.Info(fmt.Sprintf("Getting list limit ranges in the namespace %s", nsQuery.ToRequestParam()))
+	list, err := client.CoreV1().LimitRanges(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredItems []v1.LimitRange
+	for _, item := range list.Items {
+		if nsQuery.Matches(item.ObjectMeta.Namespace) {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+	return toLimitRangeList(filteredItems, dsQuery), nil
+}
+
+func toLimitRangeList(limitRanges []v1.LimitRange, dsQuery *dataselect.DataSelectQuery) *LimitRangeList {
+	result := &LimitRangeList{
+		Items:    make([]LimitRangeDetail, 0),
+		ListMeta: k8s.ListMeta{TotalItems: len(limitRanges)},
+	}
+
+	limitRangeCells, filteredTotal := dataselect.GenericDataSelectWithFilter(toCells(limitRanges), dsQuery)
+	limitRanges = fromCells(limitRangeCells)
+	result.ListMeta = k8s.ListMeta{TotalItems: filteredTotal}
+
+	for i := range limitRanges {
+		result.Items = append(result.Items, *ToLimitRangeDetail(&limitRanges[i]))
+	}
+	return result
+}
+
+// GetLimitRangeDetail returns detailed information about a limit range
+func GetLimitRangeDetail(client kubernetes.Interface, namespace, name string) (*LimitRangeDetail, error) {
+	rawLimitRange, err := client.CoreV1().LimitRanges(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ToLimitRangeDetail(rawLimitRange), nil
+}
+
+// CreateLimitRange 创建LimitRange
+func CreateLimitRange(client kubernetes.Interface, data k8s.LimitRangeData) (*LimitRangeDetail, error) {
+	common.LOG.Info(fmt.Sprintf("创建LimitRange: %v, namespace: %v", data.Name, data.Namespace))
+	limitRange := &v1.LimitRange{
+		ObjectMeta: metaV1.ObjectMeta{Name: data.Name, Namespace: data.Namespace},
+		Spec:       data.Spec,
+	}
+	created, err := client.CoreV1().LimitRanges(data.Namespace).Create(context.TODO(), limitRange, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ToLimitRangeDetail(created), nil
+}
+
+// UpdateLimitRange 更新LimitRange的spec
+func UpdateLimitRange(client kubernetes.Interface, data k8s.LimitRangeData) (*LimitRangeDetail, error) {
+	common.LOG.Info(fmt.Sprintf("更新LimitRange: %v, namespace: %v", data.Name, data.Namespace))
+	limitRange, err := client.CoreV1().LimitRanges(data.Namespace).Get(context.TODO(), data.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	limitRange.Spec = data.Spec
+	updated, err := client.CoreV1().LimitRanges(data.Namespace).Update(context.TODO(), limitRange, metaV1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ToLimitRangeDetail(updated), nil
+}
+
+// DeleteLimitRange 删除LimitRange
+func DeleteLimitRange(client kubernetes.Interface, namespace, name string) error {
+	common.LOG.Info(fmt.Sprintf("请求删除LimitRange: %v, namespace: %v", name, namespace))
+	return client.CoreV1().LimitRanges(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+}
@@ -0,0 +1,211 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// maxStreamSources 同时跟踪的Pod容器数量上限
+	maxStreamSources = 50
+	// streamFlushInterval 合并多个日志流的时间窗口, 窗口内的日志按时间戳排序后发送
+	streamFlushInterval = 500 * time.Millisecond
+	// maxStreamLineSize 单行日志的最大长度
+	maxStreamLineSize = 1024 * 1024
+)
+
+// StreamLine 实时日志中的一行, 标记所属的Pod和容器
+type StreamLine struct {
+	Pod       string       `json:"pod"`
+	Container string       `json:"container"`
+	Timestamp LogTimestamp `json:"timestamp"`
+	Content   string       `json:"content"`
+	// Error 日志流打开或读取失败的原因
+	Error string `json:"error,omitempty"`
+
+	time time.Time
+}
+
+// StreamOptions 实时日志参数
+type StreamOptions struct {
+	// Container 为空时跟踪所有容器
+	Container    string
+	SinceSeconds *int64
+	TailLines    *int64
+	// Filter 服务端过滤, 只发送匹配的行
+	Filter *regexp.Regexp
+}
+
+type streamSource struct {
+	pod       string
+	container string
+}
+
+// StreamLogs 跟踪Pod或控制器下所有Pod容器的日志(Follow), 合并后按时间戳排序分批交给send,
+// ctx取消或send返回错误时关闭所有日志流. 开始跟踪后新建的Pod不会被跟踪
+func StreamLogs(ctx context.Context, client kubernetes.Interface, ns, resourceName, resourceType string,
+	opts StreamOptions, send func([]StreamLine) error) error {
+	sources, err := GetLogSources(client, ns, resourceName, resourceType)
+	if err != nil {
+		return err
+	}
+	var streams []streamSource
+	for _, pod := range sources.PodNames {
+		for _, container := range sources.ContainerNames {
+			if opts.Container == "" || opts.Container == container {
+				streams = append(streams, streamSource{pod: pod, container: container})
+			}
+		}
+	}
+	if len(streams) == 0 {
+		return fmt.Errorf("%s %s/%s下没有可跟踪的容器", resourceType, ns, resourceName)
+	}
+	if len(streams) > maxStreamSources {
+		return fmt.Errorf("日志流数量%d超过上限%d, 请指定容器", len(streams), maxStreamSources)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lines := make(chan StreamLine, 1000)
+	var wg sync.WaitGroup
+	for _, s := range streams {
+		wg.Add(1)
+		go func(s streamSource) {
+			defer wg.Done()
+			followLog(ctx, client, ns, s, opts, lines)
+		}(s)
+	}
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	ticker := time.NewTicker(streamFlushInterval)
+	defer ticker.Stop()
+	var buffer []StreamLine
+	flush := func() error {
+		if len(buffer) == 0 {
+			return nil
+		}
+		sortStreamLines(buffer)
+		err := send(buffer)
+		buffer = nil
+		return err
+	}
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				// 所有日志流都已结束(容器退出或ctx取消)
+				if ctx.Err() != nil {
+					return nil
+				}
+				return flush()
+			}
+			buffer = append(buffer, line)
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// followLog 跟踪单个容器的日志, 直到日志流结束或ctx取消
+func followLog(ctx context.Context, client kubernetes.Interface, ns string, s streamSource, opts StreamOptions, lines chan<- StreamLine) {
+	emit := func(line StreamLine) bool {
+		select {
+		case lines <- line:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	emitError := func(err error) {
+		now := time.Now()
+		emit(StreamLine{Pod: s.pod, Container: s.container, Timestamp: LogTimestamp(now.Format(time.RFC3339Nano)),
+			Error: err.Error(), time: now})
+	}
+
+	logOptions := &v1.PodLogOptions{
+		Container:    s.container,
+		Follow:       true,
+		Timestamps:   true,
+		SinceSeconds: opts.SinceSeconds,
+		TailLines:    opts.TailLines,
+	}
+	stream, err := client.CoreV1().Pods(ns).GetLogs(s.pod, logOptions).Stream(ctx)
+	if err != nil {
+		emitError(err)
+		return
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+	for scanner.Scan() {
+		line := parseStreamLine(s.pod, s.container, scanner.Text())
+		if opts.Filter != nil && !opts.Filter.MatchString(line.Content) {
+			continue
+		}
+		if !emit(line) {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		emitError(err)
+	}
+}
+
+// parseStreamLine 拆分日志行开头的时间戳, 没有时间戳的行使用接收时间
+func parseStreamLine(pod, container, raw string) StreamLine {
+	line := StreamLine{Pod: pod, Container: container, Content: raw}
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != ' ' {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339Nano, raw[:i]); err == nil {
+			line.time = t
+			line.Timestamp = LogTimestamp(raw[:i])
+			line.Content = raw[i+1:]
+			return line
+		}
+		break
+	}
+	line.time = time.Now()
+	line.Timestamp = LogTimestamp(line.time.Format(time.RFC3339Nano))
+	return line
+}
+
+// sortStreamLines 按时间戳排序, 时间相同时保持接收顺序
+func sortStreamLines(lines []StreamLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].time.Before(lines[j].time)
+	})
+}
@@ -0,0 +1,89 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"regexp"
+	"testing"
+)
+
+func TestParseAndSortStreamLines(t *testing.T) {
+	lines := []StreamLine{
+		parseStreamLine("web-1", "app", "2021-11-02T10:00:05.12Z second"),
+		parseStreamLine("web-0", "app", "2021-11-02T10:00:05.1Z first"),
+		parseStreamLine("web-0", "app", "2021-11-02T10:00:06Z third"),
+	}
+	sortStreamLines(lines)
+
+	expected := []string{"first", "second", "third"}
+	for i, line := range lines {
+		if line.Content != expected[i] {
+			t.Fatalf("line %d: expected %q, got %q", i, expected[i], line.Content)
+		}
+	}
+	if lines[0].Pod != "web-0" || lines[0].Timestamp != "2021-11-02T10:00:05.1Z" {
+		t.Fatalf("unexpected first line %+v", lines[0])
+	}
+
+	raw := parseStreamLine("web-0", "app", "no timestamp here")
+	if raw.Content != "no timestamp here" || raw.Timestamp == "" {
+		t.Fatalf("unexpected line without timestamp %+v", raw)
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metaV1.ObjectMeta{Name: "web-0", Namespace: "default"},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "app"}, {Name: "sidecar"},
+		}},
+	}
+	client := fake.NewSimpleClientset(pod)
+
+	var got []StreamLine
+	err := StreamLogs(context.Background(), client, "default", "web-0", "pod",
+		StreamOptions{Container: "sidecar"}, func(lines []StreamLine) error {
+			got = append(got, lines...)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// fake客户端的日志内容固定为"fake logs"
+	if len(got) != 1 || got[0].Pod != "web-0" || got[0].Container != "sidecar" || got[0].Content != "fake logs" {
+		t.Fatalf("unexpected lines %+v", got)
+	}
+
+	got = nil
+	err = StreamLogs(context.Background(), client, "default", "web-0", "pod",
+		StreamOptions{Filter: regexp.MustCompile("error")}, func(lines []StreamLine) error {
+			got = append(got, lines...)
+			return nil
+		})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected all lines filtered, got %+v, err %v", got, err)
+	}
+
+	if err := StreamLogs(context.Background(), client, "default", "web-0", "pod",
+		StreamOptions{Container: "missing"}, func([]StreamLine) error { return nil }); err == nil {
+		t.Fatal("expected error for unknown container")
+	}
+}
@@ -18,11 +18,49 @@ package namespace
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	"kubespace/server/pkg/k8s/dataselect"
+	"sync"
+	"time"
 )
 
+// deleteTokenTTL 删除命名空间确认Token的有效期
+const deleteTokenTTL = 5 * time.Minute
+
+// protectedNamespaces 系统命名空间, 不允许删除
+var protectedNamespaces = map[string]bool{
+	"default":         true,
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+// NamespaceList contains a list of namespaces in the cluster.
+type NamespaceList struct {
+	ListMeta k8s.ListMeta `json:"listMeta"`
+
+	// Unordered list of Namespaces.
+	Namespaces []Namespace `json:"namespaces"`
+}
+
+// Namespace is a presentation layer view of Kubernetes namespaces. This means it is namespace plus
+// additional augmented data we can get from other sources.
+type Namespace struct {
+	ObjectMeta k8s.ObjectMeta `json:"objectMeta"`
+	TypeMeta   k8s.TypeMeta   `json:"typeMeta"`
+
+	// Phase is the current lifecycle phase of the namespace.
+	Phase v1.NamespacePhase `json:"phase"`
+}
+
 func GetNamespaceList(client *kubernetes.Clientset) (*v1.NamespaceList, error) {
 
 	namespace, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
@@ -31,3 +69,130 @@ func GetNamespaceList(client *kubernetes.Clientset) (*v1.NamespaceList, error) {
 	}
 	return namespace, nil
 }
+
+// GetNamespaceListWithSelect 按dataselect条件分页查询命名空间
+func GetNamespaceListWithSelect(client kubernetes.Interface, dsQuery *dataselect.DataSelectQuery) (*NamespaceList, error) {
+	namespaces, err := client.CoreV1().Namespaces().List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+	return toNamespaceList(namespaces.Items, dsQuery), nil
+}
+
+func toNamespace(namespace v1.Namespace) Namespace {
+	return Namespace{
+		ObjectMeta: k8s.NewObjectMeta(namespace.ObjectMeta),
+		TypeMeta:   k8s.NewTypeMeta(k8s.ResourceKindNamespace),
+		Phase:      namespace.Status.Phase,
+	}
+}
+
+func toNamespaceList(namespaces []v1.Namespace, dsQuery *dataselect.DataSelectQuery) *NamespaceList {
+	namespaceList := &NamespaceList{
+		Namespaces: make([]Namespace, 0),
+		ListMeta:   k8s.ListMeta{TotalItems: len(namespaces)},
+	}
+
+	namespaceCells, filteredTotal := dataselect.GenericDataSelectWithFilter(toCells(namespaces), dsQuery)
+	namespaces = fromCells(namespaceCells)
+	namespaceList.ListMeta = k8s.ListMeta{TotalItems: filteredTotal}
+
+	for _, namespace := range namespaces {
+		namespaceList.Namespaces = append(namespaceList.Namespaces, toNamespace(namespace))
+	}
+	return namespaceList
+}
+
+// CreateNamespace 创建命名空间
+func CreateNamespace(client kubernetes.Interface, data k8s.NamespaceData) (*Namespace, error) {
+	common.LOG.Info(fmt.Sprintf("创建命名空间: %v", data.Name))
+	namespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        data.Name,
+			Labels:      data.Labels,
+			Annotations: data.Annotations,
+		},
+	}
+	created, err := client.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toNamespace(*created)
+	return &result, nil
+}
+
+// UpdateNamespaceMeta 修改命名空间的标签和注解, 传入的标签、注解会替换原有的值
+func UpdateNamespaceMeta(client kubernetes.Interface, data k8s.NamespaceData) (*Namespace, error) {
+	common.LOG.Info(fmt.Sprintf("修改命名空间: %v 的标签和注解", data.Name))
+	namespace, err := client.CoreV1().Namespaces().Get(context.TODO(), data.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	namespace.Labels = data.Labels
+	namespace.Annotations = data.Annotations
+	updated, err := client.CoreV1().Namespaces().Update(context.TODO(), namespace, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toNamespace(*updated)
+	return &result, nil
+}
+
+type deleteToken struct {
+	token    string
+	expireAt time.Time
+}
+
+type deleteTokenMap struct {
+	sync.Mutex
+	tokens map[string]deleteToken
+}
+
+// deleteTokens 删除命名空间的确认Token, key为 集群ID/命名空间
+var deleteTokens = &deleteTokenMap{tokens: make(map[string]deleteToken)}
+
+// IssueDeleteToken 生成删除命名空间的确认Token, 有效期内只能使用一次
+func IssueDeleteToken(key, name string) (string, error) {
+	if protectedNamespaces[name] {
+		return "", fmt.Errorf("系统命名空间%s不允许删除", name)
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	deleteTokens.Lock()
+	defer deleteTokens.Unlock()
+	now := time.Now()
+	for k, t := range deleteTokens.tokens {
+		if now.After(t.expireAt) {
+			delete(deleteTokens.tokens, k)
+		}
+	}
+	deleteTokens.tokens[key] = deleteToken{token: token, expireAt: now.Add(deleteTokenTTL)}
+	return token, nil
+}
+
+func consumeDeleteToken(key, token string) bool {
+	deleteTokens.Lock()
+	defer deleteTokens.Unlock()
+	t, ok := deleteTokens.tokens[key]
+	if !ok || t.token != token || time.Now().After(t.expireAt) {
+		return false
+	}
+	delete(deleteTokens.tokens, key)
+	return true
+}
+
+// DeleteNamespace 校验确认Token后删除命名空间, 命名空间下的所有资源都会被删除
+func DeleteNamespace(client kubernetes.Interface, key string, data k8s.NamespaceDelete) error {
+	if protectedNamespaces[data.Name] {
+		return fmt.Errorf("系统命名空间%s不允许删除", data.Name)
+	}
+	if !consumeDeleteToken(key, data.Token) {
+		return errors.New("确认Token无效或已过期, 请重新获取")
+	}
+	common.LOG.Info(fmt.Sprintf("请求删除命名空间: %v", data.Name))
+	return client.CoreV1().Namespaces().Delete(context.TODO(), data.Name, metav1.DeleteOptions{})
+}
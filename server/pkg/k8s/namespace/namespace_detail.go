@@ -0,0 +1,68 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/models/k8s"
+	"kubespace/server/pkg/k8s/limitrange"
+	"kubespace/server/pkg/k8s/resourcequota"
+)
+
+// NamespaceDetail 命名空间详情, 包含配额及使用量、LimitRange
+type NamespaceDetail struct {
+	// Extends list item structure.
+	Namespace `json:",inline"`
+
+	// ResourceQuotaList 命名空间下的配额, statusList中为上限和已使用量
+	ResourceQuotaList []resourcequota.ResourceQuotaDetail `json:"resourceQuotaList"`
+
+	// LimitRangeList 命名空间下的LimitRange
+	LimitRangeList []limitrange.LimitRangeDetail `json:"limitRangeList"`
+}
+
+// GetNamespaceDetail gets namespace details.
+func GetNamespaceDetail(client kubernetes.Interface, name string) (*NamespaceDetail, error) {
+	namespace, err := client.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := client.CoreV1().ResourceQuotas(name).List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+	limitRanges, err := client.CoreV1().LimitRanges(name).List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &NamespaceDetail{
+		Namespace:         toNamespace(*namespace),
+		ResourceQuotaList: make([]resourcequota.ResourceQuotaDetail, 0, len(quotas.Items)),
+		LimitRangeList:    make([]limitrange.LimitRangeDetail, 0, len(limitRanges.Items)),
+	}
+	for i := range quotas.Items {
+		detail.ResourceQuotaList = append(detail.ResourceQuotaList, *resourcequota.ToResourceQuotaDetail(&quotas.Items[i]))
+	}
+	for i := range limitRanges.Items {
+		detail.LimitRangeList = append(detail.LimitRangeList, *limitrange.ToLimitRangeDetail(&limitRanges.Items[i]))
+	}
+	return detail, nil
+}
@@ -0,0 +1,62 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+
+	"go.uber.org/zap"
+)
+
+func TestDeleteNamespaceToken(t *testing.T) {
+	common.LOG = zap.NewNop()
+	client := fake.NewSimpleClientset(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo"}})
+
+	if err := DeleteNamespace(client, "1/demo", k8s.NamespaceDelete{Name: "demo", Token: "bad"}); err == nil {
+		t.Fatal("expected error for invalid token")
+	}
+
+	token, err := IssueDeleteToken("1/demo", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteNamespace(client, "2/demo", k8s.NamespaceDelete{Name: "demo", Token: token}); err == nil {
+		t.Fatal("expected error for token issued in another cluster")
+	}
+	if err := DeleteNamespace(client, "1/demo", k8s.NamespaceDelete{Name: "demo", Token: token}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Namespaces().Get(context.TODO(), "demo", metav1.GetOptions{}); err == nil {
+		t.Fatal("namespace should be deleted")
+	}
+	if err := DeleteNamespace(client, "1/demo", k8s.NamespaceDelete{Name: "demo", Token: token}); err == nil {
+		t.Fatal("token should be single use")
+	}
+}
+
+func TestProtectedNamespace(t *testing.T) {
+	if _, err := IssueDeleteToken("1/kube-system", "kube-system"); err == nil {
+		t.Fatal("expected error for protected namespace")
+	}
+}
@@ -191,11 +191,7 @@ func CordonNode(client *kubernetes.Clientset, nodeName string) (bool, error) {
 		选择排空节点（同时设置为不可调度），在后续进行应用部署时，则Pod不会再调度到该节点，并且该节点上由DaemonSet控制的Pod不会被排空。
 		kubectl drain cn-beijing.i-2ze19qyi8votgjz12345 --grace-period=120 --ignore-daemonsets=true
 	*/
-	_, err := NodeUnschdulable(client, nodeName, true)
-	if err != nil {
-		return false, err
-	}
-	err = evict.EvictsNodePods(client, nodeName)
+	err := evict.DrainNode(client, k8s.NodeDrain{NodeName: nodeName})
 	if err != nil {
 		common.LOG.Error(fmt.Sprintf("排空节点出现异常: %v", err.Error()))
 		return false, err
@@ -207,11 +203,8 @@ func CordonNode(client *kubernetes.Clientset, nodeName string) (bool, error) {
 func RemoveNode(client *kubernetes.Clientset, nodeName string) (bool, error) {
 	startTime := time.Now()
 	common.LOG.Info(fmt.Sprintf("移除Node节点:%v, 异步任务已开始", nodeName))
-	_, err := NodeUnschdulable(client, nodeName, true)
-	if err != nil {
-		return false, err
-	}
-	err = evict.EvictsNodePods(client, nodeName)
+	// 排空失败时不移除节点, 避免节点上的Pod被强制删除
+	err := evict.DrainNode(client, k8s.NodeDrain{NodeName: nodeName})
 	if err != nil {
 		common.LOG.Error(fmt.Sprintf("排空节点出现异常: %v", err.Error()))
 		return false, err
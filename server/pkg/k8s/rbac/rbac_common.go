@@ -0,0 +1,87 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+// The code below allows to perform complex data section on []Role and []RoleBinding
+
+type RoleCell Role
+
+func (self RoleCell) GetProperty(name dataselect.PropertyName) dataselect.ComparableValue {
+	switch name {
+	case dataselect.NameProperty:
+		return dataselect.StdComparableString(self.ObjectMeta.Name)
+	case dataselect.CreationTimestampProperty:
+		return dataselect.StdComparableTime(self.ObjectMeta.CreationTimestamp.Time)
+	case dataselect.NamespaceProperty:
+		return dataselect.StdComparableString(self.ObjectMeta.Namespace)
+	default:
+		// if name is not supported then just return a constant dummy value, sort will have no effect.
+		return nil
+	}
+}
+
+func toRoleCells(std []Role) []dataselect.DataCell {
+	cells := make([]dataselect.DataCell, len(std))
+	for i := range std {
+		cells[i] = RoleCell(std[i])
+	}
+	return cells
+}
+
+func fromRoleCells(cells []dataselect.DataCell) []Role {
+	std := make([]Role, len(cells))
+	for i := range std {
+		std[i] = Role(cells[i].(RoleCell))
+	}
+	return std
+}
+
+type RoleBindingCell RoleBinding
+
+func (self RoleBindingCell) GetProperty(name dataselect.PropertyName) dataselect.ComparableValue {
+	switch name {
+	case dataselect.NameProperty:
+		return dataselect.StdComparableString(self.ObjectMeta.Name)
+	case dataselect.CreationTimestampProperty:
+		return dataselect.StdComparableTime(self.ObjectMeta.CreationTimestamp.Time)
+	case dataselect.NamespaceProperty:
+		return dataselect.StdComparableString(self.ObjectMeta.Namespace)
+	default:
+		// if name is not supported then just return a constant dummy value, sort will have no effect.
+		return nil
+	}
+}
+
+func toRoleBindingCells(std []RoleBinding) []dataselect.DataCell {
+	cells := make([]dataselect.DataCell, len(std))
+	for i := range std {
+		cells[i] = RoleBindingCell(std[i])
+	}
+	return cells
+}
+
+func fromRoleBindingCells(cells []dataselect.DataCell) []RoleBinding {
+	std := make([]RoleBinding, len(cells))
+	for i := range std {
+		std[i] = RoleBinding(cells[i].(RoleBindingCell))
+	}
+	return std
+}
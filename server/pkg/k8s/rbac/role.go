@@ -0,0 +1,203 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	rbac "k8s.io/api/rbac/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+// RoleList contains a list of Roles or ClusterRoles in the cluster.
+type RoleList struct {
+	ListMeta k8s.ListMeta `json:"listMeta"`
+
+	// Unordered list of Roles.
+	Items []Role `json:"items"`
+}
+
+// Role Role和ClusterRole的列表展示结构, ClusterRole的namespace为空
+type Role struct {
+	ObjectMeta k8s.ObjectMeta `json:"objectMeta"`
+	TypeMeta   k8s.TypeMeta   `json:"typeMeta"`
+}
+
+// RoleDetail contains Role or ClusterRole details.
+type RoleDetail struct {
+	// Extends list item structure.
+	Role `json:",inline"`
+
+	Rules []rbac.PolicyRule `json:"rules"`
+
+	// AggregationRule 仅ClusterRole有, 聚合其他ClusterRole的规则
+	AggregationRule *rbac.AggregationRule `json:"aggregationRule,omitempty"`
+}
+
+func toRole(meta metaV1.ObjectMeta, kind k8s.ResourceKind) Role {
+	return Role{
+		ObjectMeta: k8s.NewObjectMeta(meta),
+		TypeMeta:   k8s.NewTypeMeta(kind),
+	}
+}
+
+func toRoleList(roles []Role, dsQuery *dataselect.DataSelectQuery) *RoleList {
+	roleCells, filteredTotal := dataselect.GenericDataSelectWithFilter(toRoleCells(roles), dsQuery)
+	return &RoleList{
+		ListMeta: k8s.ListMeta{TotalItems: filteredTotal},
+		Items:    fromRoleCells(roleCells),
+	}
+}
+
+// GetRoleList returns a list of all Roles in the namespaces.
+func GetRoleList(client kubernetes.Interface, nsQuery *k8scommon.NamespaceQuery, dsQuery *dataselect.DataSelectQuery) (*RoleList, error) {
+	common.LOG.Info(fmt.Sprintf("Getting list of roles in the namespace %s", nsQuery.ToRequestParam()))
+	list, err := client.RbacV1().Roles(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]Role, 0, len(list.Items))
+	for _, item := range list.Items {
+		if nsQuery.Matches(item.ObjectMeta.Namespace) {
+			roles = append(roles, toRole(item.ObjectMeta, k8s.ResourceKindRole))
+		}
+	}
+	return toRoleList(roles, dsQuery), nil
+}
+
+// GetRoleDetail returns detailed information about a role
+func GetRoleDetail(client kubernetes.Interface, namespace, name string) (*RoleDetail, error) {
+	role, err := client.RbacV1().Roles(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &RoleDetail{
+		Role:  toRole(role.ObjectMeta, k8s.ResourceKindRole),
+		Rules: role.Rules,
+	}, nil
+}
+
+// DeleteRole 删除Role
+func DeleteRole(client kubernetes.Interface, namespace, name string) error {
+	common.LOG.Info(fmt.Sprintf("请求删除Role: %v, namespace: %v", name, namespace))
+	return client.RbacV1().Roles(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+}
+
+// CreateRole 创建Role
+func CreateRole(client kubernetes.Interface, data k8s.RoleData) (*RoleDetail, error) {
+	common.LOG.Info(fmt.Sprintf("创建Role: %v, namespace: %v", data.Name, data.Namespace))
+	if data.Namespace == "" {
+		return nil, errors.New("命名空间不能为空")
+	}
+	role := &rbac.Role{
+		ObjectMeta: metaV1.ObjectMeta{Name: data.Name, Namespace: data.Namespace},
+		Rules:      data.Rules,
+	}
+	created, err := client.RbacV1().Roles(data.Namespace).Create(context.TODO(), role, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &RoleDetail{Role: toRole(created.ObjectMeta, k8s.ResourceKindRole), Rules: created.Rules}, nil
+}
+
+// UpdateRole 更新Role的规则
+func UpdateRole(client kubernetes.Interface, data k8s.RoleData) (*RoleDetail, error) {
+	common.LOG.Info(fmt.Sprintf("更新Role: %v, namespace: %v", data.Name, data.Namespace))
+	role, err := client.RbacV1().Roles(data.Namespace).Get(context.TODO(), data.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	role.Rules = data.Rules
+	updated, err := client.RbacV1().Roles(data.Namespace).Update(context.TODO(), role, metaV1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &RoleDetail{Role: toRole(updated.ObjectMeta, k8s.ResourceKindRole), Rules: updated.Rules}, nil
+}
+
+// GetClusterRoleList returns a list of all ClusterRoles in the cluster.
+func GetClusterRoleList(client kubernetes.Interface, dsQuery *dataselect.DataSelectQuery) (*RoleList, error) {
+	common.LOG.Info("Getting list of all cluster roles in the cluster")
+	list, err := client.RbacV1().ClusterRoles().List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]Role, 0, len(list.Items))
+	for _, item := range list.Items {
+		roles = append(roles, toRole(item.ObjectMeta, k8s.ResourceKindClusterRole))
+	}
+	return toRoleList(roles, dsQuery), nil
+}
+
+// GetClusterRoleDetail returns detailed information about a cluster role
+func GetClusterRoleDetail(client kubernetes.Interface, name string) (*RoleDetail, error) {
+	role, err := client.RbacV1().ClusterRoles().Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &RoleDetail{
+		Role:            toRole(role.ObjectMeta, k8s.ResourceKindClusterRole),
+		Rules:           role.Rules,
+		AggregationRule: role.AggregationRule,
+	}, nil
+}
+
+// CreateClusterRole 创建ClusterRole
+func CreateClusterRole(client kubernetes.Interface, data k8s.RoleData) (*RoleDetail, error) {
+	common.LOG.Info(fmt.Sprintf("创建ClusterRole: %v", data.Name))
+	role := &rbac.ClusterRole{
+		ObjectMeta: metaV1.ObjectMeta{Name: data.Name},
+		Rules:      data.Rules,
+	}
+	created, err := client.RbacV1().ClusterRoles().Create(context.TODO(), role, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &RoleDetail{Role: toRole(created.ObjectMeta, k8s.ResourceKindClusterRole), Rules: created.Rules}, nil
+}
+
+// UpdateClusterRole 更新ClusterRole的规则, 聚合的ClusterRole的规则由控制器维护, 不允许修改
+func UpdateClusterRole(client kubernetes.Interface, data k8s.RoleData) (*RoleDetail, error) {
+	common.LOG.Info(fmt.Sprintf("更新ClusterRole: %v", data.Name))
+	role, err := client.RbacV1().ClusterRoles().Get(context.TODO(), data.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if role.AggregationRule != nil {
+		return nil, errors.New("聚合的ClusterRole规则由控制器维护, 不能直接修改")
+	}
+	role.Rules = data.Rules
+	updated, err := client.RbacV1().ClusterRoles().Update(context.TODO(), role, metaV1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &RoleDetail{Role: toRole(updated.ObjectMeta, k8s.ResourceKindClusterRole), Rules: updated.Rules}, nil
+}
+
+// DeleteClusterRole 删除ClusterRole
+func DeleteClusterRole(client kubernetes.Interface, name string) error {
+	common.LOG.Info(fmt.Sprintf("请求删除ClusterRole: %v", name))
+	return client.RbacV1().ClusterRoles().Delete(context.TODO(), name, metaV1.DeleteOptions{})
+}
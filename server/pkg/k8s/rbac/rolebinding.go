@@ -0,0 +1,238 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	rbac "k8s.io/api/rbac/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+// RoleBindingList contains a list of RoleBindings or ClusterRoleBindings in the cluster.
+type RoleBindingList struct {
+	ListMeta k8s.ListMeta `json:"listMeta"`
+
+	// Unordered list of RoleBindings.
+	Items []RoleBinding `json:"items"`
+}
+
+// RoleBinding RoleBinding和ClusterRoleBinding的展示结构, ClusterRoleBinding的namespace为空
+type RoleBinding struct {
+	ObjectMeta k8s.ObjectMeta `json:"objectMeta"`
+	TypeMeta   k8s.TypeMeta   `json:"typeMeta"`
+
+	RoleRef  rbac.RoleRef   `json:"roleRef"`
+	Subjects []rbac.Subject `json:"subjects"`
+}
+
+func toRoleBinding(meta metaV1.ObjectMeta, kind k8s.ResourceKind, roleRef rbac.RoleRef, subjects []rbac.Subject) RoleBinding {
+	return RoleBinding{
+		ObjectMeta: k8s.NewObjectMeta(meta),
+		TypeMeta:   k8s.NewTypeMeta(kind),
+		RoleRef:    roleRef,
+		Subjects:   subjects,
+	}
+}
+
+func toRoleBindingList(bindings []RoleBinding, dsQuery *dataselect.DataSelectQuery) *RoleBindingList {
+	bindingCells, filteredTotal := dataselect.GenericDataSelectWithFilter(toRoleBindingCells(bindings), dsQuery)
+	return &RoleBindingList{
+		ListMeta: k8s.ListMeta{TotalItems: filteredTotal},
+		Items:    fromRoleBindingCells(bindingCells),
+	}
+}
+
+// GetRoleBindingList returns a list of all RoleBindings in the namespaces.
+func GetRoleBindingList(client kubernetes.Interface, nsQuery *k8scommon.NamespaceQuery, dsQuery *dataselect.DataSelectQuery) (*RoleBindingList, error) {
+	common.LOG.Info(fmt.Sprintf("Getting list of role bindings in the namespace %s", nsQuery.ToRequestParam()))
+	list, err := client.RbacV1().RoleBindings(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := make([]RoleBinding, 0, len(list.Items))
+	for _, item := range list.Items {
+		if nsQuery.Matches(item.ObjectMeta.Namespace) {
+			bindings = append(bindings, toRoleBinding(item.ObjectMeta, k8s.ResourceKindRoleBinding, item.RoleRef, item.Subjects))
+		}
+	}
+	return toRoleBindingList(bindings, dsQuery), nil
+}
+
+// GetRoleBindingDetail returns detailed information about a role binding
+func GetRoleBindingDetail(client kubernetes.Interface, namespace, name string) (*RoleBinding, error) {
+	binding, err := client.RbacV1().RoleBindings(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toRoleBinding(binding.ObjectMeta, k8s.ResourceKindRoleBinding, binding.RoleRef, binding.Subjects)
+	return &result, nil
+}
+
+// DeleteRoleBinding 删除RoleBinding
+func DeleteRoleBinding(client kubernetes.Interface, namespace, name string) error {
+	common.LOG.Info(fmt.Sprintf("请求删除RoleBinding: %v, namespace: %v", name, namespace))
+	return client.RbacV1().RoleBindings(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+}
+
+// toRoleRef 补全绑定引用的角色, RoleBinding可以引用Role或ClusterRole, ClusterRoleBinding只能引用ClusterRole
+func toRoleRef(ref rbac.RoleRef, clusterBinding bool) (rbac.RoleRef, error) {
+	if ref.Name == "" {
+		return ref, errors.New("绑定的角色不能为空")
+	}
+	if ref.Kind == "" {
+		ref.Kind = "ClusterRole"
+	}
+	if ref.Kind != "ClusterRole" && (clusterBinding || ref.Kind != "Role") {
+		return ref, fmt.Errorf("不支持绑定%s类型的角色", ref.Kind)
+	}
+	ref.APIGroup = rbac.GroupName
+	return ref, nil
+}
+
+// checkRoleRef 绑定创建后RoleRef不能修改, 未填写时沿用原值
+func checkRoleRef(current, ref rbac.RoleRef) error {
+	if ref.Name == "" {
+		return nil
+	}
+	if ref.Kind == "" {
+		ref.Kind = "ClusterRole"
+	}
+	if ref.Kind != current.Kind || ref.Name != current.Name {
+		return errors.New("绑定的角色不能修改, 请删除后重新创建")
+	}
+	return nil
+}
+
+// CreateRoleBinding 创建RoleBinding
+func CreateRoleBinding(client kubernetes.Interface, data k8s.RoleBindingData) (*RoleBinding, error) {
+	common.LOG.Info(fmt.Sprintf("创建RoleBinding: %v, namespace: %v", data.Name, data.Namespace))
+	if data.Namespace == "" {
+		return nil, errors.New("命名空间不能为空")
+	}
+	roleRef, err := toRoleRef(data.RoleRef, false)
+	if err != nil {
+		return nil, err
+	}
+	binding := &rbac.RoleBinding{
+		ObjectMeta: metaV1.ObjectMeta{Name: data.Name, Namespace: data.Namespace},
+		RoleRef:    roleRef,
+		Subjects:   data.Subjects,
+	}
+	created, err := client.RbacV1().RoleBindings(data.Namespace).Create(context.TODO(), binding, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toRoleBinding(created.ObjectMeta, k8s.ResourceKindRoleBinding, created.RoleRef, created.Subjects)
+	return &result, nil
+}
+
+// UpdateRoleBinding 更新RoleBinding的主体
+func UpdateRoleBinding(client kubernetes.Interface, data k8s.RoleBindingData) (*RoleBinding, error) {
+	common.LOG.Info(fmt.Sprintf("更新RoleBinding: %v, namespace: %v", data.Name, data.Namespace))
+	binding, err := client.RbacV1().RoleBindings(data.Namespace).Get(context.TODO(), data.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoleRef(binding.RoleRef, data.RoleRef); err != nil {
+		return nil, err
+	}
+	binding.Subjects = data.Subjects
+	updated, err := client.RbacV1().RoleBindings(data.Namespace).Update(context.TODO(), binding, metaV1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toRoleBinding(updated.ObjectMeta, k8s.ResourceKindRoleBinding, updated.RoleRef, updated.Subjects)
+	return &result, nil
+}
+
+// GetClusterRoleBindingList returns a list of all ClusterRoleBindings in the cluster.
+func GetClusterRoleBindingList(client kubernetes.Interface, dsQuery *dataselect.DataSelectQuery) (*RoleBindingList, error) {
+	common.LOG.Info("Getting list of all cluster role bindings in the cluster")
+	list, err := client.RbacV1().ClusterRoleBindings().List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := make([]RoleBinding, 0, len(list.Items))
+	for _, item := range list.Items {
+		bindings = append(bindings, toRoleBinding(item.ObjectMeta, k8s.ResourceKindClusterRoleBinding, item.RoleRef, item.Subjects))
+	}
+	return toRoleBindingList(bindings, dsQuery), nil
+}
+
+// GetClusterRoleBindingDetail returns detailed information about a cluster role binding
+func GetClusterRoleBindingDetail(client kubernetes.Interface, name string) (*RoleBinding, error) {
+	binding, err := client.RbacV1().ClusterRoleBindings().Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toRoleBinding(binding.ObjectMeta, k8s.ResourceKindClusterRoleBinding, binding.RoleRef, binding.Subjects)
+	return &result, nil
+}
+
+// CreateClusterRoleBinding 创建ClusterRoleBinding
+func CreateClusterRoleBinding(client kubernetes.Interface, data k8s.RoleBindingData) (*RoleBinding, error) {
+	common.LOG.Info(fmt.Sprintf("创建ClusterRoleBinding: %v", data.Name))
+	roleRef, err := toRoleRef(data.RoleRef, true)
+	if err != nil {
+		return nil, err
+	}
+	binding := &rbac.ClusterRoleBinding{
+		ObjectMeta: metaV1.ObjectMeta{Name: data.Name},
+		RoleRef:    roleRef,
+		Subjects:   data.Subjects,
+	}
+	created, err := client.RbacV1().ClusterRoleBindings().Create(context.TODO(), binding, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toRoleBinding(created.ObjectMeta, k8s.ResourceKindClusterRoleBinding, created.RoleRef, created.Subjects)
+	return &result, nil
+}
+
+// UpdateClusterRoleBinding 更新ClusterRoleBinding的主体
+func UpdateClusterRoleBinding(client kubernetes.Interface, data k8s.RoleBindingData) (*RoleBinding, error) {
+	common.LOG.Info(fmt.Sprintf("更新ClusterRoleBinding: %v", data.Name))
+	binding, err := client.RbacV1().ClusterRoleBindings().Get(context.TODO(), data.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoleRef(binding.RoleRef, data.RoleRef); err != nil {
+		return nil, err
+	}
+	binding.Subjects = data.Subjects
+	updated, err := client.RbacV1().ClusterRoleBindings().Update(context.TODO(), binding, metaV1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toRoleBinding(updated.ObjectMeta, k8s.ResourceKindClusterRoleBinding, updated.RoleRef, updated.Subjects)
+	return &result, nil
+}
+
+// DeleteClusterRoleBinding 删除ClusterRoleBinding
+func DeleteClusterRoleBinding(client kubernetes.Interface, name string) error {
+	common.LOG.Info(fmt.Sprintf("请求删除ClusterRoleBinding: %v", name))
+	return client.RbacV1().ClusterRoleBindings().Delete(context.TODO(), name, metaV1.DeleteOptions{})
+}
@@ -0,0 +1,50 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	rbac "k8s.io/api/rbac/v1"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+)
+
+func TestUpdateRoleBinding(t *testing.T) {
+	common.LOG = zap.NewNop()
+	client := newTestClient()
+	subjects := []rbac.Subject{{Kind: rbac.UserKind, Name: "alice"}}
+
+	if _, err := UpdateRoleBinding(client, k8s.RoleBindingData{Namespace: "dev", Name: "ci-deployer",
+		RoleRef: rbac.RoleRef{Kind: "ClusterRole", Name: "view"}, Subjects: subjects}); err == nil {
+		t.Error("changing the role of a binding should fail")
+	}
+
+	binding, err := UpdateRoleBinding(client, k8s.RoleBindingData{Namespace: "dev", Name: "ci-deployer", Subjects: subjects})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binding.RoleRef.Name != "deployer" || len(binding.Subjects) != 1 || binding.Subjects[0].Name != "alice" {
+		t.Errorf("UpdateRoleBinding = %+v", binding)
+	}
+
+	if _, err := CreateClusterRoleBinding(client, k8s.RoleBindingData{Name: "bad",
+		RoleRef: rbac.RoleRef{Kind: "Role", Name: "deployer"}}); err == nil {
+		t.Error("ClusterRoleBinding referencing a Role should fail")
+	}
+}
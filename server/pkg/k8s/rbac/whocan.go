@@ -0,0 +1,283 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/models/k8s"
+	"sort"
+	"strings"
+)
+
+// Grant 一个绑定授予的权限, Namespace为空时表示集群范围
+type Grant struct {
+	BindingKind string            `json:"bindingKind"`
+	BindingName string            `json:"bindingName"`
+	Namespace   string            `json:"namespace"`
+	RoleRef     rbac.RoleRef      `json:"roleRef"`
+	Subjects    []rbac.Subject    `json:"-"`
+	Rules       []rbac.PolicyRule `json:"rules"`
+}
+
+// Permission 主体对某类资源的有效动作
+type Permission struct {
+	Namespace     string   `json:"namespace"`
+	APIGroup      string   `json:"apiGroup"`
+	Resource      string   `json:"resource"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
+	Verbs         []string `json:"verbs"`
+}
+
+// SubjectPermissions 主体的绑定及合并后的有效权限
+type SubjectPermissions struct {
+	Subject     rbac.Subject `json:"subject"`
+	Grants      []Grant      `json:"grants"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// SubjectGrant 能执行某操作的主体及授权来源
+type SubjectGrant struct {
+	Subject     rbac.Subject `json:"subject"`
+	BindingKind string       `json:"bindingKind"`
+	BindingName string       `json:"bindingName"`
+	Namespace   string       `json:"namespace"`
+	RoleRef     rbac.RoleRef `json:"roleRef"`
+}
+
+// listGrants 读取集群中所有的绑定, 并解析出绑定对应角色的规则
+func listGrants(client kubernetes.Interface) ([]Grant, error) {
+	roles, err := client.RbacV1().Roles("").List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+	clusterRoles, err := client.RbacV1().ClusterRoles().List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+	roleBindings, err := client.RbacV1().RoleBindings("").List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+	clusterRoleBindings, err := client.RbacV1().ClusterRoleBindings().List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	roleRules := make(map[string][]rbac.PolicyRule, len(roles.Items))
+	for _, role := range roles.Items {
+		roleRules[role.Namespace+"/"+role.Name] = role.Rules
+	}
+	clusterRoleRules := make(map[string][]rbac.PolicyRule, len(clusterRoles.Items))
+	for _, role := range clusterRoles.Items {
+		clusterRoleRules[role.Name] = role.Rules
+	}
+
+	grants := make([]Grant, 0, len(roleBindings.Items)+len(clusterRoleBindings.Items))
+	for _, binding := range clusterRoleBindings.Items {
+		grants = append(grants, Grant{
+			BindingKind: "ClusterRoleBinding",
+			BindingName: binding.Name,
+			RoleRef:     binding.RoleRef,
+			Subjects:    binding.Subjects,
+			Rules:       clusterRoleRules[binding.RoleRef.Name],
+		})
+	}
+	for _, binding := range roleBindings.Items {
+		var rules []rbac.PolicyRule
+		if binding.RoleRef.Kind == "ClusterRole" {
+			rules = clusterRoleRules[binding.RoleRef.Name]
+		} else {
+			rules = roleRules[binding.Namespace+"/"+binding.RoleRef.Name]
+		}
+		grants = append(grants, Grant{
+			BindingKind: "RoleBinding",
+			BindingName: binding.Name,
+			Namespace:   binding.Namespace,
+			RoleRef:     binding.RoleRef,
+			Subjects:    binding.Subjects,
+			Rules:       rules,
+		})
+	}
+	return grants, nil
+}
+
+// subjectMatches 判断绑定中的主体是否包含目标主体, ServiceAccount和User隐含所属的系统组
+func subjectMatches(bound, target rbac.Subject) bool {
+	if bound.Kind == target.Kind && bound.Name == target.Name {
+		return bound.Kind != rbac.ServiceAccountKind || bound.Namespace == target.Namespace
+	}
+	if bound.Kind != rbac.GroupKind {
+		return false
+	}
+	switch target.Kind {
+	case rbac.ServiceAccountKind:
+		return bound.Name == "system:serviceaccounts" ||
+			bound.Name == "system:serviceaccounts:"+target.Namespace ||
+			bound.Name == "system:authenticated"
+	case rbac.UserKind:
+		return bound.Name == "system:authenticated"
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == rbac.VerbAll {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleAllows 判断规则是否允许对资源执行指定动作. 规则限定了ResourceNames时只对这些对象生效,
+// 未指定对象名称(如list、create或对所有对象的操作)时不匹配
+func ruleAllows(rule rbac.PolicyRule, verb, apiGroup, resource, name string) bool {
+	if !contains(rule.Verbs, verb) || !contains(rule.APIGroups, apiGroup) || !contains(rule.Resources, resource) {
+		return false
+	}
+	if len(rule.ResourceNames) == 0 {
+		return true
+	}
+	for _, resourceName := range rule.ResourceNames {
+		if name != "" && resourceName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSubjectPermissions 解析主体的所有绑定, 合并为每类资源的有效动作; namespace不为空时只返回该命名空间内生效的权限
+func GetSubjectPermissions(client kubernetes.Interface, subject rbac.Subject, namespace string) (*SubjectPermissions, error) {
+	grants, err := listGrants(client)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SubjectPermissions{Subject: subject, Grants: make([]Grant, 0), Permissions: make([]Permission, 0)}
+	index := make(map[permissionKey]int)
+	for _, grant := range grants {
+		if namespace != "" && grant.Namespace != "" && grant.Namespace != namespace {
+			continue
+		}
+		if !grantMatches(grant, subject) {
+			continue
+		}
+		result.Grants = append(result.Grants, grant)
+
+		for _, rule := range grant.Rules {
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					key := permissionKey{
+						namespace:     grant.Namespace,
+						apiGroup:      group,
+						resource:      resource,
+						resourceNames: strings.Join(rule.ResourceNames, ","),
+					}
+					i, ok := index[key]
+					if !ok {
+						i = len(result.Permissions)
+						index[key] = i
+						result.Permissions = append(result.Permissions, Permission{
+							Namespace:     grant.Namespace,
+							APIGroup:      group,
+							Resource:      resource,
+							ResourceNames: rule.ResourceNames,
+						})
+					}
+					result.Permissions[i].Verbs = mergeVerbs(result.Permissions[i].Verbs, rule.Verbs)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// permissionKey 合并有效权限时的分组键
+type permissionKey struct {
+	namespace     string
+	apiGroup      string
+	resource      string
+	resourceNames string
+}
+
+func grantMatches(grant Grant, subject rbac.Subject) bool {
+	for _, bound := range grant.Subjects {
+		if subjectMatches(bound, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeVerbs 合并动作并去重排序
+func mergeVerbs(verbs, add []string) []string {
+	for _, verb := range add {
+		exists := false
+		for _, v := range verbs {
+			if v == verb {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			verbs = append(verbs, verb)
+		}
+	}
+	sort.Strings(verbs)
+	return verbs
+}
+
+// WhoCan 查询能在命名空间中对资源执行指定动作的主体, name为空时表示对所有对象的操作, namespace为空时只匹配集群范围的授权
+func WhoCan(client kubernetes.Interface, verb, apiGroup, resource, name, namespace string) ([]SubjectGrant, error) {
+	if verb == "" || resource == "" {
+		return nil, fmt.Errorf("动作和资源不能为空")
+	}
+	grants, err := listGrants(client)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SubjectGrant, 0)
+	for _, grant := range grants {
+		if grant.Namespace != "" && grant.Namespace != namespace {
+			continue
+		}
+		allowed := false
+		for _, rule := range grant.Rules {
+			if ruleAllows(rule, verb, apiGroup, resource, name) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			continue
+		}
+		for _, subject := range grant.Subjects {
+			result = append(result, SubjectGrant{
+				Subject:     subject,
+				BindingKind: grant.BindingKind,
+				BindingName: grant.BindingName,
+				Namespace:   grant.Namespace,
+				RoleRef:     grant.RoleRef,
+			})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,144 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"testing"
+
+	rbac "k8s.io/api/rbac/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestClient() *fake.Clientset {
+	return fake.NewSimpleClientset(
+		&rbac.ClusterRole{
+			ObjectMeta: metaV1.ObjectMeta{Name: "view"},
+			Rules: []rbac.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods", "services"}, Verbs: []string{"get", "list"}},
+			},
+		},
+		&rbac.Role{
+			ObjectMeta: metaV1.ObjectMeta{Name: "deployer", Namespace: "dev"},
+			Rules: []rbac.PolicyRule{
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}},
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"delete"}},
+			},
+		},
+		&rbac.ClusterRoleBinding{
+			ObjectMeta: metaV1.ObjectMeta{Name: "all-sa-view"},
+			RoleRef:    rbac.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbac.Subject{{Kind: rbac.GroupKind, Name: "system:serviceaccounts:dev"}},
+		},
+		&rbac.RoleBinding{
+			ObjectMeta: metaV1.ObjectMeta{Name: "ci-deployer", Namespace: "dev"},
+			RoleRef:    rbac.RoleRef{Kind: "Role", Name: "deployer"},
+			Subjects:   []rbac.Subject{{Kind: rbac.ServiceAccountKind, Name: "ci", Namespace: "dev"}},
+		},
+	)
+}
+
+func TestGetSubjectPermissions(t *testing.T) {
+	client := newTestClient()
+	subject := rbac.Subject{Kind: rbac.ServiceAccountKind, Name: "ci", Namespace: "dev"}
+
+	result, err := GetSubjectPermissions(client, subject, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Grants) != 2 {
+		t.Fatalf("grants = %d, want 2", len(result.Grants))
+	}
+
+	verbs := make(map[string][]string)
+	for _, p := range result.Permissions {
+		verbs[p.Namespace+"/"+p.APIGroup+"/"+p.Resource] = p.Verbs
+	}
+	if got := verbs["//pods"]; len(got) != 2 || got[0] != "get" || got[1] != "list" {
+		t.Errorf("cluster pods verbs = %v", got)
+	}
+	if got := verbs["dev//pods"]; len(got) != 1 || got[0] != "delete" {
+		t.Errorf("dev pods verbs = %v", got)
+	}
+	if got := verbs["dev/apps/deployments"]; len(got) != 1 || got[0] != "*" {
+		t.Errorf("dev deployments verbs = %v", got)
+	}
+
+	result, err = GetSubjectPermissions(client, rbac.Subject{Kind: rbac.ServiceAccountKind, Name: "ci", Namespace: "prod"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Grants) != 0 {
+		t.Errorf("service account in another namespace got grants: %v", result.Grants)
+	}
+}
+
+func TestWhoCan(t *testing.T) {
+	client := newTestClient()
+
+	subjects, err := WhoCan(client, "update", "apps", "deployments", "", "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subjects) != 1 || subjects[0].Subject.Name != "ci" || subjects[0].BindingName != "ci-deployer" {
+		t.Errorf("WhoCan update deployments in dev = %v", subjects)
+	}
+
+	subjects, err = WhoCan(client, "update", "apps", "deployments", "", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subjects) != 0 {
+		t.Errorf("WhoCan update deployments in prod = %v", subjects)
+	}
+
+	subjects, err = WhoCan(client, "list", "", "pods", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subjects) != 1 || subjects[0].Subject.Kind != rbac.GroupKind {
+		t.Errorf("WhoCan list pods = %v", subjects)
+	}
+}
+
+func TestRuleAllows(t *testing.T) {
+	named := rbac.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"},
+		ResourceNames: []string{"app-config"}, Verbs: []string{"get", "update"}}
+	all := rbac.PolicyRule{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}
+
+	cases := []struct {
+		rule                           rbac.PolicyRule
+		verb, apiGroup, resource, name string
+		want                           bool
+	}{
+		{named, "get", "", "configmaps", "app-config", true},
+		{named, "update", "", "configmaps", "app-config", true},
+		// 限定了ResourceNames的规则不授予对其他对象或所有对象的权限
+		{named, "get", "", "configmaps", "other", false},
+		{named, "get", "", "configmaps", "", false},
+		{named, "list", "", "configmaps", "", false},
+		{named, "delete", "", "configmaps", "app-config", false},
+		{named, "get", "apps", "configmaps", "app-config", false},
+		{all, "delete", "apps", "deployments", "", true},
+		{all, "get", "", "secrets", "any", true},
+	}
+	for i, c := range cases {
+		if got := ruleAllows(c.rule, c.verb, c.apiGroup, c.resource, c.name); got != c.want {
+			t.Errorf("case %d: ruleAllows(%s %s/%s %q) = %v, want %v", i, c.verb, c.apiGroup, c.resource, c.name, got, c.want)
+		}
+	}
+}
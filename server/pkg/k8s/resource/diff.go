@@ -0,0 +1,67 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// Diff returns a unified diff between the YAML of the live and the applied object. A nil live
+// object diffs against an empty document. Fields the apiserver bumps on every write are left out
+// so unchanged objects produce an empty diff. Secret values are redacted, only changed keys are marked.
+func Diff(live, applied *unstructured.Unstructured) (string, error) {
+	if isSecret(live) || isSecret(applied) {
+		live, applied = redactSecretDiff(live, applied)
+	}
+	from, err := toDiffYAML(live)
+	if err != nil {
+		return "", err
+	}
+	to, err := toDiffYAML(applied)
+	if err != nil {
+		return "", err
+	}
+	if from == to {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: "live",
+		ToFile:   "applied",
+		Context:  3,
+	})
+}
+
+func toDiffYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	obj = obj.DeepCopy()
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	obj.SetGeneration(0)
+
+	data, err := sigsyaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,267 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"kubespace/server/common"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// FieldManager server-side apply时使用的字段管理者名称
+const FieldManager = "kubespace"
+
+// DryRunAll 与Kubernetes API的dryRun=All参数一致
+const DryRunAll = metav1.DryRunAll
+
+// 单个资源的apply结果
+const (
+	OperationCreated    = "created"
+	OperationConfigured = "configured"
+	OperationUnchanged  = "unchanged"
+	OperationFailed     = "failed"
+)
+
+// ApplyResult is the outcome of applying a single object of a manifest.
+type ApplyResult struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+
+	// Operation is one of created, configured, unchanged or failed.
+	Operation string `json:"operation"`
+
+	// DryRun is true when the apiserver didn't persist the object.
+	DryRun bool `json:"dryRun"`
+
+	// Diff is a unified diff between the live object and the applied one.
+	Diff string `json:"diff"`
+
+	// Error describes why applying the object failed.
+	Error string `json:"error,omitempty"`
+}
+
+// ApplyOptions 资源apply参数
+type ApplyOptions struct {
+	// Namespace is used for namespaced objects that don't set one.
+	Namespace string
+	// Force takes over fields owned by other field managers instead of failing with a conflict.
+	Force bool
+	// DryRun validates and merges the objects on the server without persisting them.
+	DryRun bool
+}
+
+// DecodeManifest decodes a multi-document YAML or JSON manifest into objects. List kinds
+// (e.g. v1/List) are flattened into their items.
+func DecodeManifest(content []byte) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	objects := make([]*unstructured.Unstructured, 0)
+	for {
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("解析资源清单失败: %v", err)
+		}
+		// 空文档, 例如以"---"结尾的清单
+		if len(raw) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				objects = append(objects, item.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// ApplyResources server-side applies every object of the manifest and reports per-object
+// results. A failing object doesn't stop the remaining ones.
+func ApplyResources(client dynamic.Interface, mapper *restmapper.DeferredDiscoveryRESTMapper, content []byte, opts ApplyOptions) ([]ApplyResult, error) {
+	objects, err := DecodeManifest(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("资源清单中没有可用的资源")
+	}
+
+	results := make([]ApplyResult, 0, len(objects))
+	for _, obj := range objects {
+		results = append(results, applyResource(client, mapper, obj, opts))
+	}
+	return results, nil
+}
+
+func applyResource(client dynamic.Interface, mapper *restmapper.DeferredDiscoveryRESTMapper, obj *unstructured.Unstructured, opts ApplyOptions) ApplyResult {
+	result := ApplyResult{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		DryRun:     opts.DryRun,
+		Operation:  OperationFailed,
+	}
+	if result.Kind == "" || result.APIVersion == "" {
+		result.Error = "apiVersion和kind不能为空"
+		return result
+	}
+	if result.Name == "" {
+		result.Error = "metadata.name不能为空"
+		return result
+	}
+
+	ri, namespace, err := ResourceInterface(client, mapper, obj.GroupVersionKind(), obj.GetNamespace(), opts.Namespace)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	result.Namespace = namespace
+	// apply请求中不允许携带managedFields
+	obj.SetManagedFields(nil)
+
+	live, err := ri.Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			result.Error = err.Error()
+			return result
+		}
+		live = nil
+	}
+	if isSecret(obj) {
+		if err := restoreRedactedSecret(obj, live); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	patchOptions := metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &opts.Force,
+	}
+	if opts.DryRun {
+		patchOptions.DryRun = []string{DryRunAll}
+	}
+
+	common.LOG.Info(fmt.Sprintf("apply资源: %v/%v, namespace: %v, dryRun: %v", result.Kind, result.Name, namespace, opts.DryRun))
+	applied, err := ri.Patch(context.TODO(), obj.GetName(), types.ApplyPatchType, data, patchOptions)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Diff, err = Diff(live, applied)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	switch {
+	case live == nil:
+		result.Operation = OperationCreated
+	case result.Diff == "":
+		result.Operation = OperationUnchanged
+	default:
+		result.Operation = OperationConfigured
+	}
+	return result
+}
+
+// ResourceInterface resolves the dynamic client of a kind. For namespaced kinds the namespace
+// of the object is used, then defaultNamespace, then "default"; the chosen namespace is
+// returned alongside. Discovery is refreshed once when the kind is unknown, e.g. for a
+// CustomResourceDefinition created after the mapper was built.
+func ResourceInterface(client dynamic.Interface, mapper *restmapper.DeferredDiscoveryRESTMapper, gvk schema.GroupVersionKind,
+	namespace, defaultNamespace string) (dynamic.ResourceInterface, string, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		mapper.Reset()
+		mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return client.Resource(mapping.Resource), "", nil
+	}
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	return client.Resource(mapping.Resource).Namespace(namespace), namespace, nil
+}
+
+// GetResourceYAML returns the live object as YAML, ready to be edited and sent back to
+// ApplyResources. managedFields are dropped since apply requests must not carry them.
+// Secret values are redacted; ApplyResources restores the ones left untouched.
+func GetResourceYAML(client dynamic.Interface, mapper *restmapper.DeferredDiscoveryRESTMapper, apiVersion, kind, namespace, name string) (string, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return "", err
+	}
+	ri, _, err := ResourceInterface(client, mapper, gv.WithKind(kind), namespace, "")
+	if err != nil {
+		return "", err
+	}
+	obj, err := ri.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	obj.SetManagedFields(nil)
+	if isSecret(obj) {
+		obj = RedactSecret(obj)
+	}
+
+	data, err := sigsyaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
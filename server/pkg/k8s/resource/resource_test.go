@@ -0,0 +1,187 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/restmapper"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDecodeManifest(t *testing.T) {
+	cases := []struct {
+		content       string
+		expectedKinds []string
+	}{
+		{
+			content: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b
+---
+`,
+			expectedKinds: []string{"ConfigMap", "Deployment"},
+		},
+		{
+			content:       `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "c"}}`,
+			expectedKinds: []string{"Service"},
+		},
+		{
+			content: `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: d
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: e
+`,
+			expectedKinds: []string{"Secret", "ConfigMap"},
+		},
+	}
+
+	for _, c := range cases {
+		objects, err := DecodeManifest([]byte(c.content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var kinds []string
+		for _, obj := range objects {
+			kinds = append(kinds, obj.GetKind())
+		}
+		if strings.Join(kinds, ",") != strings.Join(c.expectedKinds, ",") {
+			t.Errorf("DecodeManifest(%q) kinds == %v, expected %v", c.content, kinds, c.expectedKinds)
+		}
+	}
+
+	if _, err := DecodeManifest([]byte("kind: [")); err == nil {
+		t.Error("expected an error for a malformed manifest")
+	}
+}
+
+func newConfigMap(resourceVersion string, data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":            "a",
+			"resourceVersion": resourceVersion,
+		},
+		"data": data,
+	}}
+}
+
+func TestDiff(t *testing.T) {
+	live := newConfigMap("1", map[string]interface{}{"key": "old"})
+
+	diff, err := Diff(live, newConfigMap("2", map[string]interface{}{"key": "old"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Errorf("expected no diff when only the resourceVersion changed, got %q", diff)
+	}
+
+	diff, err = Diff(live, newConfigMap("2", map[string]interface{}{"key": "new"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "-  key: old") || !strings.Contains(diff, "+  key: new") {
+		t.Errorf("unexpected diff %q", diff)
+	}
+
+	diff, err = Diff(nil, live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "+kind: ConfigMap") {
+		t.Errorf("expected a diff against an empty document for a new object, got %q", diff)
+	}
+}
+
+func newSecret(data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      "db",
+			"namespace": "default",
+			"annotations": map[string]interface{}{
+				corev1.LastAppliedConfigAnnotation: `{"data":{"password":"c2VjcmV0"}}`,
+			},
+		},
+		"data": data,
+	}}
+}
+
+func TestSecretRedaction(t *testing.T) {
+	live := newSecret(map[string]interface{}{"password": "c2VjcmV0", "user": "YWRtaW4="})
+
+	discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "secrets", Namespaced: true, Kind: "Secret"}},
+	}}}}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discovery))
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), live)
+	yaml, err := GetResourceYAML(client, mapper, "v1", "Secret", "default", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(yaml, "c2VjcmV0") || strings.Contains(yaml, "YWRtaW4=") {
+		t.Errorf("secret values leaked in yaml: %s", yaml)
+	}
+
+	applied := newSecret(map[string]interface{}{"password": "bmV3", "user": "YWRtaW4="})
+	diff, err := Diff(live, applied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(diff, "c2VjcmV0") || strings.Contains(diff, "bmV3") || !strings.Contains(diff, "+  password: '"+redactedChanged+"'") {
+		t.Errorf("unexpected secret diff %q", diff)
+	}
+
+	edited := newSecret(map[string]interface{}{"password": RedactedValue, "user": "bmV3"})
+	if err := restoreRedactedSecret(edited, live); err != nil {
+		t.Fatal(err)
+	}
+	if data := edited.Object["data"].(map[string]interface{}); data["password"] != "c2VjcmV0" || data["user"] != "bmV3" {
+		t.Errorf("unexpected restored data %v", data)
+	}
+	if err := restoreRedactedSecret(newSecret(map[string]interface{}{"token": RedactedValue}), live); err == nil {
+		t.Error("redacted value without a live value must be rejected")
+	}
+}
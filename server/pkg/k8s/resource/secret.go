@@ -0,0 +1,97 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// RedactedValue Secret的值在YAML和diff中替换为该标记, 查看明文需要config/secret/reveal权限.
+// 该标记不是合法的base64, 未被还原时apply会失败而不会写入错误的值
+const RedactedValue = "<redacted>"
+
+// redactedChanged diff中表示Secret的值发生了变化
+const redactedChanged = "<redacted: changed>"
+
+func isSecret(obj *unstructured.Unstructured) bool {
+	return obj != nil && obj.GetKind() == "Secret" && obj.GroupVersionKind().Group == corev1.GroupName
+}
+
+// RedactSecret 返回脱敏后的Secret副本, data、stringData的值替换为RedactedValue,
+// 并去掉包含明文的kubectl last-applied-configuration注解
+func RedactSecret(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	obj = obj.DeepCopy()
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := obj.Object[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for k := range values {
+			values[k] = RedactedValue
+		}
+	}
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		delete(annotations, corev1.LastAppliedConfigAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+	return obj
+}
+
+// redactSecretDiff 脱敏diff两侧的Secret, 值发生变化的key在applied一侧标记为redactedChanged
+func redactSecretDiff(live, applied *unstructured.Unstructured) (*unstructured.Unstructured, *unstructured.Unstructured) {
+	var liveData map[string]interface{}
+	if live != nil {
+		liveData, _ = live.Object["data"].(map[string]interface{})
+		live = RedactSecret(live)
+	}
+	if applied != nil {
+		appliedData, _ := applied.Object["data"].(map[string]interface{})
+		applied = RedactSecret(applied)
+		redacted, _ := applied.Object["data"].(map[string]interface{})
+		for k, v := range appliedData {
+			if old, ok := liveData[k]; ok && old != v {
+				redacted[k] = redactedChanged
+			}
+		}
+	}
+	return live, applied
+}
+
+// restoreRedactedSecret 将编辑器提交的Secret中仍为RedactedValue的值还原为线上的值
+func restoreRedactedSecret(obj, live *unstructured.Unstructured) error {
+	data, ok := obj.Object["data"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var liveData map[string]interface{}
+	if live != nil {
+		liveData, _ = live.Object["data"].(map[string]interface{})
+	}
+	for k, v := range data {
+		if v != RedactedValue {
+			continue
+		}
+		old, ok := liveData[k]
+		if !ok {
+			return fmt.Errorf("Secret %s的%s为脱敏值, 请填写实际的值", obj.GetName(), k)
+		}
+		data[k] = old
+	}
+	return nil
+}
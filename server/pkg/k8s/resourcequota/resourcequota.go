@@ -0,0 +1,154 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"kubespace/server/pkg/k8s/dataselect"
+)
+
+// ResourceStatus 配额的上限和已使用量
+type ResourceStatus struct {
+	Used string `json:"used,omitempty"`
+	Hard string `json:"hard,omitempty"`
+}
+
+// ResourceQuotaList contains a list of Resource Quotas in the cluster.
+type ResourceQuotaList struct {
+	ListMeta k8s.ListMeta `json:"listMeta"`
+
+	// Unordered list of Resource Quotas
+	Items []ResourceQuotaDetail `json:"items"`
+}
+
+// ResourceQuotaDetail 配额详情, StatusList中是每种资源的上限和已使用量
+type ResourceQuotaDetail struct {
+	ObjectMeta k8s.ObjectMeta `json:"objectMeta"`
+	TypeMeta   k8s.TypeMeta   `json:"typeMeta"`
+
+	// Scopes 配额作用范围, 为空时作用于命名空间下的所有资源
+	Scopes []v1.ResourceQuotaScope `json:"scopes,omitempty"`
+
+	// ScopeSelector is also a collection of filters like Scopes that must match each object tracked by a quota
+	ScopeSelector *v1.ScopeSelector `json:"scopeSelector,omitempty"`
+
+	StatusList map[v1.ResourceName]ResourceStatus `json:"statusList,omitempty"`
+}
+
+// ToResourceQuotaDetail 转换ResourceQuota, 合并spec.hard和status.used
+func ToResourceQuotaDetail(rawResourceQuota *v1.ResourceQuota) *ResourceQuotaDetail {
+	statusList := make(map[v1.ResourceName]ResourceStatus)
+	for key, value := range rawResourceQuota.Spec.Hard {
+		statusList[key] = ResourceStatus{Hard: value.String()}
+	}
+	for key, value := range rawResourceQuota.Status.Used {
+		status := statusList[key]
+		status.Used = value.String()
+		statusList[key] = status
+	}
+	return &ResourceQuotaDetail{
+		ObjectMeta:    k8s.NewObjectMeta(rawResourceQuota.ObjectMeta),
+		TypeMeta:      k8s.NewTypeMeta(k8s.ResourceKindResourceQuota),
+		Scopes:        rawResourceQuota.Spec.Scopes,
+		ScopeSelector: rawResourceQuota.Spec.ScopeSelector,
+		StatusList:    statusList,
+	}
+}
+
+// GetResourceQuotaList returns a list of all Resource Quotas in the cluster.
+func GetResourceQuotaList(client kubernetes.Interface, nsQuery *k8scommon.NamespaceQuery, dsQuery *dataselect.DataSelectQuery) (*ResourceQuotaList, error) {
+	common.LOG.Info(fmt.Sprintf("Getting list resource quotas in the namespace %s", nsQuery.ToRequestParam()))
+	list, err := client.CoreV1().ResourceQuotas(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredItems []v1.ResourceQuota
+	for _, item := range list.Items {
+		if nsQuery.Matches(item.ObjectMeta.Namespace) {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+	return toResourceQuotaList(filteredItems, dsQuery), nil
+}
+
+func toResourceQuotaList(resourceQuotas []v1.ResourceQuota, dsQuery *dataselect.DataSelectQuery) *ResourceQuotaList {
+	result := &ResourceQuotaList{
+		Items:    make([]ResourceQuotaDetail, 0),
+		ListMeta: k8s.ListMeta{TotalItems: len(resourceQuotas)},
+	}
+
+	resourceQuotaCells, filteredTotal := dataselect.GenericDataSelectWithFilter(toCells(resourceQuotas), dsQuery)
+	resourceQuotas = fromCells(resourceQuotaCells)
+	result.ListMeta = k8s.ListMeta{TotalItems: filteredTotal}
+
+	for i := range resourceQuotas {
+		result.Items = append(result.Items, *ToResourceQuotaDetail(&resourceQuotas[i]))
+	}
+	return result
+}
+
+// GetResourceQuotaDetail returns detailed information about a resource quota
+func GetResourceQuotaDetail(client kubernetes.Interface, namespace, name string) (*ResourceQuotaDetail, error) {
+	rawResourceQuota, err := client.CoreV1().ResourceQuotas(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ToResourceQuotaDetail(rawResourceQuota), nil
+}
+
+// CreateResourceQuota 创建ResourceQuota
+func CreateResourceQuota(client kubernetes.Interface, data k8s.ResourceQuotaData) (*ResourceQuotaDetail, error) {
+	common.LOG.Info(fmt.Sprintf("创建ResourceQuota: %v, namespace: %v", data.Name, data.Namespace))
+	quota := &v1.ResourceQuota{
+		ObjectMeta: metaV1.ObjectMeta{Name: data.Name, Namespace: data.Namespace},
+		Spec:       data.Spec,
+	}
+	created, err := client.CoreV1().ResourceQuotas(data.Namespace).Create(context.TODO(), quota, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ToResourceQuotaDetail(created), nil
+}
+
+// UpdateResourceQuota 更新ResourceQuota的spec
+func UpdateResourceQuota(client kubernetes.Interface, data k8s.ResourceQuotaData) (*ResourceQuotaDetail, error) {
+	common.LOG.Info(fmt.Sprintf("更新ResourceQuota: %v, namespace: %v", data.Name, data.Namespace))
+	quota, err := client.CoreV1().ResourceQuotas(data.Namespace).Get(context.TODO(), data.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	quota.Spec = data.Spec
+	updated, err := client.CoreV1().ResourceQuotas(data.Namespace).Update(context.TODO(), quota, metaV1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ToResourceQuotaDetail(updated), nil
+}
+
+// DeleteResourceQuota 删除ResourceQuota
+func DeleteResourceQuota(client kubernetes.Interface, namespace, name string) error {
+	common.LOG.Info(fmt.Sprintf("请求删除ResourceQuota: %v, namespace: %v", name, namespace))
+	return client.CoreV1().ResourceQuotas(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+}
@@ -0,0 +1,229 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"encoding/json"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sort"
+)
+
+// 变更的分类
+const (
+	ChangeContainer = "container"
+	ChangeImage     = "image"
+	ChangeEnv       = "env"
+	ChangeResources = "resources"
+	ChangeProbe     = "probe"
+	ChangeVolume    = "volume"
+	ChangeLabel     = "label"
+)
+
+// FieldChange 两个版本之间的一处差异, Old为空表示新增, New为空表示删除
+type FieldChange struct {
+	Category  string `json:"category"`
+	Container string `json:"container,omitempty"`
+	Field     string `json:"field"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+}
+
+// RevisionDiff 两个版本的Pod模板差异
+type RevisionDiff struct {
+	From    Revision      `json:"from"`
+	To      Revision      `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// DiffRevisions 比较工作负载两个历史版本的Pod模板
+func DiffRevisions(client kubernetes.Interface, kind, namespace, name string, from, to int64) (*RevisionDiff, error) {
+	revisions, err := GetRevisions(client, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	var fromRevision, toRevision *Revision
+	for i := range revisions {
+		// from和to可能是同一个版本
+		if revisions[i].Revision == from {
+			fromRevision = &revisions[i]
+		}
+		if revisions[i].Revision == to {
+			toRevision = &revisions[i]
+		}
+	}
+	if fromRevision == nil {
+		return nil, fmt.Errorf("版本%d不存在", from)
+	}
+	if toRevision == nil {
+		return nil, fmt.Errorf("版本%d不存在", to)
+	}
+	return &RevisionDiff{
+		From:    *fromRevision,
+		To:      *toRevision,
+		Changes: DiffTemplates(fromRevision.template, toRevision.template),
+	}, nil
+}
+
+// DiffTemplates 比较Pod模板中的镜像、环境变量、资源、探针、存储卷和标签
+func DiffTemplates(old, new *v1.PodTemplateSpec) []FieldChange {
+	changes := make([]FieldChange, 0)
+	changes = append(changes, diffMap(ChangeLabel, "", old.Labels, new.Labels)...)
+
+	oldContainers := containerMap(old)
+	newContainers := containerMap(new)
+	for _, name := range containerNames(oldContainers, newContainers) {
+		oldContainer, inOld := oldContainers[name]
+		newContainer, inNew := newContainers[name]
+		switch {
+		case !inOld:
+			changes = append(changes, FieldChange{Category: ChangeContainer, Container: name, Field: "image", New: newContainer.Image})
+		case !inNew:
+			changes = append(changes, FieldChange{Category: ChangeContainer, Container: name, Field: "image", Old: oldContainer.Image})
+		default:
+			changes = append(changes, diffContainer(oldContainer, newContainer)...)
+		}
+	}
+
+	changes = append(changes, diffMap(ChangeVolume, "", volumeMap(old.Spec.Volumes), volumeMap(new.Spec.Volumes))...)
+	return changes
+}
+
+func diffContainer(old, new v1.Container) []FieldChange {
+	var changes []FieldChange
+	if old.Image != new.Image {
+		changes = append(changes, FieldChange{Category: ChangeImage, Container: old.Name, Field: "image", Old: old.Image, New: new.Image})
+	}
+	changes = append(changes, diffMap(ChangeEnv, old.Name, envMap(old), envMap(new))...)
+	changes = append(changes, diffMap(ChangeResources, old.Name, resourceMap(old.Resources), resourceMap(new.Resources))...)
+	changes = append(changes, diffMap(ChangeProbe, old.Name, probeMap(old), probeMap(new))...)
+	changes = append(changes, diffMap(ChangeVolume, old.Name, mountMap(old), mountMap(new))...)
+	return changes
+}
+
+// diffMap 按key比较, key有序以保证结果稳定
+func diffMap(category, container string, old, new map[string]string) []FieldChange {
+	var changes []FieldChange
+	for _, key := range unionKeys(old, new) {
+		if old[key] != new[key] {
+			changes = append(changes, FieldChange{Category: category, Container: container, Field: key, Old: old[key], New: new[key]})
+		}
+	}
+	return changes
+}
+
+func unionKeys(old, new map[string]string) []string {
+	keys := make([]string, 0, len(old)+len(new))
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range new {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containerNames(old, new map[string]v1.Container) []string {
+	names := make(map[string]string)
+	for name := range old {
+		names[name] = name
+	}
+	for name := range new {
+		names[name] = name
+	}
+	return unionKeys(names, nil)
+}
+
+// containerMap 初始化容器和普通容器按名称合并, 容器名在Pod内唯一
+func containerMap(template *v1.PodTemplateSpec) map[string]v1.Container {
+	containers := make(map[string]v1.Container)
+	for _, c := range template.Spec.InitContainers {
+		containers[c.Name] = c
+	}
+	for _, c := range template.Spec.Containers {
+		containers[c.Name] = c
+	}
+	return containers
+}
+
+func envMap(container v1.Container) map[string]string {
+	env := make(map[string]string)
+	for _, e := range container.Env {
+		if e.ValueFrom != nil {
+			env[e.Name] = toJSON(e.ValueFrom)
+		} else {
+			env[e.Name] = e.Value
+		}
+	}
+	for _, from := range container.EnvFrom {
+		env["envFrom:"+from.Prefix] = toJSON(from)
+	}
+	return env
+}
+
+func resourceMap(resources v1.ResourceRequirements) map[string]string {
+	result := make(map[string]string)
+	for name, quantity := range resources.Limits {
+		result["limits."+string(name)] = quantity.String()
+	}
+	for name, quantity := range resources.Requests {
+		result["requests."+string(name)] = quantity.String()
+	}
+	return result
+}
+
+func probeMap(container v1.Container) map[string]string {
+	result := make(map[string]string)
+	if container.LivenessProbe != nil {
+		result["livenessProbe"] = toJSON(container.LivenessProbe)
+	}
+	if container.ReadinessProbe != nil {
+		result["readinessProbe"] = toJSON(container.ReadinessProbe)
+	}
+	if container.StartupProbe != nil {
+		result["startupProbe"] = toJSON(container.StartupProbe)
+	}
+	return result
+}
+
+func volumeMap(volumes []v1.Volume) map[string]string {
+	result := make(map[string]string)
+	for _, volume := range volumes {
+		result[volume.Name] = toJSON(volume.VolumeSource)
+	}
+	return result
+}
+
+func mountMap(container v1.Container) map[string]string {
+	result := make(map[string]string)
+	for _, mount := range container.VolumeMounts {
+		result["mount:"+mount.MountPath] = toJSON(mount)
+	}
+	return result
+}
+
+func toJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
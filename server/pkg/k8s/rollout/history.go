@@ -0,0 +1,217 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	deploymentutil "k8s.io/kubectl/pkg/util/deployment"
+	"kubespace/server/common"
+	"sort"
+	"strings"
+)
+
+// Revision 工作负载的历史版本, Deployment对应ReplicaSet, StatefulSet和DaemonSet对应ControllerRevision
+type Revision struct {
+	Revision    int64       `json:"revision"`
+	Name        string      `json:"name"`
+	CreateTime  metav1.Time `json:"createTime"`
+	Images      []string    `json:"images"`
+	ChangeCause string      `json:"changeCause"`
+
+	template *v1.PodTemplateSpec
+}
+
+// GetRevisions 获取工作负载的历史版本, 按版本号升序
+func GetRevisions(client kubernetes.Interface, kind, namespace, name string) ([]Revision, error) {
+	var (
+		revisions []Revision
+		err       error
+	)
+	switch strings.ToLower(kind) {
+	case KindDeployment:
+		revisions, err = deploymentRevisions(client, namespace, name)
+	case KindStatefulSet:
+		revisions, err = statefulSetRevisions(client, namespace, name)
+	case KindDaemonSet:
+		revisions, err = daemonSetRevisions(client, namespace, name)
+	default:
+		return nil, unsupportedKindErr(kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+func newRevision(revision int64, meta metav1.ObjectMeta, template *v1.PodTemplateSpec) Revision {
+	// 去掉控制器生成的hash标签, 避免每个版本的标签都不同
+	delete(template.Labels, apps.DefaultDeploymentUniqueLabelKey)
+	delete(template.Labels, apps.ControllerRevisionHashLabelKey)
+
+	images := make([]string, 0, len(template.Spec.Containers))
+	for _, container := range template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return Revision{
+		Revision:    revision,
+		Name:        meta.Name,
+		CreateTime:  meta.CreationTimestamp,
+		Images:      images,
+		ChangeCause: meta.Annotations[ChangeCauseAnnotation],
+		template:    template,
+	}
+}
+
+func deploymentRevisions(client kubernetes.Interface, namespace, name string) ([]Revision, error) {
+	deployment, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	_, oldRSs, newRS, err := deploymentutil.GetAllReplicaSets(deployment, client.AppsV1())
+	if err != nil {
+		return nil, err
+	}
+	if newRS != nil {
+		oldRSs = append(oldRSs, newRS)
+	}
+
+	revisions := make([]Revision, 0, len(oldRSs))
+	for _, rs := range oldRSs {
+		revision, err := deploymentutil.Revision(rs)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, newRevision(revision, rs.ObjectMeta, rs.Spec.Template.DeepCopy()))
+	}
+	return revisions, nil
+}
+
+// controllerRevisions 获取由工作负载控制的ControllerRevision
+func controllerRevisions(client kubernetes.Interface, owner metav1.Object, labelSelector *metav1.LabelSelector) ([]apps.ControllerRevision, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.AppsV1().ControllerRevisions(owner.GetNamespace()).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]apps.ControllerRevision, 0, len(list.Items))
+	for i := range list.Items {
+		if metav1.IsControlledBy(&list.Items[i], owner) {
+			result = append(result, list.Items[i])
+		}
+	}
+	return result, nil
+}
+
+// applyControllerRevision ControllerRevision中保存的是模板的strategic merge patch, 应用到当前对象后得到该版本的模板
+func applyControllerRevision(current interface{}, history *apps.ControllerRevision, result interface{}) error {
+	original, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, history.Data.Raw, current)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(patched, result)
+}
+
+func statefulSetRevisions(client kubernetes.Interface, namespace, name string) ([]Revision, error) {
+	sts, err := client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	histories, err := controllerRevisions(client, sts, sts.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]Revision, 0, len(histories))
+	for i := range histories {
+		applied := &apps.StatefulSet{}
+		if err := applyControllerRevision(sts, &histories[i], applied); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, newRevision(histories[i].Revision, histories[i].ObjectMeta, &applied.Spec.Template))
+	}
+	return revisions, nil
+}
+
+func daemonSetRevisions(client kubernetes.Interface, namespace, name string) ([]Revision, error) {
+	ds, err := client.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	histories, err := controllerRevisions(client, ds, ds.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]Revision, 0, len(histories))
+	for i := range histories {
+		applied := &apps.DaemonSet{}
+		if err := applyControllerRevision(ds, &histories[i], applied); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, newRevision(histories[i].Revision, histories[i].ObjectMeta, &applied.Spec.Template))
+	}
+	return revisions, nil
+}
+
+// RollbackRevision 将StatefulSet或DaemonSet回滚到指定版本, revision为0时回滚到上一个版本.
+// Deployment的回滚使用deployment.RollbackDeployment
+func RollbackRevision(client kubernetes.Interface, kind, namespace, name string, revision int64) (string, error) {
+	common.LOG.Info(fmt.Sprintf("%s: %v, namespace: %v, 版本回滚到%v", kind, name, namespace, revision))
+	var (
+		rollbacker polymorphichelpers.Rollbacker
+		obj        runtime.Object
+		err        error
+	)
+	switch strings.ToLower(kind) {
+	case KindStatefulSet:
+		var sts *apps.StatefulSet
+		if sts, err = client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{}); err != nil {
+			return "", err
+		}
+		obj = sts
+		rollbacker, err = polymorphichelpers.RollbackerFor(apps.SchemeGroupVersion.WithKind("StatefulSet").GroupKind(), client)
+	case KindDaemonSet:
+		var ds *apps.DaemonSet
+		if ds, err = client.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{}); err != nil {
+			return "", err
+		}
+		obj = ds
+		rollbacker, err = polymorphichelpers.RollbackerFor(apps.SchemeGroupVersion.WithKind("DaemonSet").GroupKind(), client)
+	default:
+		return "", unsupportedKindErr(kind)
+	}
+	if err != nil {
+		return "", err
+	}
+	return rollbacker.Rollback(obj, nil, revision, cmdutil.DryRunNone)
+}
@@ -0,0 +1,149 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.uber.org/zap"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"kubespace/server/common"
+)
+
+func newTemplate(image, logLevel, memory string) v1.PodTemplateSpec {
+	return v1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}},
+		Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name:  "db",
+			Image: image,
+			Env:   []v1.EnvVar{{Name: "LOG_LEVEL", Value: logLevel}},
+			Resources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse(memory)},
+			},
+		}}},
+	}
+}
+
+// newControllerRevision 按StatefulSet控制器的格式保存模板
+func newControllerRevision(owner *apps.StatefulSet, name string, revision int64, template v1.PodTemplateSpec) *apps.ControllerRevision {
+	raw, _ := json.Marshal(template)
+	var objCopy map[string]interface{}
+	_ = json.Unmarshal(raw, &objCopy)
+	objCopy["$patch"] = "replace"
+	patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": objCopy}})
+
+	return &apps.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       owner.Namespace,
+			Labels:          map[string]string{"app": "db"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(owner, apps.SchemeGroupVersion.WithKind("StatefulSet"))},
+		},
+		Data:     runtime.RawExtension{Raw: patch},
+		Revision: revision,
+	}
+}
+
+func TestDiffTemplates(t *testing.T) {
+	old := newTemplate("mysql:5.7", "info", "1Gi")
+	new := newTemplate("mysql:8.0", "debug", "1Gi")
+	new.Labels["version"] = "8"
+	new.Spec.Containers[0].ReadinessProbe = &v1.Probe{InitialDelaySeconds: 5}
+
+	changes := DiffTemplates(&old, &new)
+	want := map[string]FieldChange{
+		ChangeLabel: {Category: ChangeLabel, Field: "version", New: "8"},
+		ChangeImage: {Category: ChangeImage, Container: "db", Field: "image", Old: "mysql:5.7", New: "mysql:8.0"},
+		ChangeEnv:   {Category: ChangeEnv, Container: "db", Field: "LOG_LEVEL", Old: "info", New: "debug"},
+	}
+	found := make(map[string]bool)
+	for _, change := range changes {
+		if change.Category == ChangeProbe {
+			if change.Field != "readinessProbe" || change.Old != "" || change.New == "" {
+				t.Errorf("unexpected probe change: %+v", change)
+			}
+			found[ChangeProbe] = true
+			continue
+		}
+		if expected, ok := want[change.Category]; !ok || expected != change {
+			t.Errorf("unexpected change: %+v", change)
+		}
+		found[change.Category] = true
+	}
+	if len(found) != 4 {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestStatefulSetRevisionsAndRollback(t *testing.T) {
+	common.LOG = zap.NewNop()
+	sts := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: types.UID("sts-uid")},
+		Spec: apps.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Template: newTemplate("mysql:8.0", "debug", "2Gi"),
+		},
+	}
+	client := fake.NewSimpleClientset(sts,
+		newControllerRevision(sts, "db-1", 1, newTemplate("mysql:5.7", "info", "1Gi")),
+		newControllerRevision(sts, "db-2", 2, newTemplate("mysql:8.0", "debug", "2Gi")),
+	)
+
+	revisions, err := GetRevisions(client, "StatefulSet", "default", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 || revisions[0].Images[0] != "mysql:5.7" || revisions[1].Images[0] != "mysql:8.0" {
+		t.Fatalf("unexpected revisions: %+v", revisions)
+	}
+
+	diff, err := DiffRevisions(client, "statefulset", "default", "db", 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Changes) != 3 {
+		t.Errorf("changes = %+v", diff.Changes)
+	}
+
+	diff, err = DiffRevisions(client, "statefulset", "default", "db", 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.From.Revision != 2 || diff.To.Revision != 2 || len(diff.Changes) != 0 {
+		t.Errorf("diff of the same revision = %+v", diff)
+	}
+
+	if _, err = RollbackRevision(client, "statefulset", "default", "db", 1); err != nil {
+		t.Fatal(err)
+	}
+	current, _ := client.AppsV1().StatefulSets("default").Get(context.TODO(), "db", metav1.GetOptions{})
+	if current.Spec.Template.Spec.Containers[0].Image != "mysql:5.7" {
+		t.Errorf("image after rollback = %s", current.Spec.Template.Spec.Containers[0].Image)
+	}
+
+	if _, err = RollbackRevision(client, "deployment", "default", "db", 1); err == nil {
+		t.Error("expected error for deployment")
+	}
+}
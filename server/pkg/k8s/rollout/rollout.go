@@ -0,0 +1,155 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	"strings"
+)
+
+// ChangeCauseAnnotation 记录变更原因, Deployment的变更原因会随模板复制到ReplicaSet, 在历史版本中展示
+const ChangeCauseAnnotation = "kubernetes.io/change-cause"
+
+// 支持滚动更新的工作负载类型
+const (
+	KindDeployment  = "deployment"
+	KindStatefulSet = "statefulset"
+	KindDaemonSet   = "daemonset"
+)
+
+// SetImages 修改工作负载中容器的镜像并记录变更原因, 容器不存在时不做任何修改
+func SetImages(client kubernetes.Interface, data k8s.RolloutImage) error {
+	changeCause := data.ChangeCause
+	if changeCause == "" {
+		images := make([]string, 0, len(data.Images))
+		for _, image := range data.Images {
+			images = append(images, image.Container+"="+image.Image)
+		}
+		changeCause = "更新镜像: " + strings.Join(images, ", ")
+	}
+	common.LOG.Info(fmt.Sprintf("%s: %v, namespace: %v, %s", data.Kind, data.Name, data.Namespace, changeCause))
+
+	ctx := context.TODO()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		switch strings.ToLower(data.Kind) {
+		case KindDeployment:
+			obj, err := client.AppsV1().Deployments(data.Namespace).Get(ctx, data.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if err = setTemplateImages(&obj.Spec.Template, data.Images); err != nil {
+				return err
+			}
+			setChangeCause(&obj.ObjectMeta, changeCause)
+			_, err = client.AppsV1().Deployments(data.Namespace).Update(ctx, obj, metav1.UpdateOptions{})
+			return err
+		case KindStatefulSet:
+			obj, err := client.AppsV1().StatefulSets(data.Namespace).Get(ctx, data.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if err = setTemplateImages(&obj.Spec.Template, data.Images); err != nil {
+				return err
+			}
+			setChangeCause(&obj.ObjectMeta, changeCause)
+			_, err = client.AppsV1().StatefulSets(data.Namespace).Update(ctx, obj, metav1.UpdateOptions{})
+			return err
+		case KindDaemonSet:
+			obj, err := client.AppsV1().DaemonSets(data.Namespace).Get(ctx, data.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if err = setTemplateImages(&obj.Spec.Template, data.Images); err != nil {
+				return err
+			}
+			setChangeCause(&obj.ObjectMeta, changeCause)
+			_, err = client.AppsV1().DaemonSets(data.Namespace).Update(ctx, obj, metav1.UpdateOptions{})
+			return err
+		}
+		return unsupportedKindErr(data.Kind)
+	})
+}
+
+// setTemplateImages 按容器名修改镜像, 同时查找初始化容器
+func setTemplateImages(template *v1.PodTemplateSpec, images []k8s.ContainerImage) error {
+	for _, image := range images {
+		if !setContainerImage(template.Spec.Containers, image) && !setContainerImage(template.Spec.InitContainers, image) {
+			return fmt.Errorf("容器%s不存在", image.Container)
+		}
+	}
+	return nil
+}
+
+func setContainerImage(containers []v1.Container, image k8s.ContainerImage) bool {
+	for i := range containers {
+		if containers[i].Name == image.Container {
+			containers[i].Image = image.Image
+			return true
+		}
+	}
+	return false
+}
+
+func setChangeCause(meta *metav1.ObjectMeta, changeCause string) {
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[ChangeCauseAnnotation] = changeCause
+}
+
+// PauseRollout 暂停Deployment的滚动更新, 暂停期间对模板的修改不会触发发布
+func PauseRollout(client kubernetes.Interface, kind, namespace, name string) error {
+	return setPaused(client, kind, namespace, name, true)
+}
+
+// ResumeRollout 恢复Deployment的滚动更新
+func ResumeRollout(client kubernetes.Interface, kind, namespace, name string) error {
+	return setPaused(client, kind, namespace, name, false)
+}
+
+func setPaused(client kubernetes.Interface, kind, namespace, name string, paused bool) error {
+	if strings.ToLower(kind) != KindDeployment {
+		return fmt.Errorf("%s不支持暂停和恢复发布, 仅支持Deployment", kind)
+	}
+	common.LOG.Info(fmt.Sprintf("设置Deployment: %v, namespace: %v 暂停发布: %v", name, namespace, paused))
+	deployment, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if deployment.Spec.Paused == paused {
+		if paused {
+			return fmt.Errorf("Deployment %s 已处于暂停状态", name)
+		}
+		return fmt.Errorf("Deployment %s 未暂停", name)
+	}
+	data := fmt.Sprintf(`{"spec":{"paused":%t}}`, paused)
+	_, err = client.AppsV1().Deployments(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType,
+		[]byte(data), metav1.PatchOptions{FieldManager: "kubectl-rollout"})
+	return err
+}
+
+func unsupportedKindErr(kind string) error {
+	return fmt.Errorf("不支持的工作负载类型: %s", kind)
+}
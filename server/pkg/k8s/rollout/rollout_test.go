@@ -0,0 +1,143 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+)
+
+func newDeployment() *apps.Deployment {
+	replicas := int32(2)
+	return &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+		Spec: apps.DeploymentSpec{
+			Replicas: &replicas,
+			Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "init", Image: "busybox:1.33"}},
+				Containers:     []v1.Container{{Name: "app", Image: "nginx:1.20"}, {Name: "sidecar", Image: "envoy:1.18"}},
+			}},
+		},
+		Status: apps.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+}
+
+func TestSetImages(t *testing.T) {
+	common.LOG = zap.NewNop()
+	client := fake.NewSimpleClientset(newDeployment())
+
+	err := SetImages(client, k8s.RolloutImage{
+		Kind: "Deployment", Namespace: "default", Name: "web",
+		Images: []k8s.ContainerImage{{Container: "app", Image: "nginx:1.21"}, {Container: "init", Image: "busybox:1.34"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := client.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if d.Spec.Template.Spec.Containers[0].Image != "nginx:1.21" || d.Spec.Template.Spec.InitContainers[0].Image != "busybox:1.34" {
+		t.Errorf("images not updated: %+v", d.Spec.Template.Spec)
+	}
+	if d.Spec.Template.Spec.Containers[1].Image != "envoy:1.18" {
+		t.Errorf("untouched container changed: %s", d.Spec.Template.Spec.Containers[1].Image)
+	}
+	if d.Annotations[ChangeCauseAnnotation] != "更新镜像: app=nginx:1.21, init=busybox:1.34" {
+		t.Errorf("change cause = %q", d.Annotations[ChangeCauseAnnotation])
+	}
+
+	err = SetImages(client, k8s.RolloutImage{
+		Kind: "deployment", Namespace: "default", Name: "web",
+		Images: []k8s.ContainerImage{{Container: "app", Image: "nginx:1.22"}, {Container: "missing", Image: "x"}},
+	})
+	if err == nil {
+		t.Fatal("expected error for missing container")
+	}
+	d, _ = client.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if d.Spec.Template.Spec.Containers[0].Image != "nginx:1.21" {
+		t.Errorf("deployment modified despite error: %s", d.Spec.Template.Spec.Containers[0].Image)
+	}
+}
+
+func TestWatchStatus(t *testing.T) {
+	statusInterval = 10 * time.Millisecond
+	client := fake.NewSimpleClientset(newDeployment())
+
+	var statuses []RolloutStatus
+	err := WatchStatus(context.TODO(), client, "Deployment", "default", "web", time.Second, func(status RolloutStatus) error {
+		statuses = append(statuses, status)
+		if len(statuses) == 1 {
+			d, _ := client.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+			d.Status.UpdatedReplicas, d.Status.AvailableReplicas = 2, 2
+			_, err := client.AppsV1().Deployments("default").UpdateStatus(context.TODO(), d, metav1.UpdateOptions{})
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0].Done || !statuses[1].Done {
+		t.Fatalf("unexpected statuses: %+v", statuses)
+	}
+}
+
+func TestWatchStatusProgressDeadline(t *testing.T) {
+	statusInterval, statusMargin = 10*time.Millisecond, 0
+	defer func() { statusMargin = time.Minute }()
+	deployment := newDeployment()
+	deadline := int32(1)
+	deployment.Spec.ProgressDeadlineSeconds = &deadline
+	client := fake.NewSimpleClientset(deployment)
+
+	// 未指定timeout时, Deployment在progressDeadlineSeconds内没有进展后结束, 有进展时重新计时
+	start := time.Now()
+	var statuses []RolloutStatus
+	err := WatchStatus(context.TODO(), client, "Deployment", "default", "web", 0, func(status RolloutStatus) error {
+		statuses = append(statuses, status)
+		if len(statuses) == 1 {
+			time.AfterFunc(700*time.Millisecond, func() {
+				d, _ := client.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+				d.Status.UpdatedReplicas = 2
+				_, _ = client.AppsV1().Deployments("default").UpdateStatus(context.TODO(), d, metav1.UpdateOptions{})
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 || statuses[2].Error == "" {
+		t.Fatalf("expected timeout after progress stalls: %+v", statuses)
+	}
+	if elapsed := time.Since(start); elapsed < 1500*time.Millisecond || elapsed > 5*time.Second {
+		t.Fatalf("unexpected watch duration %v", elapsed)
+	}
+}
+
+func TestPauseRolloutUnsupportedKind(t *testing.T) {
+	if err := PauseRollout(fake.NewSimpleClientset(), "statefulset", "default", "db"); err == nil {
+		t.Fatal("expected error for statefulset")
+	}
+}
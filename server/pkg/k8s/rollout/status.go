@@ -0,0 +1,174 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"fmt"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	"math"
+	"strings"
+	"time"
+)
+
+// defaultStatusTimeout StatefulSet和DaemonSet没有progressDeadlineSeconds, 跟踪超过该时间后结束
+const defaultStatusTimeout = 10 * time.Minute
+
+var (
+	// statusInterval 查询发布状态的间隔
+	statusInterval = time.Second
+	// statusMargin Deployment超过progressDeadlineSeconds后, 等待控制器更新Progressing条件的时间
+	statusMargin = time.Minute
+)
+
+// RolloutStatus 发布进度, Done为true或Error不为空时跟踪结束
+type RolloutStatus struct {
+	Message    string                     `json:"message"`
+	Done       bool                       `json:"done"`
+	Error      string                     `json:"error,omitempty"`
+	Conditions []apps.DeploymentCondition `json:"conditions,omitempty"`
+}
+
+// WatchStatus 跟踪工作负载的发布进度, 状态变化时调用send, 直到发布完成、失败或ctx取消.
+// 指定timeout时最长跟踪timeout. 未指定时, Deployment超过progressDeadlineSeconds没有进展会由控制器标记为失败,
+// 因此只在状态持续该时间没有变化后结束; 其他类型最长跟踪defaultStatusTimeout
+func WatchStatus(ctx context.Context, client kubernetes.Interface, kind, namespace, name string, timeout time.Duration,
+	send func(RolloutStatus) error) error {
+	kind = strings.ToLower(kind)
+	viewer, err := statusViewerFor(kind)
+	if err != nil {
+		return err
+	}
+	idle := false // 状态变化时重新计时
+	if timeout <= 0 {
+		timeout = defaultStatusTimeout
+		if kind == KindDeployment {
+			deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			timeout, idle = progressTimeout(deployment), true
+		}
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+	var last string
+	for {
+		status, err := getStatus(ctx, client, viewer, kind, namespace, name)
+		if err != nil {
+			return err
+		}
+		if key := fmt.Sprintf("%s|%t|%s|%d", status.Message, status.Done, status.Error, len(status.Conditions)); key != last {
+			last = key
+			if idle {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(timeout)
+			}
+			if err = send(status); err != nil {
+				return err
+			}
+		}
+		if status.Done || status.Error != "" {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			return send(RolloutStatus{Message: status.Message, Error: "跟踪发布状态超时"})
+		case <-ticker.C:
+		}
+	}
+}
+
+// progressTimeout Deployment没有进展时的最长跟踪时间. progressDeadlineSeconds未设置时Kubernetes默认为600秒,
+// 设置为MaxInt32时控制器不会判定失败, 使用defaultStatusTimeout
+func progressTimeout(deployment *apps.Deployment) time.Duration {
+	seconds := deployment.Spec.ProgressDeadlineSeconds
+	if seconds == nil {
+		return 600*time.Second + statusMargin
+	}
+	if *seconds == math.MaxInt32 {
+		return defaultStatusTimeout
+	}
+	return time.Duration(*seconds)*time.Second + statusMargin
+}
+
+func statusViewerFor(kind string) (polymorphichelpers.StatusViewer, error) {
+	switch kind {
+	case KindDeployment:
+		return polymorphichelpers.StatusViewerFor(apps.SchemeGroupVersion.WithKind("Deployment").GroupKind())
+	case KindStatefulSet:
+		return polymorphichelpers.StatusViewerFor(apps.SchemeGroupVersion.WithKind("StatefulSet").GroupKind())
+	case KindDaemonSet:
+		return polymorphichelpers.StatusViewerFor(apps.SchemeGroupVersion.WithKind("DaemonSet").GroupKind())
+	}
+	return nil, unsupportedKindErr(kind)
+}
+
+// getStatus 使用kubectl rollout status的判断逻辑计算当前进度
+func getStatus(ctx context.Context, client kubernetes.Interface, viewer polymorphichelpers.StatusViewer,
+	kind, namespace, name string) (RolloutStatus, error) {
+	var (
+		obj        runtime.Object
+		conditions []apps.DeploymentCondition
+		paused     bool
+		err        error
+	)
+	switch kind {
+	case KindDeployment:
+		var deployment *apps.Deployment
+		deployment, err = client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			obj, conditions, paused = deployment, deployment.Status.Conditions, deployment.Spec.Paused
+		}
+	case KindStatefulSet:
+		obj, err = client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case KindDaemonSet:
+		obj, err = client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return RolloutStatus{}, err
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return RolloutStatus{}, err
+	}
+	message, done, err := viewer.Status(&unstructured.Unstructured{Object: content}, 0)
+	status := RolloutStatus{Message: strings.TrimSpace(message), Done: done, Conditions: conditions}
+	if err != nil {
+		status.Error = err.Error()
+	} else if paused && !done {
+		status.Error = "Deployment已暂停发布, 恢复后才会继续"
+	}
+	return status, nil
+}
@@ -31,14 +31,18 @@ type SecretDetail struct {
 	// Extends list item structure.
 	Secret `json:",inline"`
 
+	// Keys maps every data key to the size of its value in bytes, values themselves are not exposed.
+	Keys map[string]int `json:"keys"`
+
 	// Data contains the secret data.  Each key must be a valid DNS_SUBDOMAIN
 	// or leading dot followed by valid DNS_SUBDOMAIN.
 	// The serialized form of the secret data is a base64 encoded string,
 	// representing the arbitrary (possibly non-string) data value here.
-	Data map[string][]byte `json:"data"`
+	// Only filled by RevealSecretDetail.
+	Data map[string][]byte `json:"data,omitempty"`
 }
 
-// GetSecretDetail returns detailed information about a secret
+// GetSecretDetail returns detailed information about a secret without its values
 func GetSecretDetail(client kubernetes.Interface, namespace, name string) (*SecretDetail, error) {
 	common.LOG.Info(fmt.Sprintf("Getting details of %s secret in %s namespace", name, namespace))
 
@@ -47,12 +51,32 @@ func GetSecretDetail(client kubernetes.Interface, namespace, name string) (*Secr
 		return nil, err
 	}
 
-	return getSecretDetail(rawSecret), nil
+	return getSecretDetail(rawSecret, false), nil
 }
 
-func getSecretDetail(rawSecret *v1.Secret) *SecretDetail {
-	return &SecretDetail{
+// RevealSecretDetail returns detailed information about a secret including its values.
+// The route serving it is guarded by its own casbin policy so only authorised roles can read values.
+func RevealSecretDetail(client kubernetes.Interface, namespace, name string) (*SecretDetail, error) {
+	common.LOG.Info(fmt.Sprintf("查看Secret明文: %v, namespace: %v", name, namespace))
+
+	rawSecret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return getSecretDetail(rawSecret, true), nil
+}
+
+func getSecretDetail(rawSecret *v1.Secret, reveal bool) *SecretDetail {
+	detail := &SecretDetail{
 		Secret: toSecret(rawSecret),
-		Data:   rawSecret.Data,
+		Keys:   make(map[string]int, len(rawSecret.Data)),
+	}
+	for k, v := range rawSecret.Data {
+		detail.Keys[k] = len(v)
+	}
+	if reveal {
+		detail.Data = rawSecret.Data
 	}
+	return detail
 }
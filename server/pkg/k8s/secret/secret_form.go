@@ -0,0 +1,255 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"strings"
+)
+
+// OpaqueSecretSpec is a specification of an Opaque secret with plain text values implements SecretSpec
+type OpaqueSecretSpec struct {
+	Name      string
+	Namespace string
+	Data      map[string]string
+}
+
+func (spec *OpaqueSecretSpec) GetName() string {
+	return spec.Name
+}
+
+func (spec *OpaqueSecretSpec) GetNamespace() string {
+	return spec.Namespace
+}
+
+func (spec *OpaqueSecretSpec) GetType() v1.SecretType {
+	return v1.SecretTypeOpaque
+}
+
+// GetData returns the values as bytes, they are base64 encoded when serialized
+func (spec *OpaqueSecretSpec) GetData() map[string][]byte {
+	data := make(map[string][]byte, len(spec.Data))
+	for k, v := range spec.Data {
+		data[k] = []byte(v)
+	}
+	return data
+}
+
+// TLSSecretSpec is a specification of a kubernetes.io/tls secret implements SecretSpec
+type TLSSecretSpec struct {
+	Name      string
+	Namespace string
+	Cert      string
+	Key       string
+}
+
+func (spec *TLSSecretSpec) GetName() string {
+	return spec.Name
+}
+
+func (spec *TLSSecretSpec) GetNamespace() string {
+	return spec.Namespace
+}
+
+func (spec *TLSSecretSpec) GetType() v1.SecretType {
+	return v1.SecretTypeTLS
+}
+
+func (spec *TLSSecretSpec) GetData() map[string][]byte {
+	return map[string][]byte{
+		v1.TLSCertKey:       []byte(spec.Cert),
+		v1.TLSPrivateKeyKey: []byte(spec.Key),
+	}
+}
+
+// DockerConfigJSONSecretSpec is a specification of a kubernetes.io/dockerconfigjson secret implements SecretSpec
+type DockerConfigJSONSecretSpec struct {
+	Name      string
+	Namespace string
+	Server    string
+	Username  string
+	Password  string
+	Email     string
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email,omitempty"`
+	Auth     string `json:"auth"`
+}
+
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+func (spec *DockerConfigJSONSecretSpec) GetName() string {
+	return spec.Name
+}
+
+func (spec *DockerConfigJSONSecretSpec) GetNamespace() string {
+	return spec.Namespace
+}
+
+func (spec *DockerConfigJSONSecretSpec) GetType() v1.SecretType {
+	return v1.SecretTypeDockerConfigJson
+}
+
+// GetData returns the .dockerconfigjson content, the same format kubectl create secret docker-registry produces
+func (spec *DockerConfigJSONSecretSpec) GetData() map[string][]byte {
+	config := dockerConfigJSON{Auths: map[string]dockerConfigEntry{
+		spec.Server: {
+			Username: spec.Username,
+			Password: spec.Password,
+			Email:    spec.Email,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(spec.Username + ":" + spec.Password)),
+		},
+	}}
+	content, _ := json.Marshal(config)
+	return map[string][]byte{v1.DockerConfigJsonKey: content}
+}
+
+// NewSecretSpec 校验表单并按类型生成SecretSpec, 错误信息可直接返回给前端
+func NewSecretSpec(form k8s.SecretForm) (SecretSpec, error) {
+	if errs := validation.IsDNS1123Subdomain(form.Name); len(errs) > 0 {
+		return nil, fmt.Errorf("Secret名称不合法: %s", strings.Join(errs, "; "))
+	}
+	if err := k8scommon.ValidateLabels(form.Labels); err != nil {
+		return nil, fmt.Errorf("labels不合法: %v", err)
+	}
+	switch v1.SecretType(form.Type) {
+	case "", v1.SecretTypeOpaque:
+		keys := make([]string, 0, len(form.Data))
+		for k := range form.Data {
+			keys = append(keys, k)
+		}
+		if err := k8scommon.ValidateDataKeys(keys); err != nil {
+			return nil, err
+		}
+		return &OpaqueSecretSpec{Name: form.Name, Namespace: form.Namespace, Data: form.Data}, nil
+	case v1.SecretTypeTLS:
+		if form.TLS == nil {
+			return nil, fmt.Errorf("TLS类型的Secret需要提供证书和私钥")
+		}
+		if _, err := tls.X509KeyPair([]byte(form.TLS.Cert), []byte(form.TLS.Key)); err != nil {
+			return nil, fmt.Errorf("证书或私钥不合法: %v", err)
+		}
+		return &TLSSecretSpec{Name: form.Name, Namespace: form.Namespace, Cert: form.TLS.Cert, Key: form.TLS.Key}, nil
+	case v1.SecretTypeDockerConfigJson:
+		if form.DockerConfig == nil {
+			return nil, fmt.Errorf("dockerconfigjson类型的Secret需要提供镜像仓库认证信息")
+		}
+		return &DockerConfigJSONSecretSpec{
+			Name:      form.Name,
+			Namespace: form.Namespace,
+			Server:    form.DockerConfig.Server,
+			Username:  form.DockerConfig.Username,
+			Password:  form.DockerConfig.Password,
+			Email:     form.DockerConfig.Email,
+		}, nil
+	default:
+		return nil, fmt.Errorf("不支持的Secret类型: %s", form.Type)
+	}
+}
+
+// CreateSecretFromForm 按表单创建Secret
+func CreateSecretFromForm(client kubernetes.Interface, form k8s.SecretForm) (*Secret, error) {
+	spec, err := NewSecretSpec(form)
+	if err != nil {
+		return nil, err
+	}
+	common.LOG.Info(fmt.Sprintf("创建Secret: %v, namespace: %v, type: %v", form.Name, form.Namespace, spec.GetType()))
+	secret := &v1.Secret{
+		ObjectMeta: metaV1.ObjectMeta{Name: spec.GetName(), Namespace: spec.GetNamespace(), Labels: form.Labels},
+		Type:       spec.GetType(),
+		Data:       spec.GetData(),
+	}
+	created, err := client.CoreV1().Secrets(form.Namespace).Create(context.TODO(), secret, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toSecret(created)
+	return &result, nil
+}
+
+// UpdateSecretFromForm 按表单更新Secret, Secret类型不可修改, 未传labels时保留原值.
+// Opaque类型只修改表单中填写的键并删除RemoveKeys中的键, 其余键保持不变; 其他类型整体替换data
+func UpdateSecretFromForm(client kubernetes.Interface, form k8s.SecretForm) (*Secret, error) {
+	spec, err := NewSecretSpec(form)
+	if err != nil {
+		return nil, err
+	}
+	common.LOG.Info(fmt.Sprintf("更新Secret: %v, namespace: %v", form.Name, form.Namespace))
+	secret, err := client.CoreV1().Secrets(form.Namespace).Get(context.TODO(), form.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if secret.Type != spec.GetType() {
+		return nil, fmt.Errorf("Secret类型为%s, 不能修改为%s", secret.Type, spec.GetType())
+	}
+	if secret.Immutable != nil && *secret.Immutable {
+		return nil, fmt.Errorf("Secret %s已设置为不可变, 不能修改", form.Name)
+	}
+	if form.Labels != nil {
+		secret.Labels = form.Labels
+	}
+	if secret.Type == v1.SecretTypeOpaque {
+		data, err := mergeSecretData(secret.Data, spec.GetData(), form.RemoveKeys)
+		if err != nil {
+			return nil, err
+		}
+		secret.Data = data
+	} else {
+		secret.Data = spec.GetData()
+	}
+	secret.StringData = nil
+	updated, err := client.CoreV1().Secrets(form.Namespace).Update(context.TODO(), secret, metaV1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := toSecret(updated)
+	return &result, nil
+}
+
+// mergeSecretData 在原有data上修改和删除键, 同一个键不能同时修改和删除
+func mergeSecretData(current, update map[string][]byte, remove []string) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(current)+len(update))
+	for k, v := range current {
+		data[k] = v
+	}
+	for k, v := range update {
+		data[k] = v
+	}
+	for _, k := range remove {
+		if _, ok := update[k]; ok {
+			return nil, fmt.Errorf("键%s不能同时修改和删除", k)
+		}
+		delete(data, k)
+	}
+	return data, nil
+}
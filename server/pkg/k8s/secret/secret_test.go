@@ -0,0 +1,124 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"encoding/json"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	"testing"
+)
+
+func TestDockerConfigJSONSecret(t *testing.T) {
+	common.LOG = zap.NewNop()
+	client := fake.NewSimpleClientset()
+	form := k8s.SecretForm{
+		Namespace: "default",
+		Name:      "registry",
+		Type:      string(v1.SecretTypeDockerConfigJson),
+		DockerConfig: &k8s.SecretDockerConfig{
+			Server:   "registry.example.com",
+			Username: "admin",
+			Password: "secret",
+		},
+	}
+	if _, err := CreateSecretFromForm(client, form); err != nil {
+		t.Fatal(err)
+	}
+
+	detail, err := GetSecretDetail(client, "default", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Data != nil || detail.Keys[v1.DockerConfigJsonKey] == 0 {
+		t.Fatalf("secret values should be masked: %+v", detail)
+	}
+
+	detail, err = RevealSecretDetail(client, "default", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config dockerConfigJSON
+	if err := json.Unmarshal(detail.Data[v1.DockerConfigJsonKey], &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Auths["registry.example.com"].Auth != "YWRtaW46c2VjcmV0" {
+		t.Fatalf("unexpected docker config: %+v", config)
+	}
+
+	form.Type = string(v1.SecretTypeOpaque)
+	if _, err := UpdateSecretFromForm(client, form); err == nil {
+		t.Fatal("expected error when changing secret type")
+	}
+}
+
+func TestNewSecretSpecValidation(t *testing.T) {
+	if _, err := NewSecretSpec(k8s.SecretForm{Name: "tls", Type: string(v1.SecretTypeTLS), TLS: &k8s.SecretTLS{Cert: "x", Key: "y"}}); err == nil {
+		t.Fatal("expected error for invalid certificate")
+	}
+	if _, err := NewSecretSpec(k8s.SecretForm{Name: "opaque", Data: map[string]string{"bad key": "v"}}); err == nil {
+		t.Fatal("expected error for invalid key")
+	}
+	if _, err := NewSecretSpec(k8s.SecretForm{Name: "Bad_Name"}); err == nil {
+		t.Fatal("expected error for invalid name")
+	}
+}
+
+func TestUpdateOpaqueSecretKeepsKeys(t *testing.T) {
+	common.LOG = zap.NewNop()
+	client := fake.NewSimpleClientset()
+	form := k8s.SecretForm{
+		Namespace: "default",
+		Name:      "app",
+		Data:      map[string]string{"user": "admin", "password": "secret", "token": "abc"},
+	}
+	if _, err := CreateSecretFromForm(client, form); err != nil {
+		t.Fatal(err)
+	}
+
+	// 只修改password并删除token, 未提及的user保持不变
+	form.Data = map[string]string{"password": "changed"}
+	form.RemoveKeys = []string{"token"}
+	if _, err := UpdateSecretFromForm(client, form); err != nil {
+		t.Fatal(err)
+	}
+	detail, err := RevealSecretDetail(client, "default", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Data) != 2 || string(detail.Data["user"]) != "admin" || string(detail.Data["password"]) != "changed" {
+		t.Fatalf("unexpected data after update: %v", detail.Data)
+	}
+
+	// 没有修改的键时不清空data
+	form.Data, form.RemoveKeys = nil, nil
+	if _, err := UpdateSecretFromForm(client, form); err != nil {
+		t.Fatal(err)
+	}
+	if detail, _ = RevealSecretDetail(client, "default", "app"); len(detail.Data) != 2 {
+		t.Fatalf("empty update should keep data: %v", detail.Data)
+	}
+
+	form.Data = map[string]string{"user": "root"}
+	form.RemoveKeys = []string{"user"}
+	if _, err := UpdateSecretFromForm(client, form); err == nil {
+		t.Fatal("expected error when updating and removing the same key")
+	}
+}
@@ -0,0 +1,213 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"strconv"
+	"strings"
+)
+
+var serviceTypes = map[v1.ServiceType]bool{
+	v1.ServiceTypeClusterIP:    true,
+	v1.ServiceTypeNodePort:     true,
+	v1.ServiceTypeLoadBalancer: true,
+	v1.ServiceTypeExternalName: true,
+}
+
+var serviceProtocols = map[v1.Protocol]bool{
+	v1.ProtocolTCP:  true,
+	v1.ProtocolUDP:  true,
+	v1.ProtocolSCTP: true,
+}
+
+// ValidateServiceForm 校验Service表单, 错误信息可直接返回给前端
+func ValidateServiceForm(form k8s.ServiceForm) error {
+	if errs := validation.IsDNS1035Label(form.Name); len(errs) > 0 {
+		return fmt.Errorf("Service名称不合法: %s", strings.Join(errs, "; "))
+	}
+	serviceType := serviceType(form)
+	if !serviceTypes[serviceType] {
+		return fmt.Errorf("不支持的Service类型: %s", form.Type)
+	}
+	if err := k8scommon.ValidateLabels(form.Selector); err != nil {
+		return fmt.Errorf("selector不合法: %v", err)
+	}
+	if err := k8scommon.ValidateLabels(form.Labels); err != nil {
+		return fmt.Errorf("labels不合法: %v", err)
+	}
+	if serviceType == v1.ServiceTypeExternalName {
+		if errs := validation.IsDNS1123Subdomain(form.ExternalName); len(errs) > 0 {
+			return fmt.Errorf("externalName不合法: %s", strings.Join(errs, "; "))
+		}
+		if form.Headless {
+			return fmt.Errorf("ExternalName类型的Service不能设置为Headless")
+		}
+	} else if len(form.Ports) == 0 && !form.Headless {
+		return fmt.Errorf("至少需要配置一个端口")
+	}
+	names := map[string]bool{}
+	for i, port := range form.Ports {
+		if len(form.Ports) > 1 && port.Name == "" {
+			return fmt.Errorf("配置多个端口时第%d个端口必须设置名称", i+1)
+		}
+		if port.Name != "" {
+			if errs := validation.IsValidPortName(port.Name); len(errs) > 0 {
+				return fmt.Errorf("端口名称%q不合法: %s", port.Name, strings.Join(errs, "; "))
+			}
+			if names[port.Name] {
+				return fmt.Errorf("端口名称%q重复", port.Name)
+			}
+			names[port.Name] = true
+		}
+		if port.Protocol != "" && !serviceProtocols[v1.Protocol(port.Protocol)] {
+			return fmt.Errorf("不支持的协议: %s", port.Protocol)
+		}
+		if errs := validation.IsValidPortNum(int(port.Port)); len(errs) > 0 {
+			return fmt.Errorf("端口%d不合法: %s", port.Port, strings.Join(errs, "; "))
+		}
+		if port.TargetPort != "" {
+			if n, err := strconv.Atoi(port.TargetPort); err == nil {
+				if errs := validation.IsValidPortNum(n); len(errs) > 0 {
+					return fmt.Errorf("目标端口%d不合法: %s", n, strings.Join(errs, "; "))
+				}
+			} else if errs := validation.IsValidPortName(port.TargetPort); len(errs) > 0 {
+				return fmt.Errorf("目标端口名称%q不合法: %s", port.TargetPort, strings.Join(errs, "; "))
+			}
+		}
+		if port.NodePort != 0 {
+			if serviceType != v1.ServiceTypeNodePort && serviceType != v1.ServiceTypeLoadBalancer {
+				return fmt.Errorf("只有NodePort和LoadBalancer类型的Service可以指定nodePort")
+			}
+			if errs := validation.IsValidPortNum(int(port.NodePort)); len(errs) > 0 {
+				return fmt.Errorf("nodePort %d不合法: %s", port.NodePort, strings.Join(errs, "; "))
+			}
+		}
+	}
+	return nil
+}
+
+func serviceType(form k8s.ServiceForm) v1.ServiceType {
+	if form.Type == "" {
+		return v1.ServiceTypeClusterIP
+	}
+	return v1.ServiceType(form.Type)
+}
+
+func toServicePorts(ports []k8s.ServicePort) []v1.ServicePort {
+	result := make([]v1.ServicePort, 0, len(ports))
+	for _, port := range ports {
+		protocol := v1.Protocol(port.Protocol)
+		if protocol == "" {
+			protocol = v1.ProtocolTCP
+		}
+		targetPort := intstr.FromInt(int(port.Port))
+		if port.TargetPort != "" {
+			targetPort = intstr.Parse(port.TargetPort)
+		}
+		result = append(result, v1.ServicePort{
+			Name:       port.Name,
+			Protocol:   protocol,
+			Port:       port.Port,
+			TargetPort: targetPort,
+			NodePort:   port.NodePort,
+		})
+	}
+	return result
+}
+
+// applyServiceForm 将表单写入Service, 更新时保留已分配的clusterIP, 未传labels或annotations时保留原值
+func applyServiceForm(svc *v1.Service, form k8s.ServiceForm) {
+	if form.Labels != nil {
+		svc.Labels = form.Labels
+	}
+	if form.Annotations != nil {
+		svc.Annotations = form.Annotations
+	}
+	svc.Spec.Type = serviceType(form)
+	svc.Spec.Selector = form.Selector
+	svc.Spec.Ports = keepNodePorts(svc, toServicePorts(form.Ports))
+	svc.Spec.ExternalName = ""
+	if svc.Spec.Type == v1.ServiceTypeExternalName {
+		svc.Spec.ExternalName = form.ExternalName
+		svc.Spec.ClusterIP = ""
+		svc.Spec.ClusterIPs = nil
+	} else if svc.Spec.ClusterIP == "" && form.Headless {
+		svc.Spec.ClusterIP = v1.ClusterIPNone
+	}
+	if svc.Spec.Type != v1.ServiceTypeNodePort && svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+		svc.Spec.ExternalTrafficPolicy = ""
+	}
+}
+
+// keepNodePorts 未指定nodePort时沿用原端口已分配的nodePort, 避免编辑后端口被重新分配
+func keepNodePorts(svc *v1.Service, ports []v1.ServicePort) []v1.ServicePort {
+	if svc.Spec.Type != v1.ServiceTypeNodePort && svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+		return ports
+	}
+	allocated := map[int32]int32{}
+	for _, port := range svc.Spec.Ports {
+		allocated[port.Port] = port.NodePort
+	}
+	for i := range ports {
+		if ports[i].NodePort == 0 {
+			ports[i].NodePort = allocated[ports[i].Port]
+		}
+	}
+	return ports
+}
+
+// CreateService 按表单创建Service
+func CreateService(client kubernetes.Interface, form k8s.ServiceForm) (*Service, error) {
+	common.LOG.Info(fmt.Sprintf("创建Service: %v, namespace: %v", form.Name, form.Namespace))
+	svc := &v1.Service{ObjectMeta: metaV1.ObjectMeta{Name: form.Name, Namespace: form.Namespace}}
+	applyServiceForm(svc, form)
+	created, err := client.CoreV1().Services(form.Namespace).Create(context.TODO(), svc, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := ToService(created)
+	return &result, nil
+}
+
+// UpdateService 按表单更新Service的类型、端口、selector和标签
+func UpdateService(client kubernetes.Interface, form k8s.ServiceForm) (*Service, error) {
+	common.LOG.Info(fmt.Sprintf("更新Service: %v, namespace: %v", form.Name, form.Namespace))
+	svc, err := client.CoreV1().Services(form.Namespace).Get(context.TODO(), form.Name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if svc.Spec.ClusterIP == v1.ClusterIPNone && serviceType(form) != v1.ServiceTypeClusterIP {
+		return nil, fmt.Errorf("Headless Service不能修改为%s类型", serviceType(form))
+	}
+	applyServiceForm(svc, form)
+	updated, err := client.CoreV1().Services(form.Namespace).Update(context.TODO(), svc, metaV1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := ToService(updated)
+	return &result, nil
+}
@@ -0,0 +1,108 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceaccount
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"kubespace/server/common"
+	"time"
+)
+
+// tokenWaitTimeout 等待token-controller填充Secret的超时时间
+var tokenWaitTimeout = 10 * time.Second
+
+// GetKubeConfig 生成ServiceAccount的kubeconfig, 权限仅限于该ServiceAccount绑定的角色, 默认命名空间为其所在命名空间
+func GetKubeConfig(client kubernetes.Interface, restConfig *rest.Config, clusterName, namespace, name string) (string, error) {
+	sa, err := client.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	secret, err := ensureTokenSecret(client, sa)
+	if err != nil {
+		return "", err
+	}
+
+	caData := secret.Data[v1.ServiceAccountRootCAKey]
+	if len(caData) == 0 {
+		caData = restConfig.CAData
+	}
+	user := namespace + "-" + name
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = &clientcmdapi.Cluster{
+		Server:                   restConfig.Host,
+		CertificateAuthorityData: caData,
+		InsecureSkipTLSVerify:    len(caData) == 0 && restConfig.Insecure,
+	}
+	config.AuthInfos[user] = &clientcmdapi.AuthInfo{Token: string(secret.Data[v1.ServiceAccountTokenKey])}
+	config.Contexts[user] = &clientcmdapi.Context{Cluster: clusterName, AuthInfo: user, Namespace: namespace}
+	config.CurrentContext = user
+
+	content, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// ensureTokenSecret 获取ServiceAccount的token Secret, 不存在时创建并等待token-controller填充token
+func ensureTokenSecret(client kubernetes.Interface, sa *v1.ServiceAccount) (*v1.Secret, error) {
+	for _, ref := range sa.Secrets {
+		secret, err := client.CoreV1().Secrets(sa.Namespace).Get(context.TODO(), ref.Name, metaV1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		if secret.Type == v1.SecretTypeServiceAccountToken && len(secret.Data[v1.ServiceAccountTokenKey]) > 0 {
+			return secret, nil
+		}
+	}
+
+	secretName := sa.Name + "-kubeconfig-token"
+	secret := &v1.Secret{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:        secretName,
+			Namespace:   sa.Namespace,
+			Annotations: map[string]string{v1.ServiceAccountNameKey: sa.Name},
+		},
+		Type: v1.SecretTypeServiceAccountToken,
+	}
+	common.LOG.Info(fmt.Sprintf("为ServiceAccount: %v 创建token Secret: %v", sa.Name, secretName))
+	if _, err := client.CoreV1().Secrets(sa.Namespace).Create(context.TODO(), secret, metaV1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+
+	err := wait.PollImmediate(500*time.Millisecond, tokenWaitTimeout, func() (bool, error) {
+		secret, err := client.CoreV1().Secrets(sa.Namespace).Get(context.TODO(), secretName, metaV1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return len(secret.Data[v1.ServiceAccountTokenKey]) > 0, nil
+	})
+	if err != nil {
+		return nil, errors.New("等待ServiceAccount token生成超时")
+	}
+	return client.CoreV1().Secrets(sa.Namespace).Get(context.TODO(), secretName, metaV1.GetOptions{})
+}
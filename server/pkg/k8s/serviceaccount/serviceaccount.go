@@ -0,0 +1,150 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceaccount
+
+import (
+	"context"
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"kubespace/server/common"
+	"kubespace/server/models/k8s"
+	k8scommon "kubespace/server/pkg/k8s/common"
+	"kubespace/server/pkg/k8s/dataselect"
+	k8srbac "kubespace/server/pkg/k8s/rbac"
+)
+
+// ServiceAccountList contains a list of service accounts.
+type ServiceAccountList struct {
+	ListMeta k8s.ListMeta `json:"listMeta"`
+
+	// Unordered list of ServiceAccounts.
+	Items []ServiceAccount `json:"items"`
+}
+
+// ServiceAccount contains an information about single service account in the list.
+type ServiceAccount struct {
+	ObjectMeta k8s.ObjectMeta `json:"objectMeta"`
+	TypeMeta   k8s.TypeMeta   `json:"typeMeta"`
+}
+
+// ServiceAccountDetail contains detailed information about a service account.
+type ServiceAccountDetail struct {
+	// Extends list item structure.
+	ServiceAccount `json:",inline"`
+
+	Secrets          []v1.ObjectReference      `json:"secrets"`
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets"`
+
+	// Permissions 该ServiceAccount通过绑定获得的有效权限
+	Permissions *k8srbac.SubjectPermissions `json:"permissions"`
+}
+
+func toServiceAccount(sa *v1.ServiceAccount) ServiceAccount {
+	return ServiceAccount{
+		ObjectMeta: k8s.NewObjectMeta(sa.ObjectMeta),
+		TypeMeta:   k8s.NewTypeMeta(k8s.ResourceKindServiceAccount),
+	}
+}
+
+// GetServiceAccountList lists service accounts from given namespace using given data select query.
+func GetServiceAccountList(client kubernetes.Interface, nsQuery *k8scommon.NamespaceQuery, dsQuery *dataselect.DataSelectQuery) (*ServiceAccountList, error) {
+	common.LOG.Info(fmt.Sprintf("Getting list of service accounts in the namespace %s", nsQuery.ToRequestParam()))
+	list, err := client.CoreV1().ServiceAccounts(nsQuery.ToRequestParam()).List(context.TODO(), k8s.ListEverything)
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredItems []v1.ServiceAccount
+	for _, item := range list.Items {
+		if nsQuery.Matches(item.ObjectMeta.Namespace) {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+
+	result := &ServiceAccountList{Items: make([]ServiceAccount, 0)}
+	saCells, filteredTotal := dataselect.GenericDataSelectWithFilter(toCells(filteredItems), dsQuery)
+	serviceAccounts := fromCells(saCells)
+	result.ListMeta = k8s.ListMeta{TotalItems: filteredTotal}
+	for i := range serviceAccounts {
+		result.Items = append(result.Items, toServiceAccount(&serviceAccounts[i]))
+	}
+	return result, nil
+}
+
+// GetServiceAccountDetail returns detailed information about a service account, including effective permissions.
+func GetServiceAccountDetail(client kubernetes.Interface, namespace, name string) (*ServiceAccountDetail, error) {
+	sa, err := client.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := k8srbac.GetSubjectPermissions(client, rbac.Subject{
+		Kind:      rbac.ServiceAccountKind,
+		Name:      name,
+		Namespace: namespace,
+	}, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceAccountDetail{
+		ServiceAccount:   toServiceAccount(sa),
+		Secrets:          sa.Secrets,
+		ImagePullSecrets: sa.ImagePullSecrets,
+		Permissions:      permissions,
+	}, nil
+}
+
+// CreateServiceAccount 创建ServiceAccount, 指定了角色时在同一命名空间创建RoleBinding
+func CreateServiceAccount(client kubernetes.Interface, data k8s.ServiceAccountData) (*ServiceAccount, error) {
+	common.LOG.Info(fmt.Sprintf("创建ServiceAccount: %v, namespace: %v", data.Name, data.Namespace))
+	sa := &v1.ServiceAccount{
+		ObjectMeta: metaV1.ObjectMeta{Name: data.Name, Namespace: data.Namespace},
+	}
+	created, err := client.CoreV1().ServiceAccounts(data.Namespace).Create(context.TODO(), sa, metaV1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if data.RoleName != "" {
+		roleKind := data.RoleKind
+		if roleKind == "" {
+			roleKind = "ClusterRole"
+		}
+		binding := &rbac.RoleBinding{
+			ObjectMeta: metaV1.ObjectMeta{Name: data.Name + "-" + data.RoleName, Namespace: data.Namespace},
+			RoleRef:    rbac.RoleRef{APIGroup: rbac.GroupName, Kind: roleKind, Name: data.RoleName},
+			Subjects: []rbac.Subject{{
+				Kind:      rbac.ServiceAccountKind,
+				Name:      data.Name,
+				Namespace: data.Namespace,
+			}},
+		}
+		if _, err = client.RbacV1().RoleBindings(data.Namespace).Create(context.TODO(), binding, metaV1.CreateOptions{}); err != nil {
+			return nil, fmt.Errorf("ServiceAccount已创建, 绑定角色失败: %v", err)
+		}
+	}
+	result := toServiceAccount(created)
+	return &result, nil
+}
+
+// DeleteServiceAccount 删除ServiceAccount
+func DeleteServiceAccount(client kubernetes.Interface, namespace, name string) error {
+	common.LOG.Info(fmt.Sprintf("请求删除ServiceAccount: %v, namespace: %v", name, namespace))
+	return client.CoreV1().ServiceAccounts(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+}
@@ -24,6 +24,7 @@ import (
 	k8scommon "kubespace/server/pkg/k8s/common"
 	"kubespace/server/pkg/k8s/dataselect"
 	"kubespace/server/pkg/k8s/event"
+	"kubespace/server/pkg/k8s/horizontalpodautoscaler"
 	"go.uber.org/zap"
 	apps "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
@@ -211,6 +212,10 @@ func ScaleStatefulSet(client *kubernetes.Clientset, ns string, name string, scal
 
 	common.LOG.Info(fmt.Sprintf("start scale of %v statefulset in %v namespace", name, ns))
 
+	if err = horizontalpodautoscaler.CheckManualScale(client, ns, "StatefulSet", name); err != nil {
+		return err
+	}
+
 	scaleData, err := client.AppsV1().StatefulSets(ns).GetScale(
 		context.TODO(),
 		name,
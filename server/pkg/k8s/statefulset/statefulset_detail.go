@@ -26,6 +26,7 @@ import (
 	k8scommon "kubespace/server/pkg/k8s/common"
 	"kubespace/server/pkg/k8s/dataselect"
 	"kubespace/server/pkg/k8s/event"
+	"kubespace/server/pkg/k8s/horizontalpodautoscaler"
 	"kubespace/server/pkg/k8s/service"
 )
 
@@ -39,6 +40,9 @@ type StatefulSetDetail struct {
 	PodList *PodList `json:"podList"`
 
 	SvcList *service.ServiceList `json:"svcList"`
+
+	// HPAList 以该StatefulSet为伸缩目标的HPA, 包含当前和期望的指标
+	HPAList []horizontalpodautoscaler.HorizontalPodAutoscaler `json:"hpaList"`
 }
 
 // GetStatefulSetDetail gets Stateful Set details.
@@ -61,6 +65,7 @@ func GetStatefulSetDetail(client *kubernetes.Clientset, dsQuery *dataselect.Data
 
 	serviceList, _ := service.GetToService(client, namespace, name)
 	ssDetail := getStatefulSetDetail(ss, podInfo, events, serviceList, client)
+	ssDetail.HPAList, _ = horizontalpodautoscaler.GetTargetHorizontalPodAutoscalers(client, namespace, "StatefulSet", name)
 	return &ssDetail, nil
 }
 
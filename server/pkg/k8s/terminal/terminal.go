@@ -17,15 +17,22 @@ limitations under the License.
 package terminal
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"io"
+	"kubespace/server/common"
+	"kubespace/server/models"
+	"kubespace/server/pkg/asciicast2"
+	"kubespace/server/pkg/utils"
 	"log"
-	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/igm/sockjs-go.v2/sockjs"
 	v1 "k8s.io/api/core/v1"
@@ -37,6 +44,9 @@ import (
 
 const END_OF_TRANSMISSION = "\u0004"
 
+// bindTimeout 创建会话后等待前端建立SockJS连接的最长时间
+const bindTimeout = 30 * time.Second
+
 // PtyHandler is what remotecommand expects from a pty
 type PtyHandler interface {
 	io.Reader
@@ -51,6 +61,100 @@ type TerminalSession struct {
 	sockJSSession sockjs.Session
 	sizeChan      chan remotecommand.TerminalSize
 	doneChan      chan struct{}
+	recorder      *sessionRecorder
+	owner         uint // 创建会话的平台用户Id, 只有该用户可以绑定
+}
+
+// TerminalMeta 终端会话的元信息
+type TerminalMeta struct {
+	ClusterId uint
+	Namespace string
+	Pod       string
+	Container string
+	Shell     string
+	UserId    uint
+	UserName  string
+}
+
+type recordData struct {
+	Event string
+	Time  float64
+	Data  []byte
+}
+
+// sessionRecorder 记录终端输出, 进程退出后以asciicast v2格式写入数据库
+type sessionRecorder struct {
+	sync.Mutex
+	meta      TerminalMeta
+	connectId string
+	width     int
+	height    int
+	createdAt time.Time
+	records   []*recordData
+	written   bool
+}
+
+func (r *sessionRecorder) record(event string, p []byte) {
+	r.Lock()
+	defer r.Unlock()
+	var data = make([]byte, len(p))
+	copy(data, p)
+	r.records = append(r.records, &recordData{
+		Time:  time.Since(r.createdAt).Seconds(),
+		Event: event,
+		Data:  data,
+	})
+}
+
+func (r *sessionRecorder) resize(width, height int) {
+	r.Lock()
+	defer r.Unlock()
+	r.width = width
+	r.height = height
+}
+
+func (r *sessionRecorder) setShell(shell string) {
+	r.Lock()
+	defer r.Unlock()
+	r.meta.Shell = shell
+}
+
+// Write2Log 将操作记录写入数据库, 一个会话只写入一次
+func (r *sessionRecorder) Write2Log() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.written || len(r.records) == 0 {
+		return nil
+	}
+	b := new(bytes.Buffer)
+	meta := asciicast2.CastV2Header{
+		Width:     r.width,
+		Height:    r.height,
+		Timestamp: r.createdAt.Unix(),
+		Title:     r.connectId,
+		Env: &map[string]string{
+			"SHELL": r.meta.Shell, "TERM": "xterm",
+		},
+	}
+	cast, buffer := asciicast2.NewCastV2(meta, b)
+	for _, v := range r.records {
+		cast.Record(v.Time, v.Data, v.Event)
+	}
+	record := models.K8STerminalRecord{
+		ConnectID:   r.connectId,
+		ClusterId:   r.meta.ClusterId,
+		Namespace:   r.meta.Namespace,
+		Pod:         r.meta.Pod,
+		Container:   r.meta.Container,
+		Shell:       r.meta.Shell,
+		UserName:    r.meta.UserName,
+		ConnectTime: models.LocalTime{Time: r.createdAt},
+		LogoutTime:  models.LocalTime{Time: time.Now()},
+		Records:     utils.ZlibCompress(buffer.Bytes()),
+	}
+	r.written = true
+	return common.DB.Create(&record).Error
 }
 
 // TerminalMessage is the messaging protocol between ShellController and TerminalSession.
@@ -96,6 +200,9 @@ func (t TerminalSession) Read(p []byte) (int, error) {
 	case "stdin":
 		return copy(p, msg.Data), nil
 	case "resize":
+		if t.recorder != nil {
+			t.recorder.resize(int(msg.Cols), int(msg.Rows))
+		}
 		t.sizeChan <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
 		return 0, nil
 	default:
@@ -117,6 +224,9 @@ func (t TerminalSession) Write(p []byte) (int, error) {
 	if err = t.sockJSSession.Send(string(msg)); err != nil {
 		return 0, err
 	}
+	if t.recorder != nil {
+		t.recorder.record("o", p)
+	}
 	return len(p), nil
 }
 
@@ -163,9 +273,11 @@ func (sm *SessionMap) Set(sessionId string, session TerminalSession) {
 func (sm *SessionMap) Close(sessionId string, status uint32, reason string) {
 	sm.Lock.Lock()
 	defer sm.Lock.Unlock()
-	err := sm.Sessions[sessionId].sockJSSession.Close(status, reason)
-	if err != nil {
-		log.Println(err)
+	// 超时未绑定的会话没有SockJS连接
+	if session := sm.Sessions[sessionId].sockJSSession; session != nil {
+		if err := session.Close(status, reason); err != nil {
+			log.Println(err)
+		}
 	}
 
 	delete(sm.Sessions, sessionId)
@@ -176,12 +288,18 @@ var terminalSessions = SessionMap{Sessions: make(map[string]TerminalSession)}
 // handleTerminalSession is Called by net/http for any new /api/sockjs connections
 func handleTerminalSession(session sockjs.Session) {
 	var (
-		buf             string
-		err             error
-		msg             TerminalMessage
-		terminalSession TerminalSession
+		buf string
+		err error
+		msg TerminalMessage
 	)
 
+	owner, ok := takeSockJSOwner(session.ID())
+	if !ok {
+		log.Printf("handleTerminalSession: unauthenticated sockjs session '%s'", session.ID())
+		_ = session.Close(2, "未登录或非法访问")
+		return
+	}
+
 	if buf, err = session.Recv(); err != nil {
 		log.Printf("handleTerminalSession: can't Recv: %v", err)
 		return
@@ -197,19 +315,93 @@ func handleTerminalSession(session sockjs.Session) {
 		return
 	}
 
-	if terminalSession = terminalSessions.Get(msg.SessionID); terminalSession.id == "" {
-		log.Printf("handleTerminalSession: can't find session '%s'", msg.SessionID)
+	if err = terminalSessions.bind(msg.SessionID, owner, session); err != nil {
+		log.Printf("handleTerminalSession: bind session '%s' failed: %v", msg.SessionID, err)
+		_ = session.Close(2, err.Error())
 		return
 	}
+}
 
+// bind 将SockJS连接绑定到终端会话, 一个会话只能由创建者绑定一次
+func (sm *SessionMap) bind(sessionId string, owner uint, session sockjs.Session) error {
+	sm.Lock.Lock()
+	defer sm.Lock.Unlock()
+	terminalSession, ok := sm.Sessions[sessionId]
+	if !ok {
+		return errors.New("会话不存在或已超时")
+	}
+	if terminalSession.owner != owner {
+		return errors.New("无权绑定该会话")
+	}
+	if terminalSession.sockJSSession != nil {
+		return errors.New("会话已绑定")
+	}
 	terminalSession.sockJSSession = session
-	terminalSessions.Set(msg.SessionID, terminalSession)
-	terminalSession.bound <- nil
+	sm.Sessions[sessionId] = terminalSession
+	// bound有1个缓冲且只会发送一次, 不会阻塞
+	select {
+	case terminalSession.bound <- nil:
+	default:
+	}
+	return nil
+}
+
+// sockJSOwners 记录SockJS会话由哪个平台用户建立, handleTerminalSession中无法获取HTTP请求, 由CreateAttachHandler在请求经过鉴权后记录
+var sockJSOwners = struct {
+	sync.Mutex
+	owners map[string]sockJSOwner
+}{owners: make(map[string]sockJSOwner)}
+
+type sockJSOwner struct {
+	userId    uint
+	createdAt time.Time
+}
+
+// setSockJSOwner 只记录第一个请求的用户, 同一SockJS会话的后续请求不能改变归属
+func setSockJSOwner(sockJSId string, userId uint) {
+	sockJSOwners.Lock()
+	defer sockJSOwners.Unlock()
+	now := time.Now()
+	for id, o := range sockJSOwners.owners {
+		// 未建立会话的记录超时清理
+		if now.Sub(o.createdAt) > bindTimeout {
+			delete(sockJSOwners.owners, id)
+		}
+	}
+	if _, ok := sockJSOwners.owners[sockJSId]; !ok {
+		sockJSOwners.owners[sockJSId] = sockJSOwner{userId: userId, createdAt: now}
+	}
+}
+
+func takeSockJSOwner(sockJSId string) (uint, bool) {
+	sockJSOwners.Lock()
+	defer sockJSOwners.Unlock()
+	o, ok := sockJSOwners.owners[sockJSId]
+	delete(sockJSOwners.owners, sockJSId)
+	return o.userId, ok
+}
+
+// sockJSSessionId 从 /{server}/{session}/{transport} 格式的路径中获取SockJS会话Id
+func sockJSSessionId(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[1]
 }
 
 // CreateAttachHandler is called from main for /api/sockjs
-func CreateAttachHandler(path string) http.Handler {
-	return sockjs.NewHandler(path, sockjs.DefaultOptions, handleTerminalSession)
+// 请求需先经过AuthMiddleware, 以便记录SockJS会话所属的用户
+func CreateAttachHandler(path string) gin.HandlerFunc {
+	handler := sockjs.NewHandler(path, sockjs.DefaultOptions, handleTerminalSession)
+	return func(c *gin.Context) {
+		if id := sockJSSessionId(c.Param("path")); id != "" {
+			if claims, ok := c.Get("claims"); ok {
+				setSockJSOwner(id, claims.(*common.CustomClaims).ID)
+			}
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
 }
 
 // startProcess is called by handleAttach
@@ -267,6 +459,31 @@ func genTerminalSessionId() (string, error) {
 	return string(id), nil
 }
 
+// NewTerminalSession 创建一个待绑定的终端会话, 返回的ID由前端在SockJS连接建立后通过bind消息发送
+func NewTerminalSession(meta TerminalMeta) (string, error) {
+	sessionId, err := genTerminalSessionId()
+	if err != nil {
+		return "", err
+	}
+	terminalSessions.Set(sessionId, TerminalSession{
+		id: sessionId,
+		// 缓冲为1, 避免超时后handleTerminalSession阻塞
+		bound:    make(chan error, 1),
+		owner:    meta.UserId,
+		sizeChan: make(chan remotecommand.TerminalSize),
+		doneChan: make(chan struct{}),
+		recorder: &sessionRecorder{
+			meta:      meta,
+			connectId: sessionId,
+			width:     188,
+			height:    42,
+			createdAt: time.Now(),
+			records:   make([]*recordData, 0),
+		},
+	})
+	return sessionId, nil
+}
+
 // isValidShell checks if the shell is an allowed one
 func isValidShell(validShells []string, shell string) bool {
 	for _, validShell := range validShells {
@@ -281,22 +498,35 @@ func isValidShell(validShells []string, shell string) bool {
 // Waits for the SockJS connection to be opened by the client the session to be bound in handleTerminalSession
 func WaitForTerminal(k8sClient kubernetes.Interface, cfg *rest.Config, request *gin.Context, sessionId string) {
 	shell := request.Query("shell")
+	session := terminalSessions.Get(sessionId)
 
 	select {
-	case <-terminalSessions.Get(sessionId).bound:
-		close(terminalSessions.Get(sessionId).bound)
+	case <-time.After(bindTimeout):
+		common.LOG.Info(fmt.Sprintf("终端会话等待连接超时: %s", sessionId))
+		terminalSessions.Close(sessionId, 2, "等待连接超时")
+		return
+	case <-session.bound:
+		recorder := session.recorder
+		defer func() {
+			close(session.doneChan)
+			if err := recorder.Write2Log(); err != nil {
+				common.LOG.Error(fmt.Sprintf("终端操作记录写入失败: %v", err))
+			}
+		}()
 
 		var err error
 		validShells := []string{"bash", "sh", "powershell", "cmd"}
 
 		if isValidShell(validShells, shell) {
 			cmd := []string{shell}
+			recorder.setShell(shell)
 			err = startProcess(k8sClient, cfg, request, cmd, terminalSessions.Get(sessionId))
 		} else {
 			// No shell given or it was not valid: try some shells until one succeeds or all fail
 			// FIXME: if the first shell fails then the first keyboard event is lost
 			for _, testShell := range validShells {
 				cmd := []string{testShell}
+				recorder.setShell(testShell)
 				if err = startProcess(k8sClient, cfg, request, cmd, terminalSessions.Get(sessionId)); err == nil {
 					break
 				}
@@ -0,0 +1,97 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type fakeSockJS struct {
+	id     string
+	msgs   chan string
+	closed string
+}
+
+func (f *fakeSockJS) ID() string { return f.id }
+
+func (f *fakeSockJS) Recv() (string, error) { return <-f.msgs, nil }
+
+func (f *fakeSockJS) Send(string) error { return nil }
+
+func (f *fakeSockJS) Close(status uint32, reason string) error {
+	f.closed = reason
+	return nil
+}
+
+func bindSession(sockJSId string, userId uint, sessionId string) *fakeSockJS {
+	f := &fakeSockJS{id: sockJSId, msgs: make(chan string, 1)}
+	msg, _ := json.Marshal(TerminalMessage{Op: "bind", SessionID: sessionId})
+	f.msgs <- string(msg)
+	setSockJSOwner(sockJSId, userId)
+	handleTerminalSession(f)
+	return f
+}
+
+func TestBindTerminalSession(t *testing.T) {
+	sessionId, err := NewTerminalSession(TerminalMeta{UserId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f := bindSession("s0", 2, sessionId); f.closed == "" {
+		t.Error("bind by another user must be rejected")
+	}
+	first := bindSession("s1", 1, sessionId)
+	if first.closed != "" {
+		t.Fatalf("first bind rejected: %s", first.closed)
+	}
+	select {
+	case <-terminalSessions.Get(sessionId).bound:
+	case <-time.After(time.Second):
+		t.Fatal("session not bound")
+	}
+	// 重复绑定不能panic, 也不能替换已绑定的连接
+	if f := bindSession("s2", 1, sessionId); f.closed == "" {
+		t.Error("second bind must be rejected")
+	}
+	if terminalSessions.Get(sessionId).sockJSSession != first {
+		t.Error("bound connection replaced")
+	}
+	terminalSessions.Close(sessionId, 1, "")
+
+	// 超时后会话已被清理
+	sessionId, _ = NewTerminalSession(TerminalMeta{UserId: 1})
+	terminalSessions.Close(sessionId, 2, "等待连接超时")
+	if f := bindSession("s3", 1, sessionId); f.closed == "" {
+		t.Error("bind after timeout must be rejected")
+	}
+}
+
+func TestSockJSSessionId(t *testing.T) {
+	cases := map[string]string{
+		"/000/abc/websocket": "abc",
+		"/info":              "",
+		"/websocket":         "",
+	}
+	for path, want := range cases {
+		if got := sockJSSessionId(path); got != want {
+			t.Errorf("%s: want %q, got %q", path, want, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recordstore
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"kubespace/server/common"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StorageLocal 本地磁盘存储
+const StorageLocal = "local"
+
+func init() {
+	Register(StorageLocal, func(conf common.Record) (Store, error) {
+		return NewLocalStore(conf.Dir)
+	})
+}
+
+// LocalStore 将录像保存在本地目录下
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore 创建本地磁盘存储, 目录不存在时自动创建
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("本地录像存储目录不能为空")
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// path 将key转换为存储目录下的文件路径, 拒绝跳出存储目录的key
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("录像存储路径不合法: %s", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+func (s *LocalStore) Save(key string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+		return err
+	}
+	// 先写临时文件再重命名, 避免读取到写了一半的录像
+	tmp := p + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func (s *LocalStore) Open(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (s *LocalStore) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
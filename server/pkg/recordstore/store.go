@@ -0,0 +1,77 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recordstore 终端会话录像的存储后端, 录像内容为zlib压缩的asciicast v2
+package recordstore
+
+import (
+	"fmt"
+	"io"
+	"kubespace/server/common"
+	"sync"
+)
+
+// StorageDB 录像保存在数据库记录自身的longblob字段中, 不经过Store
+const StorageDB = "db"
+
+// Store 录像存储后端, key由调用方生成, 使用/分隔的相对路径
+type Store interface {
+	Save(key string, data []byte) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// Factory 根据配置创建存储后端
+type Factory func(conf common.Record) (Store, error)
+
+var (
+	factoryMu sync.RWMutex
+	factories = map[string]Factory{}
+
+	defaultOnce  sync.Once
+	defaultStore Store
+	defaultErr   error
+)
+
+// Register 注册存储后端, 对象存储等后端可以在init中注册
+func Register(name string, factory Factory) {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	factories[name] = factory
+}
+
+// New 按名称创建存储后端
+func New(conf common.Record) (Store, error) {
+	factoryMu.RLock()
+	factory, ok := factories[conf.Storage]
+	factoryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("不支持的录像存储类型: %s", conf.Storage)
+	}
+	return factory(conf)
+}
+
+// Default 返回配置文件中指定的存储后端, 配置为db或为空时返回nil, 表示录像保存在数据库中
+func Default() (Store, error) {
+	defaultOnce.Do(func() {
+		conf := common.CONFIG.Record
+		if conf.Storage == "" || conf.Storage == StorageDB {
+			return
+		}
+		defaultStore, defaultErr = New(conf)
+	})
+	return defaultStore, defaultErr
+}
@@ -0,0 +1,54 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recordstore
+
+import (
+	"io/ioutil"
+	"kubespace/server/common"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := New(common.Record{Storage: StorageLocal, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("ssh/2021/01/01/abc.cast.z", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	r, err := store.Open("ssh/2021/01/01/abc.cast.z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(r)
+	r.Close()
+	if string(data) != "data" {
+		t.Fatalf("unexpected content: %q", data)
+	}
+	if err := store.Save("../escape", []byte("x")); err == nil {
+		t.Fatal("expected error for key outside the store directory")
+	}
+	if err := store.Delete("ssh/2021/01/01/abc.cast.z"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open("ssh/2021/01/01/abc.cast.z"); err == nil {
+		t.Fatal("expected error after delete")
+	}
+	if _, err := New(common.Record{Storage: "unknown"}); err == nil {
+		t.Fatal("expected error for unknown storage")
+	}
+}
@@ -17,54 +17,100 @@ limitations under the License.
 package utils
 
 import (
-	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"kubespace/server/common"
+	"io"
+	"strings"
 )
 
-func AesEncryptCBC2Hex(origData string) string {
-	// 分组秘钥
-	// NewCipher该函数限制了输入k的长度必须为16, 24或者32
-	key := []byte("NxD3S0yuCc9udD6D")
-	srcData := []byte(origData)
-	block, _ := aes.NewCipher(key)
-	blockSize := block.BlockSize()                              // 获取秘钥块的长度
-	srcData = pkcs5Padding(srcData, blockSize)                  // 补全码
-	blockMode := cipher.NewCBCEncrypter(block, key[:blockSize]) // 加密模式
-	encrypted := make([]byte, len(srcData))                     // 创建数组
-	blockMode.CryptBlocks(encrypted, srcData)
-	// 加密
-	return hex.EncodeToString(encrypted)
+// secretPrefix 使用AES-GCM加密的密文前缀, 没有前缀的是历史版本使用固定密钥AES-CBC加密的密文
+const secretPrefix = "gcm:"
+
+// legacyKey 历史版本硬编码的密钥, 仅用于解密和迁移历史数据
+var legacyKey = []byte("NxD3S0yuCc9udD6D")
+
+// secretAEAD 使用配置文件中的密钥初始化, 见SetSecretKey
+var secretAEAD cipher.AEAD
+
+// SetSecretKey 设置加密存储凭证的密钥, 密钥经过SHA-256得到AES-256的密钥, 启动时调用
+func SetSecretKey(key string) error {
+	if len(key) < 16 {
+		return errors.New("加密密钥不能少于16个字符")
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	secretAEAD = aead
+	return nil
 }
 
-func AesDecryptCBC2Hex(encrypted string) string {
-	defer func() {
-		if err := recover(); err != nil {
-			common.LOG.Error(fmt.Sprintf("ase解密失败: %v", err))
-		}
-	}()
+// EncryptSecret 使用AES-GCM加密, 每次加密使用随机nonce, 返回 前缀+hex(nonce+密文)
+func EncryptSecret(plain string) (string, error) {
+	if secretAEAD == nil {
+		return "", errors.New("未设置加密密钥")
+	}
+	nonce := make([]byte, secretAEAD.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := secretAEAD.Seal(nonce, nonce, []byte(plain), nil)
+	return secretPrefix + hex.EncodeToString(sealed), nil
+}
 
-	key := []byte("NxD3S0yuCc9udD6D")
-	block, _ := aes.NewCipher(key)                              // 分组秘钥
-	blockSize := block.BlockSize()                              // 获取秘钥块的长度
-	blockMode := cipher.NewCBCDecrypter(block, key[:blockSize]) // 加密模式
-	srcData, _ := hex.DecodeString(encrypted)
-	decrypted := make([]byte, len(srcData))   // 创建数组
-	blockMode.CryptBlocks(decrypted, srcData) // 解密
-	decrypted = pkcs5UnPadding(decrypted)     // 去除补全码
-	return string(decrypted)
+// DecryptSecret 解密EncryptSecret的密文, 兼容历史版本AES-CBC加密的密文
+func DecryptSecret(encrypted string) (string, error) {
+	if IsLegacySecret(encrypted) {
+		return legacyDecryptCBC(encrypted)
+	}
+	if secretAEAD == nil {
+		return "", errors.New("未设置加密密钥")
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(encrypted, secretPrefix))
+	if err != nil {
+		return "", fmt.Errorf("密文格式错误: %v", err)
+	}
+	nonceSize := secretAEAD.NonceSize()
+	if len(data) < nonceSize {
+		return "", errors.New("密文格式错误")
+	}
+	plain, err := secretAEAD.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", errors.New("解密失败, 请检查加密密钥是否正确")
+	}
+	return string(plain), nil
 }
 
-func pkcs5Padding(ciphertext []byte, blockSize int) []byte {
-	padding := blockSize - len(ciphertext)%blockSize
-	padText := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(ciphertext, padText...)
+// IsLegacySecret 是否为历史版本AES-CBC加密的密文, 需要使用新密钥重新加密
+func IsLegacySecret(encrypted string) bool {
+	return !strings.HasPrefix(encrypted, secretPrefix)
 }
-func pkcs5UnPadding(origData []byte) []byte {
-	length := len(origData)
-	unPadding := int(origData[length-1])
-	return origData[:(length - unPadding)]
+
+func legacyDecryptCBC(encrypted string) (string, error) {
+	srcData, err := hex.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("密文格式错误: %v", err)
+	}
+	block, _ := aes.NewCipher(legacyKey)
+	blockSize := block.BlockSize()
+	if len(srcData) == 0 || len(srcData)%blockSize != 0 {
+		return "", errors.New("密文格式错误")
+	}
+	decrypted := make([]byte, len(srcData))
+	cipher.NewCBCDecrypter(block, legacyKey[:blockSize]).CryptBlocks(decrypted, srcData)
+	unPadding := int(decrypted[len(decrypted)-1])
+	if unPadding == 0 || unPadding > blockSize {
+		return "", errors.New("解密失败")
+	}
+	return string(decrypted[:len(decrypted)-unPadding]), nil
 }
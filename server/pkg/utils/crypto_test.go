@@ -0,0 +1,66 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import "testing"
+
+func TestSecret(t *testing.T) {
+	if err := SetSecretKey("short"); err == nil {
+		t.Error("short key should be rejected")
+	}
+	if err := SetSecretKey("test-secret-key-0123456789"); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := EncryptSecret("p@ssw0rd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := EncryptSecret("p@ssw0rd")
+	if a == b {
+		t.Error("same plaintext should use different nonces")
+	}
+	if IsLegacySecret(a) {
+		t.Errorf("%s should not be legacy", a)
+	}
+	if plain, err := DecryptSecret(a); err != nil || plain != "p@ssw0rd" {
+		t.Errorf("want p@ssw0rd, got %q, err %v", plain, err)
+	}
+
+	// 篡改或使用其他密钥加密的密文返回错误
+	tampered := a[:len(a)-2] + "00"
+	if tampered == a {
+		tampered = a[:len(a)-2] + "11"
+	}
+	if _, err := DecryptSecret(tampered); err == nil {
+		t.Error("tampered secret should fail")
+	}
+	_ = SetSecretKey("another-secret-key-0123456789")
+	if _, err := DecryptSecret(a); err == nil {
+		t.Error("secret encrypted with another key should fail")
+	}
+
+	// 兼容历史版本AES-CBC加密的密文
+	if plain, err := DecryptSecret("9d940f25497bec844cfb31044d9c4802"); err != nil || plain != "legacy-secret" {
+		t.Errorf("want legacy-secret, got %q, err %v", plain, err)
+	}
+	for _, s := range []string{"not-hex", "9d940f25"} {
+		if _, err := DecryptSecret(s); err == nil {
+			t.Errorf("%s should fail", s)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package utils
 import (
 	"bytes"
 	"compress/zlib"
+	"io/ioutil"
 	"os"
 	"strconv"
 	"unsafe"
@@ -42,6 +43,16 @@ func ZlibCompress(src []byte) []byte {
 	return in.Bytes()
 }
 
+// ZlibUnCompress 解压zlib压缩的数据
+func ZlibUnCompress(src []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
 func Bytes2Str(b []byte) string {
 	return *(*string)(unsafe.Pointer(&b))
 }
@@ -0,0 +1,78 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"strings"
+)
+
+// ParseSigner 解析内存中的PEM私钥, certificate不为空时与OpenSSH证书(authorized_keys格式)组合为证书签名
+func ParseSigner(privateKey, passphrase, certificate string) (ssh.Signer, error) {
+	if !strings.HasPrefix(strings.TrimSpace(privateKey), "-----BEGIN") {
+		return nil, errors.New("私钥必须为PEM格式")
+	}
+	var (
+		signer ssh.Signer
+		err    error
+	)
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(privateKey))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析私钥失败: %v", err)
+	}
+	if certificate == "" {
+		return signer, nil
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(certificate))
+	if err != nil {
+		return nil, fmt.Errorf("解析证书失败: %v", err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, errors.New("证书不是OpenSSH证书")
+	}
+	certSigner, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		return nil, fmt.Errorf("证书与私钥不匹配: %v", err)
+	}
+	return certSigner, nil
+}
+
+// authMethods 按配置生成认证方式, 私钥优先, 同时配置密码时作为后备
+func authMethods(config Config) ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+	if config.PrivateKey != "" {
+		signer, err := ParseSigner(config.PrivateKey, config.KeyPassphrase, config.Certificate)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	if config.Password != "" {
+		methods = append(methods, ssh.Password(config.Password))
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("主机未配置SSH凭证")
+	}
+	return methods, nil
+}
@@ -0,0 +1,74 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"golang.org/x/crypto/ssh"
+	"testing"
+)
+
+func newTestKey(t *testing.T) (string, ssh.Signer) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return string(pem.EncodeToMemory(block)), signer
+}
+
+func TestParseSigner(t *testing.T) {
+	privateKey, signer := newTestKey(t)
+	_, ca := newTestKey(t)
+	_, other := newTestKey(t)
+
+	if _, err := ParseSigner("/root/.ssh/id_rsa", "", ""); err == nil {
+		t.Error("key path must be rejected")
+	}
+	got, err := ParseSigner(privateKey, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ssh.FingerprintSHA256(got.PublicKey()) != ssh.FingerprintSHA256(signer.PublicKey()) {
+		t.Error("unexpected public key")
+	}
+
+	sign := func(key ssh.PublicKey) string {
+		cert := &ssh.Certificate{Key: key, CertType: ssh.UserCert, ValidPrincipals: []string{"root"}, ValidBefore: ssh.CertTimeInfinity}
+		if err := cert.SignCert(rand.Reader, ca); err != nil {
+			t.Fatal(err)
+		}
+		return string(ssh.MarshalAuthorizedKey(cert))
+	}
+	got, err = ParseSigner(privateKey, "", sign(signer.PublicKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.PublicKey().(*ssh.Certificate); !ok {
+		t.Error("certificate signer expected")
+	}
+	if _, err := ParseSigner(privateKey, "", sign(other.PublicKey())); err == nil {
+		t.Error("certificate of another key must be rejected")
+	}
+}
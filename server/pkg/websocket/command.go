@@ -0,0 +1,374 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// outputTail 保留的最近输出长度, 用于识别输入前的提示符
+const outputTail = 256
+
+var (
+	ansiEscape   = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|\][^\x07]*\x07|[@-_])`)
+	secretPrompt = regexp.MustCompile(`(?i)(password|passphrase|passcode|\bpin\b|\btoken\b|verification code|口令|密码)[^\n]*[:：]\s*$`)
+)
+
+// Command 根据输入事件还原出的一条命令, Time为按下回车时距会话开始的秒数
+type Command struct {
+	Time    float64
+	Command string
+}
+
+// CommandParser 按终端的行编辑规则重放键盘输入, 还原用户执行的命令.
+// 只能还原行编辑器内的操作, Tab补全、反向搜索等依赖shell输出的内容无法还原.
+// 通过FeedOutput提供终端输出后, 密码提示后的输入和没有回显的输入不会被当作命令记录.
+type CommandParser struct {
+	line     []rune
+	cursor   int
+	history  []string
+	histPos  int
+	escape   []byte // 未结束的转义序列
+	pending  []byte // 未结束的UTF-8字符
+	commands []*parsedCommand
+
+	withOutput bool   // 是否提供了终端输出, 未提供时不做回显检查
+	output     []byte // 最近的输出
+	started    bool   // 当前行已有输入
+	prompt     string // 当前行开始输入时的提示符
+	echoed     bool   // 当前行输入期间出现了回显
+	feedSeq    int    // Feed调用序号
+	startSeq   int    // 当前行开始输入时的Feed序号
+
+	// 一次性输入(粘贴或快速输入)的整行, 回显在回车之后才到达, 按顺序在之后的输出中确认
+	verify    []*parsedCommand
+	verifyBuf []byte
+}
+
+type parsedCommand struct {
+	Command
+	pending bool // 等待回显确认
+	dropped bool // 没有回显, 不记录
+}
+
+// NewCommandParser 创建命令解析器
+func NewCommandParser() *CommandParser {
+	return &CommandParser{}
+}
+
+// Feed 输入一段键盘数据, t为该数据距会话开始的秒数
+func (p *CommandParser) Feed(t float64, data []byte) {
+	p.feedSeq++
+	if len(p.pending) > 0 {
+		data = append(p.pending, data...)
+		p.pending = nil
+	}
+	for len(data) > 0 {
+		if p.escape != nil {
+			p.escape = append(p.escape, data[0])
+			data = data[1:]
+			if escapeComplete(p.escape) {
+				p.handleEscape(string(p.escape))
+				p.escape = nil
+			}
+			continue
+		}
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 && !utf8.FullRune(data) {
+			p.pending = append([]byte(nil), data...)
+			return
+		}
+		data = data[size:]
+		p.handleRune(t, r)
+	}
+}
+
+// FeedOutput 输入一段终端输出, 用于识别密码提示和检查输入是否回显
+func (p *CommandParser) FeedOutput(data []byte) {
+	p.withOutput = true
+	if len(p.verify) > 0 {
+		p.verifyBuf = append(p.verifyBuf, data...)
+		p.verifyEcho()
+	}
+	if p.started && !p.echoed && p.isEcho(data) {
+		p.echoed = true
+	}
+	p.output = append(p.output, data...)
+	if len(p.output) > outputTail {
+		p.output = append([]byte(nil), p.output[len(p.output)-outputTail:]...)
+	}
+}
+
+// isEcho 输出中包含当前行输入的字符, 密码掩码'*'不算回显
+func (p *CommandParser) isEcho(data []byte) bool {
+	for _, r := range string(data) {
+		if r == '*' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			continue
+		}
+		for _, c := range p.line {
+			if c == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AtSecretPrompt 当前行是否在密码提示后输入
+func (p *CommandParser) AtSecretPrompt() bool {
+	prompt := p.prompt
+	if !p.started {
+		prompt = string(p.output)
+	}
+	prompt = ansiEscape.ReplaceAllString(prompt, "")
+	if i := strings.LastIndexAny(prompt, "\r\n"); i >= 0 {
+		prompt = prompt[i+1:]
+	}
+	return secretPrompt.MatchString(prompt)
+}
+
+// verifyEcho 依次在输出中查找待确认命令的回显. 粘贴的多行内容在前一条命令执行结束后才回显,
+// 因此在之后outputTail字节的输出内查找, 超出仍未找到则认为没有回显
+func (p *CommandParser) verifyEcho() {
+	for len(p.verify) > 0 {
+		c := p.verify[0]
+		if i := bytes.Index(p.verifyBuf, []byte(c.Command.Command)); i >= 0 {
+			c.pending = false
+			p.verifyBuf = p.verifyBuf[i+len(c.Command.Command):]
+		} else if len(p.verifyBuf) > outputTail {
+			p.drop(c)
+		} else {
+			return
+		}
+		p.verify = p.verify[1:]
+	}
+	p.verifyBuf = nil
+}
+
+// drop 没有回显的命令不记录, 同时从历史中移除
+func (p *CommandParser) drop(c *parsedCommand) {
+	c.pending = false
+	c.dropped = true
+	for i := len(p.history) - 1; i >= 0; i-- {
+		if p.history[i] == c.Command.Command {
+			p.history = append(p.history[:i], p.history[i+1:]...)
+			break
+		}
+	}
+	p.histPos = len(p.history)
+}
+
+// Line 返回当前正在编辑的行
+func (p *CommandParser) Line() string {
+	return strings.TrimSpace(string(p.line))
+}
+
+// Commands 返回已还原的命令, 回显尚未确认的命令不返回
+func (p *CommandParser) Commands() []Command {
+	commands := make([]Command, 0, len(p.commands))
+	for _, c := range p.commands {
+		if !c.pending && !c.dropped {
+			commands = append(commands, c.Command)
+		}
+	}
+	return commands
+}
+
+func (p *CommandParser) handleRune(t float64, r rune) {
+	if !p.started {
+		p.started = true
+		p.prompt = string(p.output)
+		p.startSeq = p.feedSeq
+	}
+	switch r {
+	case '\x1b':
+		p.escape = []byte{'\x1b'}
+	case '\r', '\n':
+		// 粘贴的多行内容同样按行执行
+		p.commit(t)
+	case '\x7f', '\b':
+		if p.cursor > 0 {
+			p.line = append(p.line[:p.cursor-1], p.line[p.cursor:]...)
+			p.cursor--
+		}
+	case '\x03': // Ctrl-C 放弃当前行
+		p.reset()
+	case '\x15': // Ctrl-U 删除到行首
+		p.line = append([]rune{}, p.line[p.cursor:]...)
+		p.cursor = 0
+	case '\x0b': // Ctrl-K 删除到行尾
+		p.line = p.line[:p.cursor]
+	case '\x17': // Ctrl-W 删除前一个单词
+		start := p.cursor
+		for start > 0 && unicode.IsSpace(p.line[start-1]) {
+			start--
+		}
+		for start > 0 && !unicode.IsSpace(p.line[start-1]) {
+			start--
+		}
+		p.line = append(p.line[:start], p.line[p.cursor:]...)
+		p.cursor = start
+	case '\x01': // Ctrl-A
+		p.cursor = 0
+	case '\x05': // Ctrl-E
+		p.cursor = len(p.line)
+	case '\x02': // Ctrl-B
+		p.moveCursor(-1)
+	case '\x06': // Ctrl-F
+		p.moveCursor(1)
+	case '\x04': // Ctrl-D 删除光标处字符
+		if p.cursor < len(p.line) {
+			p.line = append(p.line[:p.cursor], p.line[p.cursor+1:]...)
+		}
+	case '\x10': // Ctrl-P
+		p.historyMove(-1)
+	case '\x0e': // Ctrl-N
+		p.historyMove(1)
+	case '\t':
+		// Tab补全的结果来自shell输出, 无法还原, 保留为空格以便识别
+		p.insert(' ')
+	default:
+		if unicode.IsPrint(r) {
+			p.insert(r)
+		}
+	}
+}
+
+// escapeComplete 判断CSI(ESC [)或SS3(ESC O)序列是否结束
+func escapeComplete(seq []byte) bool {
+	if len(seq) < 2 {
+		return false
+	}
+	switch seq[1] {
+	case '[':
+		if len(seq) < 3 {
+			return false
+		}
+		last := seq[len(seq)-1]
+		return last >= 0x40 && last <= 0x7e
+	case 'O':
+		return len(seq) >= 3
+	default:
+		// Alt+字符等两字节序列
+		return true
+	}
+}
+
+func (p *CommandParser) handleEscape(seq string) {
+	switch seq {
+	case "\x1b[D", "\x1bOD":
+		p.moveCursor(-1)
+	case "\x1b[C", "\x1bOC":
+		p.moveCursor(1)
+	case "\x1b[H", "\x1bOH", "\x1b[1~", "\x1b[7~":
+		p.cursor = 0
+	case "\x1b[F", "\x1bOF", "\x1b[4~", "\x1b[8~":
+		p.cursor = len(p.line)
+	case "\x1b[3~":
+		if p.cursor < len(p.line) {
+			p.line = append(p.line[:p.cursor], p.line[p.cursor+1:]...)
+		}
+	case "\x1b[A", "\x1bOA":
+		p.historyMove(-1)
+	case "\x1b[B", "\x1bOB":
+		p.historyMove(1)
+	case "\x1b[200~", "\x1b[201~":
+		// bracketed paste的起止标记, 粘贴内容按普通输入处理
+	case "\x1bb", "\x1b[1;5D": // Alt-B / Ctrl-Left 前移一个单词
+		for p.cursor > 0 && unicode.IsSpace(p.line[p.cursor-1]) {
+			p.cursor--
+		}
+		for p.cursor > 0 && !unicode.IsSpace(p.line[p.cursor-1]) {
+			p.cursor--
+		}
+	case "\x1bf", "\x1b[1;5C": // Alt-F / Ctrl-Right 后移一个单词
+		for p.cursor < len(p.line) && unicode.IsSpace(p.line[p.cursor]) {
+			p.cursor++
+		}
+		for p.cursor < len(p.line) && !unicode.IsSpace(p.line[p.cursor]) {
+			p.cursor++
+		}
+	}
+}
+
+func (p *CommandParser) insert(r rune) {
+	p.line = append(p.line, 0)
+	copy(p.line[p.cursor+1:], p.line[p.cursor:])
+	p.line[p.cursor] = r
+	p.cursor++
+}
+
+func (p *CommandParser) moveCursor(n int) {
+	p.cursor += n
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+	if p.cursor > len(p.line) {
+		p.cursor = len(p.line)
+	}
+}
+
+// historyMove 使用本会话中已执行的命令模拟shell历史, 会话之前的历史无法还原
+func (p *CommandParser) historyMove(n int) {
+	pos := p.histPos + n
+	if pos < 0 || pos > len(p.history) {
+		return
+	}
+	p.histPos = pos
+	if pos == len(p.history) {
+		p.line = nil
+	} else {
+		p.line = []rune(p.history[pos])
+	}
+	p.cursor = len(p.line)
+}
+
+func (p *CommandParser) commit(t float64) {
+	command := p.Line()
+	secret := p.withOutput && p.AtSecretPrompt()
+	single := p.startSeq == p.feedSeq
+	echoed := p.echoed
+	p.reset()
+	if command == "" || secret {
+		return
+	}
+	// 多次输入的行在输入期间就应有回显
+	if p.withOutput && !single && !echoed {
+		return
+	}
+	c := &parsedCommand{Command: Command{Time: t, Command: command}}
+	p.commands = append(p.commands, c)
+	p.history = append(p.history, command)
+	p.histPos = len(p.history)
+	if p.withOutput && single && !echoed {
+		c.pending = true
+		p.verify = append(p.verify, c)
+	}
+}
+
+func (p *CommandParser) reset() {
+	p.line = nil
+	p.cursor = 0
+	p.histPos = len(p.history)
+	p.started = false
+	p.prompt = ""
+	p.echoed = false
+}
@@ -0,0 +1,97 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestCommandParser(t *testing.T) {
+	parser := NewCommandParser()
+	inputs := []string{
+		"ls -l\r",
+		"cat /etc/hostx\x7fs\r", // 退格修改
+		"echo world\x1b[D\x1b[D\x1b[D\x1b[D\x1b[Dhello \r", // 左移插入
+		"rm -rf /\x03",                 // Ctrl-C 放弃
+		"\x1b[A\r",                     // 上翻历史
+		"\x1b[200~uptime\x1b[201~\r",   // bracketed paste
+		"ech", "o 你\xe5", "\xa5\xbd\r", // 跨事件的输入和UTF-8字符
+	}
+	for i, in := range inputs {
+		parser.Feed(float64(i), []byte(in))
+	}
+	want := []string{"ls -l", "cat /etc/hosts", "echo hello world", "echo hello world", "uptime", "echo 你好"}
+	commands := parser.Commands()
+	if len(commands) != len(want) {
+		t.Fatalf("want %d commands, got %+v", len(want), commands)
+	}
+	for i, c := range commands {
+		if c.Command != want[i] {
+			t.Errorf("command %d: want %q, got %q", i, want[i], c.Command)
+		}
+	}
+	if commands[1].Time != 1 {
+		t.Errorf("unexpected command time: %v", commands[1].Time)
+	}
+}
+
+func TestCommandParserSecretInput(t *testing.T) {
+	parser := NewCommandParser()
+	events := []struct {
+		event, data string
+	}{
+		{"o", "root@host:~$ "},
+		{"i", "s"}, {"o", "s"}, {"i", "u"}, {"o", "u"}, {"i", "do -i\r"}, {"o", "do -i\r\n"},
+		{"o", "[sudo] password for admin: "},
+		{"i", "h"}, {"i", "unter2"}, {"i", "\r"}, {"o", "\r\n"}, // 密码提示后的输入
+		{"o", "root@host:~# "},
+		{"i", "read -s x\r"}, {"o", "read -s x\r\n"}, // 一次性输入, 回车后回显
+		{"i", "t"}, {"i", "oken\r"}, {"o", "\r\n# "}, // 没有回显的输入
+		{"i", "mysql -p\r"}, {"o", "mysql -p\r\nEnter password: "},
+		{"i", "s3cret\r"}, {"o", "\r\n"}, // 粘贴的密码
+		{"o", "# "},
+		{"i", "pwd\rid\r"}, {"o", "pwd\r\n/root\r\n# id\r\nuid=0(root)\r\n# "}, // 多行粘贴
+		{"i", "\x1b[A"}, // 上翻历史不应出现密码
+	}
+	for i, e := range events {
+		if e.event == "i" {
+			parser.Feed(float64(i), []byte(e.data))
+		} else {
+			parser.FeedOutput([]byte(e.data))
+		}
+	}
+	want := []string{"sudo -i", "read -s x", "mysql -p", "pwd", "id"}
+	commands := parser.Commands()
+	if len(commands) != len(want) {
+		t.Fatalf("want %v, got %+v", want, commands)
+	}
+	for i, c := range commands {
+		if c.Command != want[i] {
+			t.Errorf("command %d: want %q, got %q", i, want[i], c.Command)
+		}
+	}
+	if line := parser.Line(); line != "id" {
+		t.Errorf("history recall: want %q, got %q", "id", line)
+	}
+
+	guard := NewCommandGuard([]CommandRule{{ID: 1, Name: "any", Pattern: regexp.MustCompile(`.`)}})
+	guard.Output([]byte("[sudo] password for admin: "))
+	if _, blocked := guard.Filter([]byte("hunter2\r")); len(blocked) != 0 {
+		t.Errorf("input at password prompt must not be matched: %+v", blocked)
+	}
+}
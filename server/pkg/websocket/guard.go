@@ -0,0 +1,106 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// CommandRule 会话生效的命令黑名单规则
+type CommandRule struct {
+	ID      uint
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// BlockedCommand 被拦截的命令及命中的规则
+type BlockedCommand struct {
+	Rule    CommandRule
+	Command string
+}
+
+// CommandGuard 在输入发送到SSH之前按行检查命令, 命中黑名单时用Ctrl-C替换回车, 由shell丢弃整行.
+// 与CommandParser一样只能识别行编辑器内的输入, shell自身历史和Tab补全出的命令无法识别.
+type CommandGuard struct {
+	sync.Mutex
+	parser *CommandParser
+	rules  []CommandRule
+}
+
+// NewCommandGuard 创建命令拦截器, rules为空时不拦截
+func NewCommandGuard(rules []CommandRule) *CommandGuard {
+	return &CommandGuard{parser: NewCommandParser(), rules: rules}
+}
+
+// Filter 返回允许发送到SSH的数据和被拦截的命令, 返回的数据与输入长度相同
+func (g *CommandGuard) Filter(data []byte) ([]byte, []BlockedCommand) {
+	if len(g.rules) == 0 {
+		return data, nil
+	}
+	g.Lock()
+	defer g.Unlock()
+	out := make([]byte, 0, len(data))
+	var blocked []BlockedCommand
+	for len(data) > 0 {
+		i := strings.IndexAny(string(data), "\r\n")
+		if i < 0 {
+			g.parser.Feed(0, data)
+			out = append(out, data...)
+			break
+		}
+		g.parser.Feed(0, data[:i])
+		out = append(out, data[:i]...)
+		enter := data[i]
+		data = data[i+1:]
+		// 密码提示后的输入不做检查, 避免密码被记录到拦截记录中
+		if rule, ok := g.match(g.parser.Line()); ok && !g.parser.AtSecretPrompt() {
+			blocked = append(blocked, BlockedCommand{Rule: rule, Command: g.parser.Line()})
+			g.parser.Feed(0, []byte{'\x03'})
+			out = append(out, '\x03')
+			continue
+		}
+		g.parser.Feed(0, []byte{enter})
+		out = append(out, enter)
+		// 只需要当前行和历史, 已提交的命令不再保留
+		g.parser.commands = nil
+	}
+	return out, blocked
+}
+
+// Output 提供终端输出, 用于识别密码提示
+func (g *CommandGuard) Output(data []byte) {
+	if len(g.rules) == 0 {
+		return
+	}
+	g.Lock()
+	g.parser.FeedOutput(data)
+	g.Unlock()
+}
+
+func (g *CommandGuard) match(line string) (CommandRule, bool) {
+	if line == "" {
+		return CommandRule{}, false
+	}
+	for _, rule := range g.rules {
+		if rule.Pattern.MatchString(line) {
+			return rule, true
+		}
+	}
+	return CommandRule{}, false
+}
@@ -0,0 +1,50 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestCommandGuard(t *testing.T) {
+	guard := NewCommandGuard([]CommandRule{
+		{ID: 1, Name: "rm-root", Pattern: regexp.MustCompile(`^rm\s+-rf\s+/\s*$`)},
+	})
+	inputs := []struct {
+		in, out string
+		blocked string
+	}{
+		{in: "ls -l\r", out: "ls -l\r"},
+		{in: "rm -rf /\r", out: "rm -rf /\x03", blocked: "rm -rf /"},
+		{in: "rm -rf /tmp/x\r", out: "rm -rf /tmp/x\r"},
+		{in: "rm -rf ", out: "rm -rf "}, // 跨事件输入
+		{in: "/\r", out: "/\x03", blocked: "rm -rf /"},
+	}
+	for i, c := range inputs {
+		out, blocked := guard.Filter([]byte(c.in))
+		if string(out) != c.out {
+			t.Errorf("input %d: want %q, got %q", i, c.out, out)
+		}
+		if c.blocked == "" && len(blocked) != 0 {
+			t.Errorf("input %d: unexpected block %+v", i, blocked)
+		}
+		if c.blocked != "" && (len(blocked) != 1 || blocked[0].Command != c.blocked || blocked[0].Rule.ID != 1) {
+			t.Errorf("input %d: want block %q, got %+v", i, c.blocked, blocked)
+		}
+	}
+}
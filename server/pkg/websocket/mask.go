@@ -0,0 +1,78 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"bytes"
+	"unicode"
+)
+
+// inputMasker 会话进行中跟踪键盘输入, 在录像中隐藏密码提示后和没有回显的输入
+type inputMasker struct {
+	parser *CommandParser
+	line   []*RecordData // 当前行尚未确认回显的输入事件
+}
+
+func newInputMasker() *inputMasker {
+	return &inputMasker{parser: NewCommandParser()}
+}
+
+// Input 处理一条输入事件, 需要隐藏时直接修改事件数据.
+// 回显在输入之后才到达, 因此没有回显的输入要等到行结束时才能确定并隐藏
+func (m *inputMasker) Input(event *RecordData) {
+	secret := m.parser.withOutput && m.parser.AtSecretPrompt()
+	m.parser.Feed(event.Time, event.Data)
+	m.line = append(m.line, event)
+	if secret {
+		m.mask()
+		return
+	}
+	if m.parser.started && !m.parser.echoed {
+		return
+	}
+	// 与命令解析一致, 多次输入的行结束时仍没有回显则视为关闭了回显
+	if !m.parser.started && len(m.line) > 1 {
+		m.mask()
+	}
+	m.line = nil
+}
+
+// Output 处理一段终端输出, 出现回显后当前行的输入不再隐藏
+func (m *inputMasker) Output(data []byte) {
+	m.parser.FeedOutput(data)
+	if m.parser.echoed {
+		m.line = nil
+	}
+}
+
+func (m *inputMasker) mask() {
+	for _, e := range m.line {
+		e.Data = maskInput(e.Data)
+	}
+	m.line = nil
+}
+
+// maskInput 将可见字符替换为'*', 保留回车等控制字符以便仍能还原出行的结束
+func maskInput(data []byte) []byte {
+	data = ansiEscape.ReplaceAll(data, nil)
+	return bytes.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return '*'
+		}
+		return r
+	}, data)
+}
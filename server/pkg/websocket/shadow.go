@@ -0,0 +1,160 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"github.com/gorilla/websocket"
+	"kubespace/server/models"
+	"sync"
+	"time"
+)
+
+// shadowBuffer 单个旁观连接可积压的输出消息数, 超过后断开该旁观连接, 不阻塞会话本身
+const shadowBuffer = 256
+
+// SessionInfo 在线SSH会话信息
+type SessionInfo struct {
+	ConnectId  string           `json:"connect_id"`
+	Operator   string           `json:"operator"`
+	RemoteAddr string           `json:"remote_addr"`
+	UserName   string           `json:"user_name"`
+	HostId     uint             `json:"host_id"`
+	HostName   string           `json:"host_name"`
+	Width      int              `json:"width"`
+	Height     int              `json:"height"`
+	CreatedAt  models.LocalTime `json:"created_at"`
+	UpdatedAt  models.LocalTime `json:"updated_at"`
+	Shadows    int              `json:"shadows"` // 旁观连接数
+}
+
+type shadow struct {
+	send chan []byte
+	done chan struct{}
+	once sync.Once
+}
+
+func (s *shadow) close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// Info 返回会话信息
+func (r *WebSocketStream) Info() SessionInfo {
+	r.RLock()
+	defer r.RUnlock()
+	return SessionInfo{
+		ConnectId:  r.Meta.ConnectId,
+		Operator:   r.Meta.Operator,
+		RemoteAddr: r.Meta.RemoteAddr,
+		UserName:   r.Meta.UserName,
+		HostId:     r.Meta.HostId,
+		HostName:   r.Meta.HostName,
+		Width:      r.Meta.Width,
+		Height:     r.Meta.Height,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+		Shadows:    len(r.shadows),
+	}
+}
+
+// Shadow 将会话输出只读转发到ws, 首条消息为会话至今的全部输出, 用于还原当前屏幕.
+// ws上收到的消息全部丢弃, 阻塞到旁观连接或会话关闭
+func (r *WebSocketStream) Shadow(ws *websocket.Conn) {
+	s := &shadow{send: make(chan []byte, shadowBuffer), done: make(chan struct{})}
+	if !r.addShadow(s) {
+		return
+	}
+	defer r.removeShadow(s)
+
+	go func() {
+		defer s.close()
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case data := <-s.send:
+			_ = ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := ws.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (r *WebSocketStream) addShadow(s *shadow) bool {
+	r.Lock()
+	defer r.Unlock()
+	if r.shadowClosed {
+		return false
+	}
+	var history []byte
+	for _, v := range r.recorder {
+		if v.Event == "o" {
+			history = append(history, v.Data...)
+		}
+	}
+	if len(history) > 0 {
+		s.send <- history
+	}
+	if r.shadows == nil {
+		r.shadows = make(map[*shadow]struct{})
+	}
+	r.shadows[s] = struct{}{}
+	return true
+}
+
+func (r *WebSocketStream) removeShadow(s *shadow) {
+	r.Lock()
+	delete(r.shadows, s)
+	r.Unlock()
+	s.close()
+}
+
+// broadcast 转发输出到旁观连接, 调用方需持有锁
+func (r *WebSocketStream) broadcast(data []byte) {
+	for s := range r.shadows {
+		select {
+		case s.send <- data:
+		default:
+			// 旁观端消费过慢, 直接断开
+			s.close()
+			delete(r.shadows, s)
+		}
+	}
+}
+
+// CloseShadows 会话结束时断开全部旁观连接, 之后不再接受新的旁观
+func (r *WebSocketStream) CloseShadows() {
+	r.Lock()
+	defer r.Unlock()
+	r.shadowClosed = true
+	for s := range r.shadows {
+		s.close()
+		delete(r.shadows, s)
+	}
+}
+
+// Kill 提示用户后强制断开会话
+func (r *WebSocketStream) Kill(reason string) error {
+	_ = r.Conn.WriteMessage(websocket.BinaryMessage, []byte("\r\n\x1b[31m"+reason+"\x1b[0m\r\n"))
+	return r.Terminal.Close()
+}
@@ -0,0 +1,51 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import "testing"
+
+func TestStreamShadow(t *testing.T) {
+	stream := &WebSocketStream{recorder: []*RecordData{
+		{Event: "o", Data: []byte("$ ")},
+		{Event: "i", Data: []byte("ls\r")},
+		{Event: "o", Data: []byte("ls\r\n")},
+	}}
+	s := &shadow{send: make(chan []byte, 2), done: make(chan struct{})}
+	if !stream.addShadow(s) {
+		t.Fatal("shadow rejected")
+	}
+	if got := string(<-s.send); got != "$ ls\r\n" {
+		t.Errorf("unexpected history %q", got)
+	}
+
+	stream.broadcast([]byte("a"))
+	stream.broadcast([]byte("b"))
+	stream.broadcast([]byte("c")) // 缓冲已满, 旁观连接被断开
+	select {
+	case <-s.done:
+	default:
+		t.Error("slow shadow not closed")
+	}
+	if len(stream.shadows) != 0 {
+		t.Errorf("slow shadow not removed")
+	}
+
+	stream.CloseShadows()
+	if stream.addShadow(&shadow{send: make(chan []byte, 1), done: make(chan struct{})}) {
+		t.Error("shadow accepted after session closed")
+	}
+}
@@ -25,7 +25,9 @@ import (
 	"kubespace/server/models"
 	"kubespace/server/models/cmdb"
 	"kubespace/server/pkg/asciicast2"
+	"kubespace/server/pkg/recordstore"
 	"kubespace/server/pkg/utils"
+	"path"
 	"sync"
 	"time"
 )
@@ -60,25 +62,31 @@ type RecordData struct {
 }
 
 type Meta struct {
-	TERM      string
-	Width     int
-	Height    int
-	UserName  string
-	ConnectId string
-	HostId    uint
-	HostName  string
+	TERM       string
+	Width      int
+	Height     int
+	UserName   string
+	ConnectId  string
+	HostId     uint
+	HostName   string
+	Operator   string // 平台用户
+	RemoteAddr string // 平台用户的客户端地址
 }
 
 type WebSocketStream struct {
 	sync.RWMutex
-	Terminal    *Terminal        // ssh客户端
-	Conn        *wsConn          // socket 连接
-	messageType int              // 发送的数据类型
-	recorder    []*RecordData    // 操作记录
-	CreatedAt   models.LocalTime // 创建时间
-	UpdatedAt   models.LocalTime // 最新的更新时间
-	Meta        Meta             // 元信息
-	written     bool             // 是否已写入记录, 一个流只允许写入一次
+	Terminal     *Terminal            // ssh客户端
+	Conn         *wsConn              // socket 连接
+	messageType  int                  // 发送的数据类型
+	recorder     []*RecordData        // 操作记录
+	CreatedAt    models.LocalTime     // 创建时间
+	UpdatedAt    models.LocalTime     // 最新的更新时间
+	Meta         Meta                 // 元信息
+	written      bool                 // 是否已写入记录, 一个流只允许写入一次
+	guard        *CommandGuard        // 命令黑名单拦截
+	masker       *inputMasker         // 隐藏录像中的密码输入
+	shadows      map[*shadow]struct{} // 只读旁观连接
+	shadowClosed bool                 // 会话已结束, 不再接受旁观
 }
 
 // NewWebSocketSteam 创建websocket数据流
@@ -95,6 +103,7 @@ func NewWebSocketSteam(terminal *Terminal, connection *wsConn, meta Meta) *WebSo
 		},
 		recorder: make([]*RecordData, 0),
 		Meta:     meta,
+		masker:   newInputMasker(),
 	}
 }
 
@@ -124,12 +133,15 @@ func (r *WebSocketStream) Read(p []byte) (n int, err error) {
 			return
 		}
 	}
+	if r.guard != nil {
+		var blocked []BlockedCommand
+		message, blocked = r.guard.Filter(message)
+		for _, b := range blocked {
+			r.block(b)
+		}
+	}
 	r.Lock()
-	//r.recorder = append(r.recorder, &RecordData{
-	//	Time:  time.Since(r.CreatedAt.Time).Seconds(),
-	//	Event: "i",
-	//	Data:  message,
-	//})
+	r.recordInput(message)
 	defer r.Unlock()
 	r.UpdatedAt = models.LocalTime{
 		Time: time.Now(),
@@ -140,6 +152,62 @@ func (r *WebSocketStream) Read(p []byte) (n int, err error) {
 	return
 }
 
+// recordInput 记录一条输入事件, 密码等没有回显的输入在录像中隐藏, 调用方需持有锁
+func (r *WebSocketStream) recordInput(message []byte) {
+	var data = make([]byte, len(message))
+	copy(data, message)
+	event := &RecordData{
+		Time:  time.Since(r.CreatedAt.Time).Seconds(),
+		Event: "i",
+		Data:  data,
+	}
+	r.recorder = append(r.recorder, event)
+	if r.masker != nil {
+		r.masker.Input(event)
+	}
+}
+
+// recordOutput 记录一条输出事件并返回记录的数据, 调用方需持有锁
+func (r *WebSocketStream) recordOutput(p []byte) []byte {
+	var data = make([]byte, len(p))
+	copy(data, p)
+	r.recorder = append(r.recorder, &RecordData{
+		Time:  time.Since(r.CreatedAt.Time).Seconds(),
+		Event: "o",
+		Data:  data,
+	})
+	if r.masker != nil {
+		r.masker.Output(data)
+	}
+	return data
+}
+
+// SetCommandRules 设置会话生效的命令黑名单
+func (r *WebSocketStream) SetCommandRules(rules []CommandRule) {
+	r.guard = NewCommandGuard(rules)
+}
+
+// block 提示用户命令已被拦截并记录审计
+func (r *WebSocketStream) block(b BlockedCommand) {
+	common.LOG.Warn(fmt.Sprintf("拦截SSH命令, 用户: %v, 主机: %v, 命令: %v, 规则: %v", r.Meta.Operator, r.Meta.HostName, b.Command, b.Rule.Name))
+	warning := fmt.Sprintf("\r\n\x1b[31m命令 %q 命中规则 %q, 已禁止执行\x1b[0m\r\n", b.Command, b.Rule.Name)
+	_ = r.Conn.WriteMessage(websocket.BinaryMessage, utils.Str2Bytes(warning))
+	record := cmdb.SSHCommandBlock{
+		RuleId:    b.Rule.ID,
+		RuleName:  b.Rule.Name,
+		ConnectID: r.Meta.ConnectId,
+		Operator:  r.Meta.Operator,
+		UserName:  r.Meta.UserName,
+		HostId:    r.Meta.HostId,
+		HostName:  r.Meta.HostName,
+		Command:   b.Command,
+		BlockTime: models.LocalTime{Time: time.Now()},
+	}
+	if err := common.DB.Create(&record).Error; err != nil {
+		common.LOG.Error(fmt.Sprintf("记录SSH命令拦截失败: %v", err))
+	}
+}
+
 func (r *WebSocketStream) Write(p []byte) (n int, err error) {
 	n = len(p)
 	var msgObj wsMsg
@@ -154,17 +222,11 @@ func (r *WebSocketStream) Write(p []byte) (n int, err error) {
 		}
 	}
 	r.Lock()
-
-	var data = make([]byte, len(p))
-	copy(data, p)
-	r.recorder = append(r.recorder, &RecordData{
-		Time:  time.Since(r.CreatedAt.Time).Seconds(),
-		Event: "o",
-		Data:  data,
-	})
+	data := r.recordOutput(p)
 	defer r.Unlock()
-	if r.Conn != nil {
-
+	r.broadcast(data)
+	if r.guard != nil {
+		r.guard.Output(data)
 	}
 	// 超时
 	_ = r.Conn.Ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
@@ -184,13 +246,15 @@ func (r *WebSocketStream) Write2Log() error {
 	if r.written {
 		return nil
 	}
+	// 写入失败也不再重试, 避免重复保存录像
+	r.written = true
 	recorders := r.recorder
 	if len(recorders) != 0 {
 		b := new(bytes.Buffer)
 		meta := asciicast2.CastV2Header{
 			Width:     r.Meta.Width,
 			Height:    r.Meta.Height,
-			Timestamp: time.Now().Unix(),
+			Timestamp: r.CreatedAt.Unix(),
 			Title:     r.Meta.ConnectId,
 			Env: &map[string]string{
 				"SHELL": "/bin/bash", "TERM": r.Meta.TERM,
@@ -201,21 +265,86 @@ func (r *WebSocketStream) Write2Log() error {
 			cast.Record(v.Time, v.Data, v.Event)
 		}
 		compressData := utils.ZlibCompress(buffer.Bytes())
-		if len(compressData) > 320 {
-			record := cmdb.SSHRecord{
-				ConnectID:   r.Meta.ConnectId,
-				HostName:    r.Meta.HostName,
-				UserName:    r.Meta.UserName,
-				Records:     compressData,
-				ConnectTime: r.CreatedAt,
-				LogoutTime: models.LocalTime{
-					Time: time.Now(),
-				},
-				HostId: r.Meta.HostId,
-			}
-			common.DB.Create(&record)
+		record := cmdb.SSHRecord{
+			ConnectID:   r.Meta.ConnectId,
+			HostName:    r.Meta.HostName,
+			UserName:    r.Meta.UserName,
+			Operator:    r.Meta.Operator,
+			Size:        len(compressData),
+			Duration:    cast.Duration,
+			ConnectTime: r.CreatedAt,
+			LogoutTime: models.LocalTime{
+				Time: time.Now(),
+			},
+			HostId: r.Meta.HostId,
+		}
+		if err := saveRecord(&record, compressData); err != nil {
+			return err
+		}
+		record.Commands = r.parseCommands()
+		if err := common.DB.Create(&record).Error; err != nil {
+			removeRecord(&record)
+			return err
 		}
 	}
-	r.written = true
 	return nil
 }
+
+// saveRecord 按配置的存储后端保存录像, 未配置时存储在数据库中
+func saveRecord(record *cmdb.SSHRecord, data []byte) error {
+	store, err := recordstore.Default()
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		record.Records = data
+		return nil
+	}
+	key := path.Join("ssh", record.ConnectTime.Format("2006/01/02"), record.ConnectID+".cast.z")
+	if err := store.Save(key, data); err != nil {
+		return err
+	}
+	record.StoragePath = key
+	return nil
+}
+
+// removeRecord 会话记录写入数据库失败时删除已保存的录像, 避免留下没有记录引用的文件
+func removeRecord(record *cmdb.SSHRecord) {
+	if record.StoragePath == "" {
+		return
+	}
+	store, err := recordstore.Default()
+	if err != nil || store == nil {
+		return
+	}
+	if err := store.Delete(record.StoragePath); err != nil {
+		common.LOG.Error(fmt.Sprintf("删除录像%s失败: %v", record.StoragePath, err))
+	}
+}
+
+// parseCommands 从输入事件中还原执行的命令, 随会话录像一起保存
+func (r *WebSocketStream) parseCommands() []cmdb.SSHCommand {
+	parser := NewCommandParser()
+	for _, v := range r.recorder {
+		switch v.Event {
+		case "i":
+			parser.Feed(v.Time, v.Data)
+		case "o":
+			parser.FeedOutput(v.Data)
+		}
+	}
+	commands := make([]cmdb.SSHCommand, 0, len(parser.Commands()))
+	for _, c := range parser.Commands() {
+		commands = append(commands, cmdb.SSHCommand{
+			ConnectID: r.Meta.ConnectId,
+			UserName:  r.Meta.UserName,
+			Operator:  r.Meta.Operator,
+			HostId:    r.Meta.HostId,
+			HostName:  r.Meta.HostName,
+			Command:   c.Command,
+			ExecTime:  models.LocalTime{Time: r.CreatedAt.Add(time.Duration(c.Time * float64(time.Second)))},
+			Offset:    c.Time,
+		})
+	}
+	return commands
+}
@@ -0,0 +1,180 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"kubespace/server/common"
+	"kubespace/server/models"
+	"kubespace/server/pkg/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingConn 所有SQL都执行失败的连接
+type failingConn struct{}
+
+var errDBDown = errors.New("database is down")
+
+func (failingConn) PrepareContext(context.Context, string) (*sql.Stmt, error) { return nil, errDBDown }
+func (failingConn) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errDBDown
+}
+func (failingConn) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errDBDown
+}
+func (failingConn) QueryRowContext(context.Context, string, ...interface{}) *sql.Row { return nil }
+
+// okConn 所有SQL都执行成功的连接
+type okConn struct{ failingConn }
+
+type okResult struct{}
+
+func (okResult) LastInsertId() (int64, error) { return 1, nil }
+func (okResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (okConn) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return okResult{}, nil
+}
+
+func TestWrite2LogMasksSecretInput(t *testing.T) {
+	common.LOG = zap.NewNop()
+	dir := t.TempDir()
+	common.CONFIG.Record = common.Record{Storage: "local", Dir: dir}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: okConn{}, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	common.DB = db
+
+	stream := &WebSocketStream{
+		CreatedAt: models.LocalTime{Time: time.Now()},
+		Meta:      Meta{ConnectId: "c1", Width: 80, Height: 24},
+		masker:    newInputMasker(),
+	}
+	output := func(s string) { stream.recordOutput([]byte(s)) }
+	// 逐个按键输入一行, echo为false时终端没有回显
+	typeLine := func(line string, echo bool) {
+		for _, k := range line {
+			stream.recordInput([]byte(string(k)))
+			if echo {
+				output(string(k))
+			}
+		}
+		stream.recordInput([]byte("\r"))
+		output("\r\n")
+	}
+	output("$ ")
+	typeLine("sudo -s", true)
+	output("[sudo] password for ops: ")
+	typeLine("hunter2", false)
+	output("# ")
+	// 没有密码提示但关闭了回显的输入
+	typeLine("stty -echo", true)
+	output("# ")
+	typeLine("opensesame", false)
+	output("# ")
+
+	if err := stream.Write2Log(); err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("stored recordings = %v, err = %v", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cast, err := utils.ZlibUnCompress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typed := joinInput(string(cast))
+	for _, secret := range []string{"hunter2", "opensesame"} {
+		if strings.Contains(typed, secret) {
+			t.Errorf("recording contains secret %q:\n%s", secret, cast)
+		}
+	}
+	if !strings.Contains(typed, "stty -echo") {
+		t.Errorf("recording lost echoed input:\n%s", cast)
+	}
+}
+
+// joinInput 拼接录像中所有输入事件的数据
+func joinInput(cast string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(cast, "\n") {
+		var event []interface{}
+		if json.Unmarshal([]byte(line), &event) != nil || len(event) != 3 || event[1] != "i" {
+			continue
+		}
+		if s, ok := event[2].(string); ok {
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
+
+func TestWrite2LogRemovesRecordOnDBError(t *testing.T) {
+	common.LOG = zap.NewNop()
+	dir := t.TempDir()
+	common.CONFIG.Record = common.Record{Storage: "local", Dir: dir}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: failingConn{}, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	common.DB = db
+
+	stream := &WebSocketStream{
+		recorder:  []*RecordData{{Event: "o", Time: 0.1, Data: []byte("$ ")}},
+		CreatedAt: models.LocalTime{Time: time.Now()},
+		Meta:      Meta{ConnectId: "c1", Width: 80, Height: 24},
+	}
+	if err := stream.Write2Log(); !errors.Is(err, errDBDown) {
+		t.Fatalf("Write2Log error = %v, want %v", err, errDBDown)
+	}
+
+	// 数据库写入失败时不应留下录像文件
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			t.Errorf("orphaned recording %s", path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
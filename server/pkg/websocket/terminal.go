@@ -20,12 +20,9 @@ import (
 	"fmt"
 	"golang.org/x/crypto/ssh"
 	"io"
-	"io/ioutil"
 	"kubespace/server/common"
-	"kubespace/server/pkg/utils"
 	"net"
 	"os"
-	"path/filepath"
 	"time"
 )
 
@@ -41,13 +38,15 @@ type Terminal struct {
 	closed       bool
 }
 
+// Config SSH连接配置, 凭证均为解密后的明文, 只在内存中使用
 type Config struct {
 	UserName      string
 	IpAddress     string //IP地址
 	Port          string
 	Password      string // 密码连接
-	PrivateKey    string // 私钥连接
+	PrivateKey    string // 私钥连接, PEM格式内容
 	KeyPassphrase string // 私钥密码
+	Certificate   string // OpenSSH证书, 与私钥配合使用
 	Width         int    // pty width
 	Height        int    // pty height
 }
@@ -125,7 +124,10 @@ func (t *Terminal) Connect(stdin io.Reader, stdout io.Writer, stderr io.Writer)
 }
 
 func NewTerminal(config Config) (*Terminal, error) {
-	var authMethods []ssh.AuthMethod
+	methods, err := authMethods(config)
+	if err != nil {
+		return nil, err
+	}
 
 	sshConfig := &ssh.ClientConfig{
 		User:            config.UserName,
@@ -134,17 +136,7 @@ func NewTerminal(config Config) (*Terminal, error) {
 		Timeout:         time.Second * 15,
 	}
 
-	if config.PrivateKey != "" {
-		if pk, err := getPrivateKey(config.PrivateKey, utils.AesDecryptCBC2Hex(config.KeyPassphrase)); err != nil {
-			return nil, err
-		} else {
-			authMethods = append(authMethods, pk)
-		}
-	} else {
-		authMethods = append(authMethods, ssh.Password(utils.AesDecryptCBC2Hex(config.Password)))
-	}
-
-	sshConfig.Auth = authMethods
+	sshConfig.Auth = methods
 
 	addr := net.JoinHostPort(config.IpAddress, config.Port)
 
@@ -171,23 +163,3 @@ func NewTerminal(config Config) (*Terminal, error) {
 
 	return &s, nil
 }
-
-func getPrivateKey(privateKeyPath string, privateKeyPassphrase string) (ssh.AuthMethod, error) {
-	if !utils.FileExist(privateKeyPath) {
-		privateKeyPath = filepath.Join(os.Getenv("HOME"), ".ssh/id_rsa")
-	}
-	key, err := ioutil.ReadFile(privateKeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse private key: %v", err)
-	}
-	var signer ssh.Signer
-	if privateKeyPassphrase != "" {
-		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(privateKeyPassphrase))
-	} else {
-		signer, err = ssh.ParsePrivateKey(key)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("parse private key failed: %v", err)
-	}
-	return ssh.PublicKeys(signer), nil
-}
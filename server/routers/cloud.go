@@ -25,6 +25,19 @@ func InitCloudRouter(r *gin.RouterGroup) {
 	InitCloudRouter := r.Group("cloud")
 	{
 		InitCloudRouter.GET("listPlatform", controller.ListPlatform)
+		InitCloudRouter.GET("account", controller.GetCloudAccount)
 		InitCloudRouter.POST("account", controller.CloudPlatformAccount)
+		InitCloudRouter.PUT("account", controller.UpdateCloudAccount)
+		InitCloudRouter.POST("account/delete", controller.DeleteCloudAccount)
+		InitCloudRouter.POST("account/enable", controller.EnableCloudAccount)
+		InitCloudRouter.POST("account/disable", controller.DisableCloudAccount)
+		InitCloudRouter.POST("account/verify", controller.VerifyCloudAccount)
+		InitCloudRouter.GET("syncRecord", controller.ListCloudSyncRecord)
+		InitCloudRouter.POST("sync", controller.SyncCloudAccount)
+		InitCloudRouter.GET("disk", controller.ListCloudDisk)
+		InitCloudRouter.GET("loadBalancer", controller.ListCloudLoadBalancer)
+		InitCloudRouter.GET("database", controller.ListCloudDatabase)
+		InitCloudRouter.GET("vpc", controller.ListCloudVpc)
+		InitCloudRouter.GET("subnet", controller.ListCloudSubnet)
 	}
 }
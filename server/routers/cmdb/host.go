@@ -26,5 +26,25 @@ func InitHostRouter(r *gin.RouterGroup) {
 	{
 		Router.GET("/host/group", cmdb.ListHostGroup)
 		Router.GET("/host/server", cmdb.ListHost)
+		Router.POST("/host/server", cmdb.CreateHost)
+		Router.PUT("/host/server", cmdb.UpdateHost)
+		Router.POST("/host/server/delete", cmdb.DeleteHost)
+		Router.POST("/host/import", cmdb.ImportHost)
+		Router.GET("/host/export", cmdb.ExportHost)
+		Router.GET("/ssh/record", cmdb.ListSSHRecord)
+		Router.GET("/ssh/record/download", cmdb.DownloadSSHRecord)
+		Router.GET("/ssh/command", cmdb.ListSSHCommand)
+		Router.GET("/ssh/rule", cmdb.ListSSHCommandRule)
+		Router.POST("/ssh/rule", cmdb.CreateSSHCommandRule)
+		Router.PUT("/ssh/rule", cmdb.UpdateSSHCommandRule)
+		Router.POST("/ssh/rule/delete", cmdb.DeleteSSHCommandRule)
+		Router.GET("/ssh/block", cmdb.ListSSHCommandBlock)
+		Router.GET("/ssh/session", cmdb.ListSSHSession)
+		Router.POST("/ssh/session/kill", cmdb.KillSSHSession)
+		Router.GET("/ssh/credential", cmdb.ListSSHCredential)
+		Router.POST("/ssh/credential", cmdb.CreateSSHCredential)
+		Router.PUT("/ssh/credential", cmdb.UpdateSSHCredential)
+		Router.POST("/ssh/credential/delete", cmdb.DeleteSSHCredential)
+		Router.POST("/ssh/credential/bind", cmdb.BindSSHCredential)
 	}
 }
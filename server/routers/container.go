@@ -18,6 +18,7 @@ package routers
 
 import (
 	"kubespace/server/controller/k8s"
+	"kubespace/server/pkg/k8s/terminal"
 	"github.com/gin-gonic/gin"
 )
 
@@ -38,6 +39,8 @@ func InitContainerRouter(r *gin.RouterGroup) {
 		K8sClusterRouter.POST("node/collectionSchedule", k8s.CollectionNodeUnschedule)
 		K8sClusterRouter.GET("node/cordon", k8s.CordonNode)
 		K8sClusterRouter.POST("node/collectionCordon", k8s.CollectionCordonNode)
+		K8sClusterRouter.POST("node/drain", k8s.DrainNode)
+		K8sClusterRouter.GET("node/drain", k8s.GetDrainTask)
 
 		K8sClusterRouter.GET("deployment", k8s.GetDeploymentList)
 		K8sClusterRouter.POST("deployments", k8s.DeleteCollectionDeployment)
@@ -49,12 +52,76 @@ func InitContainerRouter(r *gin.RouterGroup) {
 		K8sClusterRouter.POST("deployment/service", k8s.GetDeploymentToServiceController)
 		K8sClusterRouter.POST("deployment/rollback", k8s.RollBackDeploymentController)
 
+		K8sClusterRouter.POST("rollout/image", k8s.SetImageController)
+		K8sClusterRouter.POST("rollout/pause", k8s.PauseRolloutController)
+		K8sClusterRouter.POST("rollout/resume", k8s.ResumeRolloutController)
+		K8sClusterRouter.GET("rollout/status/:namespace/:name/:kind", k8s.RolloutStatusController)
+		K8sClusterRouter.GET("rollout/history", k8s.GetRevisionsController)
+		K8sClusterRouter.GET("rollout/diff", k8s.DiffRevisionsController)
+		K8sClusterRouter.POST("rollout/rollback", k8s.RollbackRevisionController)
+
+		K8sClusterRouter.GET("hpa", k8s.GetHorizontalPodAutoscalerController)
+		K8sClusterRouter.GET("hpa/detail", k8s.DetailHorizontalPodAutoscalerController)
+		K8sClusterRouter.POST("hpa", k8s.CreateHorizontalPodAutoscalerController)
+		K8sClusterRouter.PUT("hpa", k8s.UpdateHorizontalPodAutoscalerController)
+		K8sClusterRouter.DELETE("hpa", k8s.DeleteHorizontalPodAutoscalerController)
+
 		K8sClusterRouter.GET("namespace", k8s.GetNamespaceList)
+		K8sClusterRouter.GET("namespace/list", k8s.GetNamespaceListController)
+		K8sClusterRouter.GET("namespace/detail", k8s.DetailNamespaceController)
+		K8sClusterRouter.POST("namespace", k8s.CreateNamespaceController)
+		K8sClusterRouter.PUT("namespace", k8s.UpdateNamespaceController)
+		K8sClusterRouter.GET("namespace/deleteToken", k8s.NamespaceDeleteTokenController)
+		K8sClusterRouter.POST("namespace/delete", k8s.DeleteNamespaceController)
+
+		K8sClusterRouter.GET("resourcequota", k8s.GetResourceQuotaController)
+		K8sClusterRouter.GET("resourcequota/detail", k8s.DetailResourceQuotaController)
+		K8sClusterRouter.POST("resourcequota", k8s.CreateResourceQuotaController)
+		K8sClusterRouter.PUT("resourcequota", k8s.UpdateResourceQuotaController)
+		K8sClusterRouter.DELETE("resourcequota", k8s.DeleteResourceQuotaController)
+
+		K8sClusterRouter.GET("serviceaccount", k8s.GetServiceAccountController)
+		K8sClusterRouter.GET("serviceaccount/detail", k8s.DetailServiceAccountController)
+		K8sClusterRouter.POST("serviceaccount", k8s.CreateServiceAccountController)
+		K8sClusterRouter.DELETE("serviceaccount", k8s.DeleteServiceAccountController)
+		K8sClusterRouter.GET("serviceaccount/kubeconfig", k8s.ServiceAccountKubeConfigController)
+
+		K8sClusterRouter.GET("rbac/role", k8s.GetRoleController)
+		K8sClusterRouter.GET("rbac/role/detail", k8s.DetailRoleController)
+		K8sClusterRouter.POST("rbac/role", k8s.CreateRoleController)
+		K8sClusterRouter.PUT("rbac/role", k8s.UpdateRoleController)
+		K8sClusterRouter.DELETE("rbac/role", k8s.DeleteRoleController)
+		K8sClusterRouter.GET("rbac/clusterrole", k8s.GetClusterRoleController)
+		K8sClusterRouter.GET("rbac/clusterrole/detail", k8s.DetailClusterRoleController)
+		K8sClusterRouter.POST("rbac/clusterrole", k8s.CreateClusterRoleController)
+		K8sClusterRouter.PUT("rbac/clusterrole", k8s.UpdateClusterRoleController)
+		K8sClusterRouter.DELETE("rbac/clusterrole", k8s.DeleteClusterRoleController)
+		K8sClusterRouter.GET("rbac/rolebinding", k8s.GetRoleBindingController)
+		K8sClusterRouter.GET("rbac/rolebinding/detail", k8s.DetailRoleBindingController)
+		K8sClusterRouter.POST("rbac/rolebinding", k8s.CreateRoleBindingController)
+		K8sClusterRouter.PUT("rbac/rolebinding", k8s.UpdateRoleBindingController)
+		K8sClusterRouter.DELETE("rbac/rolebinding", k8s.DeleteRoleBindingController)
+		K8sClusterRouter.GET("rbac/clusterrolebinding", k8s.GetClusterRoleBindingController)
+		K8sClusterRouter.GET("rbac/clusterrolebinding/detail", k8s.DetailClusterRoleBindingController)
+		K8sClusterRouter.POST("rbac/clusterrolebinding", k8s.CreateClusterRoleBindingController)
+		K8sClusterRouter.PUT("rbac/clusterrolebinding", k8s.UpdateClusterRoleBindingController)
+		K8sClusterRouter.DELETE("rbac/clusterrolebinding", k8s.DeleteClusterRoleBindingController)
+		K8sClusterRouter.GET("rbac/whocan", k8s.WhoCanController)
+		K8sClusterRouter.GET("rbac/subject", k8s.SubjectPermissionsController)
+
+		K8sClusterRouter.GET("limitrange", k8s.GetLimitRangeController)
+		K8sClusterRouter.GET("limitrange/detail", k8s.DetailLimitRangeController)
+		K8sClusterRouter.POST("limitrange", k8s.CreateLimitRangeController)
+		K8sClusterRouter.PUT("limitrange", k8s.UpdateLimitRangeController)
+		K8sClusterRouter.DELETE("limitrange", k8s.DeleteLimitRangeController)
 
 		K8sClusterRouter.GET("pod", k8s.GetPodsListController)
 		K8sClusterRouter.DELETE("pod", k8s.DeletePodController)
 		K8sClusterRouter.POST("pods", k8s.DeleteCollectionPodsController)
 		K8sClusterRouter.GET("pod/detail", k8s.DetailPodController)
+		K8sClusterRouter.GET("pod/terminal", k8s.ExecShellController)
+		// SockJS握手及数据传输同样经过AuthMiddleware, token通过query参数传递
+		K8sClusterRouter.Any("sockjs/*path", terminal.CreateAttachHandler(K8sClusterRouter.BasePath()+"/sockjs"))
 
 		K8sClusterRouter.GET("statefulset", k8s.GetStatefulSetListController)
 		K8sClusterRouter.DELETE("statefulset", k8s.DeleteStatefulSetController)
@@ -79,6 +146,8 @@ func InitContainerRouter(r *gin.RouterGroup) {
 		K8sClusterRouter.DELETE("cronjob", k8s.DeleteCronJobController)
 		K8sClusterRouter.POST("cronjobs", k8s.DeleteCollectionCronJobController)
 		K8sClusterRouter.GET("cronjob/detail", k8s.DetailCronJobController)
+		K8sClusterRouter.POST("cronjob/suspend", k8s.SuspendCronJobController)
+		K8sClusterRouter.POST("cronjob/trigger", k8s.TriggerCronJobController)
 
 		K8sClusterRouter.GET("storage/pvc", k8s.GetPersistentVolumeClaimListController)
 		K8sClusterRouter.GET("storage/pvc/detail", k8s.DetailPersistentVolumeClaimController)
@@ -96,25 +165,47 @@ func InitContainerRouter(r *gin.RouterGroup) {
 		K8sClusterRouter.GET("network/service/detail", k8s.DetailServiceController)
 		K8sClusterRouter.DELETE("network/service", k8s.DeleteServiceController)
 		K8sClusterRouter.POST("network/services", k8s.DeleteCollectionServiceController)
+		K8sClusterRouter.POST("network/service", k8s.CreateServiceController)
+		K8sClusterRouter.PUT("network/service", k8s.UpdateServiceController)
 
 		K8sClusterRouter.GET("network/ingress", k8s.GetIngressListController)
 		K8sClusterRouter.GET("network/ingress/detail", k8s.DetailIngressController)
 		K8sClusterRouter.DELETE("network/ingress", k8s.DeleteIngressController)
 		K8sClusterRouter.POST("network/ingresss", k8s.DeleteCollectionIngressController)
+		K8sClusterRouter.POST("network/ingress", k8s.CreateIngressController)
+		K8sClusterRouter.PUT("network/ingress", k8s.UpdateIngressController)
 
 		K8sClusterRouter.GET("config/configmap", k8s.GetConfigMapController)
 		K8sClusterRouter.GET("config/configmap/detail", k8s.DetailConfigMapController)
 		K8sClusterRouter.DELETE("config/configmap", k8s.DeleteConfigMapController)
 		K8sClusterRouter.POST("config/configmaps", k8s.DeleteCollectionConfigMapController)
+		K8sClusterRouter.POST("config/configmap", k8s.CreateConfigMapController)
+		K8sClusterRouter.PUT("config/configmap", k8s.UpdateConfigMapController)
+		K8sClusterRouter.POST("config/configmap/file", k8s.UploadConfigMapFileController)
 
 		K8sClusterRouter.GET("config/secret", k8s.GetSecretsController)
 		K8sClusterRouter.GET("config/secret/detail", k8s.DetailSecretsController)
 		K8sClusterRouter.DELETE("config/secret", k8s.DeleteSecretsController)
 		K8sClusterRouter.POST("config/secrets", k8s.DeleteCollectionSecretsController)
+		K8sClusterRouter.POST("config/secret", k8s.CreateSecretController)
+		K8sClusterRouter.PUT("config/secret", k8s.UpdateSecretController)
+		// 明文查看与详情分开授权, 需在casbin中为角色单独分配该路由
+		K8sClusterRouter.GET("config/secret/reveal", k8s.RevealSecretController)
+
+		K8sClusterRouter.GET("crd", k8s.GetCustomResourceDefinitionController)
+		K8sClusterRouter.GET("crd/detail", k8s.DetailCustomResourceDefinitionController)
+		K8sClusterRouter.GET("crd/object", k8s.GetCustomResourceObjectController)
+		K8sClusterRouter.GET("crd/object/yaml", k8s.GetCustomResourceObjectYAMLController)
+		K8sClusterRouter.DELETE("crd/object", k8s.DeleteCustomResourceObjectController)
+		K8sClusterRouter.GET("crd/object/event", k8s.GetCustomResourceObjectEventController)
+
+		K8sClusterRouter.POST("resource/apply", k8s.ApplyResourceController)
+		K8sClusterRouter.GET("resource/yaml", k8s.GetResourceYAMLController)
 
 		K8sClusterRouter.GET("/log/source/:namespace/:resourceName/:resourceType", k8s.GetLogSourcesController)
 		K8sClusterRouter.GET("/log/:namespace/:pod", k8s.GetLogDetailController)
 		K8sClusterRouter.GET("/log/:namespace/:pod/:container", k8s.GetLogDetailController)
 		K8sClusterRouter.GET("/log/file/:namespace/:pod/:container", k8s.GetLogFileController)
+		K8sClusterRouter.GET("/log/stream/:namespace/:resourceName/:resourceType", k8s.StreamLogController)
 	}
 }
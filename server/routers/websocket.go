@@ -28,5 +28,7 @@ func InitWebSocketRouter(r *gin.RouterGroup) {
 			c.String(200, "pong")
 		})
 		ws.GET("webssh", cmdb.WebSocketConnect)
+		ws.GET("sshrecord/replay", cmdb.ReplaySSHRecord)
+		ws.GET("sshsession/shadow", cmdb.ShadowSSHSession)
 	}
 }
@@ -17,49 +17,311 @@ limitations under the License.
 package services
 
 import (
+	"errors"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"kubespace/server/common"
 	"kubespace/server/models/cmdb"
 	"kubespace/server/models/request"
+	"kubespace/server/pkg/utils"
 )
 
-// ListPlatform 云平台信息
+// maskedSecret 列表中返回的SecretKey
+const maskedSecret = "******"
+
+// ListPlatform 云平台信息, AccessKey脱敏, 不返回SecretKey
 func ListPlatform(info request.PageInfo) (err error, list interface{}, total int64) {
 	limit := info.PageSize
 	offset := info.PageSize * (info.Page - 1)
 
 	var platformList []cmdb.CloudPlatform
-	err = common.DB.Find(&platformList).Count(&total).Error
+	if err = common.DB.Model(&cmdb.CloudPlatform{}).Count(&total).Error; err != nil {
+		return err, nil, 0
+	}
 	err = common.DB.Limit(limit).Offset(offset).Find(&platformList).Error
+	for i := range platformList {
+		maskCloudAccount(&platformList[i])
+	}
 	return err, platformList, total
 }
 
-// CreateCloudAccount 创建云账号
+// GetCloudAccount 获取云账号, 凭证保持加密
+func GetCloudAccount(id int) (*cmdb.CloudPlatform, error) {
+	var account cmdb.CloudPlatform
+	if err := common.DB.Where("id = ?", id).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("云账号不存在")
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetCloudAccountDetail 云账号详情, 凭证脱敏
+func GetCloudAccountDetail(id int) (*cmdb.CloudPlatform, error) {
+	account, err := GetCloudAccount(id)
+	if err != nil {
+		return nil, err
+	}
+	maskCloudAccount(account)
+	return account, nil
+}
+
+// NewCloudAccount 按创建请求生成云账号, 新账号默认启用以便注册同步调度
+func NewCloudAccount(req *request.CloudAccountCreate) *cmdb.CloudPlatform {
+	return &cmdb.CloudPlatform{
+		Name:      req.Name,
+		Type:      req.Type,
+		AccessKey: req.AccessKey,
+		SecretKey: req.SecretKey,
+		Region:    req.Region,
+		Remark:    req.Remark,
+		SyncCron:  req.SyncCron,
+		Enable:    req.Enable == nil || *req.Enable,
+	}
+}
+
+// CreateCloudAccount 创建云账号, AccessKey已存在时更新该账号
 func CreateCloudAccount(account *cmdb.CloudPlatform) (err error) {
+	exist, err := findCloudAccountByAccessKey(account.AccessKey)
+	if err != nil {
+		return err
+	}
+	if err = encryptCloudAccount(account); err != nil {
+		return err
+	}
 
-	results := common.DB.Table("cloud_platform").Where("access_key = ?", &account.AccessKey).First(&account)
+	if exist == nil {
+		results := common.DB.Table("cloud_platform").Create(account)
+		if results.Error != nil {
+			common.LOG.Error("创建云平台账号失败", zap.Any("err", results.Error))
+			return results.Error
+		}
+		return nil
+	}
 
+	account.ID = exist.ID
+	results := common.DB.Table("cloud_platform").Where("id = ?", exist.ID).Updates(map[string]interface{}{
+		"name":       account.Name,
+		"access_key": account.AccessKey,
+		"secret_key": account.SecretKey,
+		"encrypted":  true,
+		"remark":     account.Remark,
+		"enable":     account.Enable,
+	})
 	if results.Error != nil {
-		if results.Error == gorm.ErrRecordNotFound {
-			results := common.DB.Table("cloud_platform").Create(&account)
-			if results.Error != nil {
-				common.LOG.Error("创建云平台账号失败", zap.Any("err", results.Error))
-				return results.Error
+		common.LOG.Error("更新云平台账号失败", zap.Any("err", results.Error))
+		return results.Error
+	}
+	return nil
+}
+
+// findCloudAccountByAccessKey 按AccessKey查找云账号, 加密使用随机nonce, 需要解密后比较
+func findCloudAccountByAccessKey(accessKey string) (*cmdb.CloudPlatform, error) {
+	var accounts []cmdb.CloudPlatform
+	if err := common.DB.Select("id", "access_key", "encrypted").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	for i := range accounts {
+		key := accounts[i].AccessKey
+		if accounts[i].Encrypted {
+			var err error
+			if key, err = utils.DecryptSecret(key); err != nil {
+				common.LOG.Error("解密云账号凭证失败", zap.Any("id", accounts[i].ID), zap.Any("err", err))
+				continue
 			}
 		}
-	} else {
-		results := common.DB.Table("cloud_platform").Model(&account).Updates(map[string]interface{}{
-			"name":       &account.Name,
-			"access_key": &account.AccessKey,
-			"secret_key": &account.SecretKey,
-			"remark":     &account.Remark,
-		})
-		if results.Error != nil {
-			common.LOG.Error("更新云平台账号失败", zap.Any("err", results.Error))
-			return results.Error
+		if key == accessKey {
+			return &accounts[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateCloudAccount 更新云账号, AccessKey、SecretKey为空时保持不变
+func UpdateCloudAccount(req *request.CloudAccountUpdate) error {
+	values := map[string]interface{}{
+		"name":      req.Name,
+		"region":    req.Region,
+		"remark":    req.Remark,
+		"sync_cron": req.SyncCron,
+	}
+	if req.AccessKey != "" && req.SecretKey != "" {
+		account := cmdb.CloudPlatform{AccessKey: req.AccessKey, SecretKey: req.SecretKey}
+		if err := encryptCloudAccount(&account); err != nil {
+			return err
+		}
+		values["access_key"] = account.AccessKey
+		values["secret_key"] = account.SecretKey
+		values["encrypted"] = true
+	}
+	results := common.DB.Model(&cmdb.CloudPlatform{}).Where("id = ?", req.ID).Updates(values)
+	if results.Error != nil {
+		common.LOG.Error("更新云平台账号失败", zap.Any("err", results.Error))
+		return results.Error
+	}
+	return nil
+}
+
+// SetCloudAccountEnable 启用或禁用云账号
+func SetCloudAccountEnable(id int, enable bool) error {
+	return common.DB.Model(&cmdb.CloudPlatform{}).Where("id = ?", id).Update("enable", enable).Error
+}
+
+// DeleteCloudAccount 删除云账号, 已同步的主机保留
+func DeleteCloudAccount(id int) error {
+	return common.DB.Delete(&cmdb.CloudPlatform{}, id).Error
+}
+
+// EncryptCloudAccounts 加密历史数据中明文存储的云账号凭证, 并使用当前密钥重新加密旧版本的密文
+func EncryptCloudAccounts() {
+	var accounts []cmdb.CloudPlatform
+	if err := common.DB.Find(&accounts).Error; err != nil {
+		common.LOG.Error("查询云账号失败", zap.Any("err", err))
+		return
+	}
+	for i := range accounts {
+		account := &accounts[i]
+		if account.Encrypted && !utils.IsLegacySecret(account.AccessKey) && !utils.IsLegacySecret(account.SecretKey) {
+			continue
+		}
+		if err := decryptCloudAccount(account); err != nil {
+			common.LOG.Error("解密云账号凭证失败", zap.Any("id", account.ID), zap.Any("err", err))
+			continue
+		}
+		if err := encryptCloudAccount(account); err != nil {
+			common.LOG.Error("加密云账号凭证失败", zap.Any("id", account.ID), zap.Any("err", err))
+			continue
+		}
+		err := common.DB.Model(&cmdb.CloudPlatform{}).Where("id = ?", account.ID).Updates(map[string]interface{}{
+			"access_key": account.AccessKey,
+			"secret_key": account.SecretKey,
+			"encrypted":  true,
+		}).Error
+		if err != nil {
+			common.LOG.Error("加密云账号凭证失败", zap.Any("id", account.ID), zap.Any("err", err))
 		}
 	}
+}
 
+func encryptCloudAccount(account *cmdb.CloudPlatform) (err error) {
+	if account.Encrypted {
+		return nil
+	}
+	if account.AccessKey, err = utils.EncryptSecret(account.AccessKey); err != nil {
+		return err
+	}
+	if account.SecretKey, err = utils.EncryptSecret(account.SecretKey); err != nil {
+		return err
+	}
+	account.Encrypted = true
 	return nil
 }
+
+// decryptCloudAccount 解密云账号凭证, 未加密的历史数据保持不变
+func decryptCloudAccount(account *cmdb.CloudPlatform) (err error) {
+	if !account.Encrypted {
+		return nil
+	}
+	if account.AccessKey, err = utils.DecryptSecret(account.AccessKey); err != nil {
+		return err
+	}
+	if account.SecretKey, err = utils.DecryptSecret(account.SecretKey); err != nil {
+		return err
+	}
+	account.Encrypted = false
+	return nil
+}
+
+// maskCloudAccount 凭证脱敏, 解密失败时AccessKey也不返回
+func maskCloudAccount(account *cmdb.CloudPlatform) {
+	accessKey := account.AccessKey
+	if account.Encrypted {
+		var err error
+		if accessKey, err = utils.DecryptSecret(accessKey); err != nil {
+			common.LOG.Error("解密云账号凭证失败", zap.Any("id", account.ID), zap.Any("err", err))
+			accessKey = ""
+		}
+	}
+	account.AccessKey = MaskAccessKey(accessKey)
+	account.SecretKey = maskedSecret
+}
+
+// MaskAccessKey 保留AccessKey前后4位
+func MaskAccessKey(accessKey string) string {
+	if len(accessKey) <= 8 {
+		return maskedSecret
+	}
+	return accessKey[:4] + maskedSecret + accessKey[len(accessKey)-4:]
+}
+
+// ListCloudSyncRecord 云资产同步记录, 按开始时间倒序
+func ListCloudSyncRecord(query request.CloudSyncRecordQuery) (err error, list interface{}, total int64) {
+	limit := query.PageSize
+	offset := query.PageSize * (query.Page - 1)
+
+	var recordList []cmdb.CloudSyncRecord
+	db := common.DB.Model(&cmdb.CloudSyncRecord{})
+	if query.PlatformId != 0 {
+		db = db.Where("platform_id = ?", query.PlatformId)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return err, nil, 0
+	}
+	err = db.Order("start_time desc").Limit(limit).Offset(offset).Find(&recordList).Error
+	return err, recordList, total
+}
+
+// listCloudResource 按账号、地域分页查询云资源, list为对应资源的切片指针
+func listCloudResource(model, list interface{}, query request.CloudResourceQuery) (err error, total int64) {
+	limit := query.PageSize
+	offset := query.PageSize * (query.Page - 1)
+
+	db := common.DB.Model(model)
+	if query.PlatformId != 0 {
+		db = db.Where("platform_id = ?", query.PlatformId)
+	}
+	if query.RegionId != "" {
+		db = db.Where("region_id = ?", query.RegionId)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return err, 0
+	}
+	err = db.Order("id desc").Limit(limit).Offset(offset).Find(list).Error
+	return err, total
+}
+
+// ListCloudDisk 云硬盘列表
+func ListCloudDisk(query request.CloudResourceQuery) (err error, list interface{}, total int64) {
+	var disks []cmdb.CloudDisk
+	err, total = listCloudResource(&cmdb.CloudDisk{}, &disks, query)
+	return err, disks, total
+}
+
+// ListCloudLoadBalancer 负载均衡列表
+func ListCloudLoadBalancer(query request.CloudResourceQuery) (err error, list interface{}, total int64) {
+	var lbs []cmdb.CloudLoadBalancer
+	err, total = listCloudResource(&cmdb.CloudLoadBalancer{}, &lbs, query)
+	return err, lbs, total
+}
+
+// ListCloudDatabase 云数据库列表
+func ListCloudDatabase(query request.CloudResourceQuery) (err error, list interface{}, total int64) {
+	var dbs []cmdb.CloudDatabase
+	err, total = listCloudResource(&cmdb.CloudDatabase{}, &dbs, query)
+	return err, dbs, total
+}
+
+// ListCloudVpc VPC列表
+func ListCloudVpc(query request.CloudResourceQuery) (err error, list interface{}, total int64) {
+	var vpcs []cmdb.CloudVpc
+	err, total = listCloudResource(&cmdb.CloudVpc{}, &vpcs, query)
+	return err, vpcs, total
+}
+
+// ListCloudSubnet 子网列表
+func ListCloudSubnet(query request.CloudResourceQuery) (err error, list interface{}, total int64) {
+	var subnets []cmdb.CloudSubnet
+	err, total = listCloudResource(&cmdb.CloudSubnet{}, &subnets, query)
+	return err, subnets, total
+}
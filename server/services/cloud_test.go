@@ -0,0 +1,296 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"io"
+	"kubespace/server/common"
+	"kubespace/server/models/cmdb"
+	"kubespace/server/models/request"
+	"kubespace/server/pkg/utils"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeConn 按查询语句返回预设数据, 记录执行的更新语句, 用于不依赖MySQL测试服务层
+type fakeConn struct {
+	mu    sync.Mutex
+	rows  func(query string) ([]string, [][]driver.Value)
+	execs []fakeExec
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+var setColumn = regexp.MustCompile("`(\\w+)`=\\?")
+
+// values 按SET子句中的列名返回更新的值
+func (e fakeExec) values() map[string]driver.Value {
+	values := map[string]driver.Value{}
+	set := e.query[strings.Index(e.query, " SET ")+5:]
+	if i := strings.Index(set, " WHERE "); i >= 0 {
+		set = set[:i]
+	}
+	for i, m := range setColumn.FindAllStringSubmatch(set, -1) {
+		values[m[1]] = e.args[i]
+	}
+	return values
+}
+
+func (f *fakeConn) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeConn) Driver() driver.Driver                        { return nil }
+func (f *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (f *fakeConn) Close() error              { return nil }
+func (f *fakeConn) Begin() (driver.Tx, error) { return f, nil }
+func (f *fakeConn) Commit() error             { return nil }
+func (f *fakeConn) Rollback() error           { return nil }
+
+func (f *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	exec := fakeExec{query: query}
+	for _, arg := range args {
+		exec.args = append(exec.args, arg.Value)
+	}
+	f.execs = append(f.execs, exec)
+	return fakeResult{}, nil
+}
+
+type fakeResult struct{}
+
+func (fakeResult) LastInsertId() (int64, error) { return 1, nil }
+func (fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (f *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	columns, values := f.rows(query)
+	return &fakeRows{columns: columns, values: values}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func useFakeDB(t *testing.T, rows func(query string) ([]string, [][]driver.Value)) *fakeConn {
+	common.LOG = zap.NewNop()
+	if err := utils.SetSecretKey("test-secret-key-0123456789"); err != nil {
+		t.Fatal(err)
+	}
+	conn := &fakeConn{rows: rows}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(conn), SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	common.DB = db
+	return conn
+}
+
+var cloudAccountColumns = []string{"id", "type", "access_key", "secret_key", "encrypted"}
+
+func encrypt(t *testing.T, s string) string {
+	encrypted, err := utils.EncryptSecret(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encrypted
+}
+
+func TestCloudAccountMask(t *testing.T) {
+	var rows [][]driver.Value
+	useFakeDB(t, func(query string) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "count(*)") {
+			return []string{"count"}, [][]driver.Value{{int64(len(rows))}}
+		}
+		return cloudAccountColumns, rows
+	})
+	rows = [][]driver.Value{
+		{int64(1), cmdb.AliYun, encrypt(t, "LTAI5tAbCdEf9876"), encrypt(t, "aliyun-secret"), true},
+		{int64(2), cmdb.Tencent, "AKIDxyz123456789", "tencent-secret", false}, // 未迁移的明文
+	}
+
+	err, list, total := ListPlatform(request.PageInfo{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts := list.([]cmdb.CloudPlatform)
+	if total != 2 || len(accounts) != 2 {
+		t.Fatalf("want 2 accounts, got %d/%d", len(accounts), total)
+	}
+	for i, want := range []string{"LTAI******9876", "AKID******6789"} {
+		if accounts[i].AccessKey != want || accounts[i].SecretKey != maskedSecret {
+			t.Errorf("account %d not masked: %s %s", i, accounts[i].AccessKey, accounts[i].SecretKey)
+		}
+	}
+
+	account, err := GetCloudAccountDetail(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.AccessKey != "LTAI******9876" || account.SecretKey != maskedSecret {
+		t.Errorf("detail not masked: %s %s", account.AccessKey, account.SecretKey)
+	}
+}
+
+func TestUpdateCloudAccountKeepSecret(t *testing.T) {
+	conn := useFakeDB(t, func(string) ([]string, [][]driver.Value) { return nil, nil })
+
+	// 未填写凭证时保持原值
+	if err := UpdateCloudAccount(&request.CloudAccountUpdate{ID: 1, Name: "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.execs) != 1 {
+		t.Fatalf("want 1 update, got %d", len(conn.execs))
+	}
+	values := conn.execs[0].values()
+	if values["name"] != "prod" {
+		t.Errorf("name not updated: %v", values)
+	}
+	for _, column := range []string{"access_key", "secret_key", "encrypted"} {
+		if _, ok := values[column]; ok {
+			t.Errorf("%s should not be updated when secret is empty", column)
+		}
+	}
+
+	// 同时填写AccessKey、SecretKey时加密保存
+	conn.execs = nil
+	err := UpdateCloudAccount(&request.CloudAccountUpdate{ID: 1, Name: "prod", AccessKey: "LTAInew", SecretKey: "new-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values = conn.execs[0].values()
+	for column, want := range map[string]string{"access_key": "LTAInew", "secret_key": "new-secret"} {
+		stored, _ := values[column].(string)
+		if stored == want || utils.IsLegacySecret(stored) {
+			t.Errorf("%s not encrypted: %q", column, stored)
+		}
+		if got, err := utils.DecryptSecret(stored); err != nil || got != want {
+			t.Errorf("%s: want %q, got %q, err %v", column, want, got, err)
+		}
+	}
+	if values["encrypted"] != true {
+		t.Errorf("encrypted flag not set: %v", values["encrypted"])
+	}
+}
+
+func TestEncryptCloudAccounts(t *testing.T) {
+	var rows [][]driver.Value
+	conn := useFakeDB(t, func(string) ([]string, [][]driver.Value) { return cloudAccountColumns, rows })
+	rows = [][]driver.Value{
+		{int64(1), cmdb.AliYun, "LTAIplain1234567", "plain-secret", false},
+		// 旧版本使用固定密钥AES-CBC加密: AKIDlegacy123456 / legacy-secret
+		{int64(2), cmdb.Tencent, "df5b0d86afd5af1a0b7c333b1a2fe3ca978fe1eef391a196fbdc97c2fc78a835", "9d940f25497bec844cfb31044d9c4802", true},
+		{int64(3), cmdb.AWS, encrypt(t, "AKIAcurrent12345"), encrypt(t, "current-secret"), true},
+	}
+
+	EncryptCloudAccounts()
+
+	want := []struct {
+		id                   int64
+		accessKey, secretKey string
+	}{
+		{1, "LTAIplain1234567", "plain-secret"},
+		{2, "AKIDlegacy123456", "legacy-secret"},
+	}
+	if len(conn.execs) != len(want) {
+		t.Fatalf("want %d updates, got %d", len(want), len(conn.execs))
+	}
+	for i, w := range want {
+		exec := conn.execs[i]
+		if id := exec.args[len(exec.args)-1]; id != w.id {
+			t.Errorf("update %d: want id %d, got %v", i, w.id, id)
+		}
+		values := exec.values()
+		accessKey, _ := utils.DecryptSecret(values["access_key"].(string))
+		secretKey, _ := utils.DecryptSecret(values["secret_key"].(string))
+		if utils.IsLegacySecret(values["access_key"].(string)) || accessKey != w.accessKey || secretKey != w.secretKey {
+			t.Errorf("update %d: got %q/%q", i, accessKey, secretKey)
+		}
+		if values["encrypted"] != true {
+			t.Errorf("update %d: encrypted flag not set", i)
+		}
+	}
+}
+
+var insertColumns = regexp.MustCompile("\\(((?:`\\w+`,?)+)\\) VALUES")
+
+// insertValues 按INSERT语句的列名返回写入的值
+func (e fakeExec) insertValues() map[string]driver.Value {
+	values := map[string]driver.Value{}
+	m := insertColumns.FindStringSubmatch(e.query)
+	if m == nil {
+		return values
+	}
+	for i, column := range strings.Split(m[1], ",") {
+		values[strings.Trim(column, "`")] = e.args[i]
+	}
+	return values
+}
+
+func TestCreateCloudAccountEnabled(t *testing.T) {
+	conn := useFakeDB(t, func(string) ([]string, [][]driver.Value) { return nil, nil })
+
+	for _, tc := range []struct {
+		body string
+		want bool
+	}{
+		// 表单未提交enable时默认启用, 才会被注册同步调度和执行首次同步
+		{`{"name":"prod","type":"AliYun","access_key":"LTAInew","secret_key":"new-secret"}`, true},
+		{`{"name":"prod","type":"AliYun","access_key":"LTAInew","secret_key":"new-secret","enable":false}`, false},
+	} {
+		var req request.CloudAccountCreate
+		if err := json.Unmarshal([]byte(tc.body), &req); err != nil {
+			t.Fatal(err)
+		}
+		conn.execs = nil
+		if err := CreateCloudAccount(NewCloudAccount(&req)); err != nil {
+			t.Fatal(err)
+		}
+		if len(conn.execs) != 1 {
+			t.Fatalf("want 1 insert, got %d", len(conn.execs))
+		}
+		if got := conn.execs[0].insertValues()["enable"]; got != tc.want {
+			t.Errorf("%s: stored enable = %v, want %v", tc.body, got, tc.want)
+		}
+	}
+}
@@ -17,10 +17,15 @@ limitations under the License.
 package cmdb
 
 import (
+	"errors"
 	"fmt"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
 	"kubespace/server/common"
 	"kubespace/server/models"
 	"kubespace/server/models/cmdb"
+	"kubespace/server/models/request"
+	"kubespace/server/pkg/utils"
 	"strconv"
 )
 
@@ -65,3 +70,220 @@ func ListVirtualMachine(tree string, p *models.PaginationQ) (host []cmdb.Virtual
 
 	return host, nil
 }
+
+// HostImportResult 批量导入结果
+type HostImportResult struct {
+	Added   int      `json:"added"`
+	Updated int      `json:"updated"`
+	Errors  []string `json:"errors"`
+}
+
+// encryptSecret 加密主机的SSH凭证, 空值不加密
+func encryptSecret(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	return utils.EncryptSecret(s)
+}
+
+// getHostGroups 获取主机分组, 未指定时放到默认的Default分组
+func getHostGroups(ids []int) ([]*cmdb.TreeMenu, error) {
+	if len(ids) == 0 {
+		return []*cmdb.TreeMenu{{ID: 1}}, nil
+	}
+	var groups []*cmdb.TreeMenu
+	if err := common.DB.Where("id IN ?", ids).Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	if len(groups) != len(ids) {
+		return nil, errors.New("主机分组不存在")
+	}
+	return groups, nil
+}
+
+// setHostCredential 更新主机SSH配置, 密码、私钥加密存储, 为空时保持原值
+func setHostCredential(host *cmdb.VirtualMachine, form *request.HostForm) (err error) {
+	if form.Port != "" {
+		host.Port = form.Port
+	}
+	if form.UserName != "" {
+		host.UserName = form.UserName
+	}
+	if form.Password != "" {
+		if host.Password, err = encryptSecret(form.Password); err != nil {
+			return err
+		}
+	}
+	if form.PrivateKey != "" {
+		if host.PrivateKey, err = encryptSecret(form.PrivateKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setHostInfo 更新手动维护主机的基础信息, 云主机的这些字段由云同步维护
+func setHostInfo(host *cmdb.VirtualMachine, form *request.HostForm) {
+	host.HostName = form.HostName
+	host.PrivateAddr = form.PrivateAddr
+	host.PublicAddr = form.PublicAddr
+	host.CPU = form.CPU
+	host.Mem = form.Mem
+	host.OS = form.OS
+	host.OSType = form.OSType
+	host.Region = form.Region
+	host.SN = form.SN
+}
+
+// CreateHost 手动添加主机
+func CreateHost(form *request.HostForm) (*cmdb.VirtualMachine, error) {
+	var count int64
+	if err := common.DB.Model(&cmdb.VirtualMachine{}).
+		Where("private_addr = ?", form.PrivateAddr).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count != 0 {
+		return nil, fmt.Errorf("私网地址%s的主机已存在", form.PrivateAddr)
+	}
+
+	groups, err := getHostGroups(form.GroupIds)
+	if err != nil {
+		return nil, err
+	}
+	host := &cmdb.VirtualMachine{
+		Groups: groups,
+		UUID:   uuid.NewV4().String(),
+		Source: cmdb.HostSourceManual,
+		Status: "Running",
+		Remark: form.Remark,
+		Port:   "22",
+	}
+	setHostInfo(host, form)
+	if err := setHostCredential(host, form); err != nil {
+		return nil, err
+	}
+	if err := common.DB.Create(host).Error; err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// UpdateHost 更新主机, 云同步的主机只允许修改SSH配置、分组和备注
+func UpdateHost(form *request.HostForm) error {
+	var host cmdb.VirtualMachine
+	if err := common.DB.Where("id = ?", form.ID).First(&host).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("主机不存在")
+		}
+		return err
+	}
+
+	// 只更新允许修改的字段, 避免覆盖云同步维护的字段
+	columns := []string{"port", "username", "password", "private_key", "remark"}
+	if !cmdb.IsCloudSource(host.Source) {
+		var count int64
+		if err := common.DB.Model(&cmdb.VirtualMachine{}).
+			Where("private_addr = ? AND id <> ?", form.PrivateAddr, host.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != 0 {
+			return fmt.Errorf("私网地址%s的主机已存在", form.PrivateAddr)
+		}
+		setHostInfo(&host, form)
+		columns = append(columns, "hostname", "private_addr", "public_addr", "cpu", "mem", "os", "os_type", "region", "sn")
+	}
+	if err := setHostCredential(&host, form); err != nil {
+		return err
+	}
+	host.Remark = form.Remark
+
+	return common.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&host).Select(columns).Updates(&host).Error; err != nil {
+			return err
+		}
+		if form.GroupIds == nil {
+			return nil
+		}
+		groups, err := getHostGroups(form.GroupIds)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&host).Association("Groups").Replace(groups)
+	})
+}
+
+// DeleteHosts 删除手动维护的主机, 云主机由云同步维护, 不允许删除
+func DeleteHosts(ids []int) error {
+	var hosts []cmdb.VirtualMachine
+	if err := common.DB.Where("id IN ?", ids).Find(&hosts).Error; err != nil {
+		return err
+	}
+	for _, h := range hosts {
+		if cmdb.IsCloudSource(h.Source) {
+			return fmt.Errorf("主机%s由云同步维护, 不允许删除", h.HostName)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+	return common.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range hosts {
+			if err := tx.Model(&hosts[i]).Association("Groups").Clear(); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&hosts).Error
+	})
+}
+
+// ImportHosts 批量导入主机, 私网地址已存在的手动主机会被更新, 与云主机冲突的行跳过
+func ImportHosts(forms []request.HostForm) *HostImportResult {
+	result := &HostImportResult{Errors: make([]string, 0)}
+	for i := range forms {
+		form := &forms[i]
+		// 第一行是表头
+		line := i + 2
+		if form.HostName == "" || form.PrivateAddr == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("第%d行: 主机名和私网地址不能为空", line))
+			continue
+		}
+
+		var exist cmdb.VirtualMachine
+		err := common.DB.Where("private_addr = ?", form.PrivateAddr).First(&exist).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if _, err := CreateHost(form); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("第%d行: %v", line, err))
+				continue
+			}
+			result.Added++
+		case err != nil:
+			result.Errors = append(result.Errors, fmt.Sprintf("第%d行: %v", line, err))
+		case cmdb.IsCloudSource(exist.Source):
+			result.Errors = append(result.Errors, fmt.Sprintf("第%d行: 私网地址%s属于云主机, 跳过", line, form.PrivateAddr))
+		default:
+			form.ID = exist.ID
+			if err := UpdateHost(form); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("第%d行: %v", line, err))
+				continue
+			}
+			result.Updated++
+		}
+	}
+	return result
+}
+
+// ExportHosts 导出分组下的全部主机, 不分页
+func ExportHosts(tree string) ([]cmdb.VirtualMachine, error) {
+	var hosts []cmdb.VirtualMachine
+	if tree == "" {
+		err := common.DB.Order("id").Find(&hosts).Error
+		return hosts, err
+	}
+	id, err := strconv.Atoi(tree)
+	if err != nil {
+		return nil, err
+	}
+	err = common.DB.Model(&cmdb.TreeMenu{ID: id}).Association("VirtualMachines").Find(&hosts)
+	return hosts, err
+}
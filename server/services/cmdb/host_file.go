@@ -0,0 +1,212 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"kubespace/server/models/cmdb"
+	"kubespace/server/models/request"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 主机导入导出文件格式
+const (
+	HostFileCSV   = "csv"
+	HostFileExcel = "xlsx"
+)
+
+// hostColumn 导入导出文件的列, 导入时表头可以使用key或title
+type hostColumn struct {
+	key   string
+	title string
+	// get 导出时取值, 为nil时该列只用于导入
+	get func(h *cmdb.VirtualMachine) string
+	set func(f *request.HostForm, v string) error
+}
+
+func parseInt(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s不是数字", v)
+	}
+	return n, nil
+}
+
+var hostColumns = []hostColumn{
+	{"hostname", "主机名", func(h *cmdb.VirtualMachine) string { return h.HostName },
+		func(f *request.HostForm, v string) error { f.HostName = v; return nil }},
+	{"private_addr", "私网地址", func(h *cmdb.VirtualMachine) string { return h.PrivateAddr },
+		func(f *request.HostForm, v string) error { f.PrivateAddr = v; return nil }},
+	{"public_addr", "公网地址", func(h *cmdb.VirtualMachine) string { return h.PublicAddr },
+		func(f *request.HostForm, v string) error { f.PublicAddr = v; return nil }},
+	{"port", "SSH端口", func(h *cmdb.VirtualMachine) string { return h.Port },
+		func(f *request.HostForm, v string) error { f.Port = v; return nil }},
+	{"username", "SSH用户", func(h *cmdb.VirtualMachine) string { return h.UserName },
+		func(f *request.HostForm, v string) error { f.UserName = v; return nil }},
+	{"password", "SSH密码", nil,
+		func(f *request.HostForm, v string) error { f.Password = v; return nil }},
+	{"private_key", "SSH私钥", nil,
+		func(f *request.HostForm, v string) error { f.PrivateKey = v; return nil }},
+	{"cpu", "CPU", func(h *cmdb.VirtualMachine) string { return strconv.Itoa(h.CPU) },
+		func(f *request.HostForm, v string) (err error) { f.CPU, err = parseInt(v); return }},
+	{"memory", "内存(MB)", func(h *cmdb.VirtualMachine) string { return strconv.Itoa(h.Mem) },
+		func(f *request.HostForm, v string) (err error) { f.Mem, err = parseInt(v); return }},
+	{"os", "操作系统", func(h *cmdb.VirtualMachine) string { return h.OS },
+		func(f *request.HostForm, v string) error { f.OS = v; return nil }},
+	{"os_type", "系统类型", func(h *cmdb.VirtualMachine) string { return h.OSType },
+		func(f *request.HostForm, v string) error { f.OSType = v; return nil }},
+	{"region", "机房", func(h *cmdb.VirtualMachine) string { return h.Region },
+		func(f *request.HostForm, v string) error { f.Region = v; return nil }},
+	{"sn", "SN序列号", func(h *cmdb.VirtualMachine) string { return h.SN },
+		func(f *request.HostForm, v string) error { f.SN = v; return nil }},
+	{"remark", "备注", func(h *cmdb.VirtualMachine) string { return h.Remark },
+		func(f *request.HostForm, v string) error { f.Remark = v; return nil }},
+	{"source", "来源", func(h *cmdb.VirtualMachine) string { return h.Source }, nil},
+	{"status", "状态", func(h *cmdb.VirtualMachine) string { return h.Status }, nil},
+}
+
+// HostFileFormat 根据文件名判断导入文件格式
+func HostFileFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return HostFileCSV, nil
+	case ".xlsx":
+		return HostFileExcel, nil
+	}
+	return "", errors.New("仅支持csv、xlsx格式的文件")
+}
+
+// ParseHostFile 解析导入的主机文件, 第一行为表头
+func ParseHostFile(format string, r io.Reader) ([]request.HostForm, error) {
+	var rows [][]string
+	switch format {
+	case HostFileCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("解析csv文件失败: %v", err)
+		}
+		rows = records
+	case HostFileExcel:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("解析excel文件失败: %v", err)
+		}
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("excel文件中没有工作表")
+		}
+		if rows, err = f.GetRows(sheets[0]); err != nil {
+			return nil, fmt.Errorf("解析excel文件失败: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的文件格式: %s", format)
+	}
+	return parseHostRows(rows)
+}
+
+func parseHostRows(rows [][]string) ([]request.HostForm, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("文件内容为空")
+	}
+
+	// 表头对应的列
+	columns := make([]*hostColumn, len(rows[0]))
+	found := false
+	for i, name := range rows[0] {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		for j := range hostColumns {
+			col := &hostColumns[j]
+			if col.set != nil && (strings.EqualFold(name, col.key) || name == col.title) {
+				columns[i] = col
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil, errors.New("文件表头不正确, 请使用导出的文件作为模板")
+	}
+
+	forms := make([]request.HostForm, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		var form request.HostForm
+		for i, v := range row {
+			if i >= len(columns) || columns[i] == nil {
+				continue
+			}
+			if err := columns[i].set(&form, strings.TrimSpace(v)); err != nil {
+				return nil, fmt.Errorf("第%d行%s: %v", n+2, columns[i].title, err)
+			}
+		}
+		forms = append(forms, form)
+	}
+	return forms, nil
+}
+
+// WriteHostFile 导出主机, 不包含SSH密码和私钥
+func WriteHostFile(format string, w io.Writer, hosts []cmdb.VirtualMachine) error {
+	header := make([]string, 0, len(hostColumns))
+	for _, col := range hostColumns {
+		if col.get != nil {
+			header = append(header, col.title)
+		}
+	}
+	rows := [][]string{header}
+	for i := range hosts {
+		row := make([]string, 0, len(header))
+		for _, col := range hostColumns {
+			if col.get != nil {
+				row = append(row, col.get(&hosts[i]))
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	switch format {
+	case HostFileCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case HostFileExcel:
+		f := excelize.NewFile()
+		sheet := f.GetSheetName(0)
+		for i, row := range rows {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+			if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+				return err
+			}
+		}
+		_, err := f.WriteTo(w)
+		return err
+	}
+	return fmt.Errorf("不支持的文件格式: %s", format)
+}
@@ -0,0 +1,70 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"bytes"
+	"kubespace/server/models/cmdb"
+	"testing"
+)
+
+func TestHostFileRoundTrip(t *testing.T) {
+	hosts := []cmdb.VirtualMachine{
+		{HostName: "idc-01", PrivateAddr: "10.0.0.1", Port: "2222", UserName: "root", CPU: 8, Mem: 16384, Password: "secret"},
+	}
+	for _, format := range []string{HostFileCSV, HostFileExcel} {
+		var buf bytes.Buffer
+		if err := WriteHostFile(format, &buf, hosts); err != nil {
+			t.Fatalf("%s: write failed: %v", format, err)
+		}
+		if bytes.Contains(buf.Bytes(), []byte("secret")) {
+			t.Fatalf("%s: exported file must not contain password", format)
+		}
+		forms, err := ParseHostFile(format, &buf)
+		if err != nil {
+			t.Fatalf("%s: parse failed: %v", format, err)
+		}
+		if len(forms) != 1 {
+			t.Fatalf("%s: expected 1 host, got %d", format, len(forms))
+		}
+		f := forms[0]
+		if f.HostName != "idc-01" || f.PrivateAddr != "10.0.0.1" || f.Port != "2222" ||
+			f.UserName != "root" || f.CPU != 8 || f.Mem != 16384 {
+			t.Fatalf("%s: unexpected host %+v", format, f)
+		}
+	}
+}
+
+func TestParseHostRows(t *testing.T) {
+	forms, err := parseHostRows([][]string{
+		{"hostname", "private_addr", "password", "unknown"},
+		{"bm-01", "192.168.1.10", "p@ss", "x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forms[0].HostName != "bm-01" || forms[0].Password != "p@ss" {
+		t.Fatalf("unexpected host %+v", forms[0])
+	}
+
+	if _, err := parseHostRows([][]string{{"foo", "bar"}}); err == nil {
+		t.Fatal("expected error for unknown header")
+	}
+	if _, err := parseHostRows([][]string{{"cpu"}, {"eight"}}); err == nil {
+		t.Fatal("expected error for invalid number")
+	}
+}
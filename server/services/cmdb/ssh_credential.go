@@ -0,0 +1,401 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+	"kubespace/server/common"
+	"kubespace/server/models/cmdb"
+	"kubespace/server/models/request"
+	"kubespace/server/pkg/utils"
+	"kubespace/server/pkg/websocket"
+	"sort"
+)
+
+// ListSSHCredential SSH凭证列表, 不返回密码和私钥
+func ListSSHCredential(query request.PageInfo) (err error, list []cmdb.SSHCredential, total int64) {
+	limit := query.PageSize
+	offset := query.PageSize * (query.Page - 1)
+
+	db := common.DB.Model(&cmdb.SSHCredential{})
+	if err = db.Count(&total).Error; err != nil {
+		return err, nil, 0
+	}
+	err = db.Order("id desc").Limit(limit).Offset(offset).Find(&list).Error
+	return err, list, total
+}
+
+// decryptSecret 解密SSH凭证, 空值不解密
+func decryptSecret(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	plain, err := utils.DecryptSecret(s)
+	if err != nil {
+		return "", fmt.Errorf("解密SSH凭证失败: %v", err)
+	}
+	return plain, nil
+}
+
+// keepSecret 表单未填写时沿用原值
+func keepSecret(value, stored string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	return decryptSecret(stored)
+}
+
+// reencryptSecrets 使用当前密钥重新加密旧版本的密文, 返回是否有更新
+func reencryptSecrets(secrets ...*string) (changed bool, err error) {
+	for _, s := range secrets {
+		if *s == "" || !utils.IsLegacySecret(*s) {
+			continue
+		}
+		plain, err := decryptSecret(*s)
+		if err != nil {
+			return false, err
+		}
+		if *s, err = encryptSecret(plain); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// EncryptLegacySSHSecrets 使用当前密钥重新加密主机、SSH凭证和全局配置中旧版本的密文, 启动时调用
+func EncryptLegacySSHSecrets() {
+	var hosts []cmdb.VirtualMachine
+	if err := common.DB.Select("id", "password", "private_key").Find(&hosts).Error; err != nil {
+		common.LOG.Error("查询主机凭证失败", zap.Any("err", err))
+	}
+	for i := range hosts {
+		host := &hosts[i]
+		if changed, err := reencryptSecrets(&host.Password, &host.PrivateKey); err != nil || !changed {
+			logReencryptError("主机", host.ID, err)
+			continue
+		}
+		err := common.DB.Model(host).UpdateColumns(map[string]interface{}{"password": host.Password, "private_key": host.PrivateKey}).Error
+		logReencryptError("主机", host.ID, err)
+	}
+
+	var creds []cmdb.SSHCredential
+	if err := common.DB.Select("id", "password", "private_key", "passphrase").Find(&creds).Error; err != nil {
+		common.LOG.Error("查询SSH凭证失败", zap.Any("err", err))
+	}
+	for i := range creds {
+		cred := &creds[i]
+		if changed, err := reencryptSecrets(&cred.Password, &cred.PrivateKey, &cred.Passphrase); err != nil || !changed {
+			logReencryptError("SSH凭证", cred.ID, err)
+			continue
+		}
+		err := common.DB.Model(cred).UpdateColumns(map[string]interface{}{
+			"password":    cred.Password,
+			"private_key": cred.PrivateKey,
+			"passphrase":  cred.Passphrase,
+		}).Error
+		logReencryptError("SSH凭证", cred.ID, err)
+	}
+
+	var configs []cmdb.SSHGlobalConfig
+	if err := common.DB.Select("id", "password", "private_key").Find(&configs).Error; err != nil {
+		common.LOG.Error("查询SSH全局配置失败", zap.Any("err", err))
+	}
+	for i := range configs {
+		config := &configs[i]
+		if changed, err := reencryptSecrets(&config.Password, &config.PrivateKey); err != nil || !changed {
+			logReencryptError("SSH全局配置", config.ID, err)
+			continue
+		}
+		err := common.DB.Model(config).UpdateColumns(map[string]interface{}{"password": config.Password, "private_key": config.PrivateKey}).Error
+		logReencryptError("SSH全局配置", config.ID, err)
+	}
+}
+
+func logReencryptError(kind string, id interface{}, err error) {
+	if err != nil {
+		common.LOG.Error(fmt.Sprintf("重新加密%s凭证失败", kind), zap.Any("id", id), zap.Any("err", err))
+	}
+}
+
+// setSSHCredential 按凭证类型校验并加密保存, 更新时密码、私钥、私钥密码为空则沿用原值
+func setSSHCredential(cred *cmdb.SSHCredential, form *request.SSHCredentialForm) error {
+	password, err := keepSecret(form.Password, cred.Password)
+	if err != nil {
+		return err
+	}
+	privateKey, err := keepSecret(form.PrivateKey, cred.PrivateKey)
+	if err != nil {
+		return err
+	}
+	passphrase, err := keepSecret(form.Passphrase, cred.Passphrase)
+	if err != nil {
+		return err
+	}
+	certificate := form.Certificate
+
+	switch form.Type {
+	case cmdb.SSHCredentialPassword:
+		if password == "" {
+			return errors.New("密码不能为空")
+		}
+		privateKey, passphrase, certificate = "", "", ""
+		cred.Fingerprint = ""
+	case cmdb.SSHCredentialKey, cmdb.SSHCredentialCertificate:
+		if privateKey == "" {
+			return errors.New("私钥不能为空")
+		}
+		if form.Type == cmdb.SSHCredentialKey {
+			certificate = ""
+		} else if certificate == "" {
+			return errors.New("证书不能为空")
+		}
+		signer, err := websocket.ParseSigner(privateKey, passphrase, certificate)
+		if err != nil {
+			return err
+		}
+		pub := signer.PublicKey()
+		if cert, ok := pub.(*ssh.Certificate); ok {
+			pub = cert.Key
+		}
+		cred.Fingerprint = ssh.FingerprintSHA256(pub)
+		password = ""
+	default:
+		return errors.New("不支持的凭证类型")
+	}
+
+	cred.Name = form.Name
+	cred.Type = form.Type
+	cred.UserName = form.UserName
+	cred.Remark = form.Remark
+	if cred.Password, err = encryptSecret(password); err != nil {
+		return err
+	}
+	if cred.PrivateKey, err = encryptSecret(privateKey); err != nil {
+		return err
+	}
+	if cred.Passphrase, err = encryptSecret(passphrase); err != nil {
+		return err
+	}
+	cred.Certificate = certificate
+	return nil
+}
+
+func checkSSHCredentialName(name string, id uint) error {
+	var count int64
+	if err := common.DB.Model(&cmdb.SSHCredential{}).Where("name = ? AND id <> ?", name, id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != 0 {
+		return errors.New("凭证名称已存在")
+	}
+	return nil
+}
+
+// CreateSSHCredential 创建SSH凭证
+func CreateSSHCredential(form *request.SSHCredentialForm) (*cmdb.SSHCredential, error) {
+	if err := checkSSHCredentialName(form.Name, 0); err != nil {
+		return nil, err
+	}
+	cred := &cmdb.SSHCredential{}
+	if err := setSSHCredential(cred, form); err != nil {
+		return nil, err
+	}
+	if err := common.DB.Create(cred).Error; err != nil {
+		return nil, err
+	}
+	return cred, nil
+}
+
+// UpdateSSHCredential 更新SSH凭证, 对之后建立的会话生效
+func UpdateSSHCredential(form *request.SSHCredentialForm) error {
+	var cred cmdb.SSHCredential
+	if err := common.DB.First(&cred, form.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("凭证不存在")
+		}
+		return err
+	}
+	if err := checkSSHCredentialName(form.Name, cred.ID); err != nil {
+		return err
+	}
+	if err := setSSHCredential(&cred, form); err != nil {
+		return err
+	}
+	return common.DB.Model(&cred).Select("name", "type", "user_name", "password", "private_key",
+		"passphrase", "certificate", "fingerprint", "remark").Updates(&cred).Error
+}
+
+// DeleteSSHCredentials 批量删除SSH凭证, 仍分配给主机或分组的凭证不允许删除
+func DeleteSSHCredentials(ids []uint) error {
+	var hosts, groups int64
+	if err := common.DB.Model(&cmdb.VirtualMachine{}).Where("credential_id IN ?", ids).Count(&hosts).Error; err != nil {
+		return err
+	}
+	if err := common.DB.Model(&cmdb.TreeMenu{}).Where("credential_id IN ?", ids).Count(&groups).Error; err != nil {
+		return err
+	}
+	if hosts != 0 || groups != 0 {
+		return errors.New("凭证已分配给主机或主机分组, 请先解除分配")
+	}
+	return common.DB.Where("id IN ?", ids).Delete(&cmdb.SSHCredential{}).Error
+}
+
+// BindSSHCredential 为主机或主机分组分配SSH凭证
+func BindSSHCredential(form *request.SSHCredentialBind) error {
+	if len(form.HostIds) == 0 && len(form.GroupIds) == 0 {
+		return errors.New("请选择主机或主机分组")
+	}
+	var credentialId *uint
+	if form.CredentialId != 0 {
+		var cred cmdb.SSHCredential
+		if err := common.DB.First(&cred, form.CredentialId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("凭证不存在")
+			}
+			return err
+		}
+		credentialId = &cred.ID
+	}
+	return common.DB.Transaction(func(tx *gorm.DB) error {
+		if len(form.HostIds) > 0 {
+			if err := tx.Model(&cmdb.VirtualMachine{}).Where("id IN ?", form.HostIds).
+				Update("credential_id", credentialId).Error; err != nil {
+				return err
+			}
+		}
+		if len(form.GroupIds) > 0 {
+			if err := tx.Model(&cmdb.TreeMenu{}).Where("id IN ?", form.GroupIds).
+				Update("credential_id", credentialId).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// hostCredentialId 主机生效的凭证: 主机自身分配的凭证, 其次为所在分组及上级分组分配的凭证,
+// 主机属于多个分组时按分组Id顺序取第一个
+func hostCredentialId(host *cmdb.VirtualMachine) (*uint, error) {
+	if host.CredentialId != nil {
+		return host.CredentialId, nil
+	}
+	if len(host.Groups) == 0 {
+		return nil, nil
+	}
+	var menus []cmdb.TreeMenu
+	if err := common.DB.Select("id", "parent_id", "credential_id").Find(&menus).Error; err != nil {
+		return nil, err
+	}
+	return groupCredentialId(menus, host.Groups), nil
+}
+
+func groupCredentialId(menus []cmdb.TreeMenu, groups []*cmdb.TreeMenu) *uint {
+	tree := make(map[int]cmdb.TreeMenu, len(menus))
+	for _, m := range menus {
+		tree[m.ID] = m
+	}
+	ids := make([]int, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		// 限制层级, 避免错误的parent_id形成环
+		for depth := 0; depth < 32; depth++ {
+			menu, ok := tree[id]
+			if !ok {
+				break
+			}
+			if menu.CredentialId != nil {
+				return menu.CredentialId
+			}
+			id = int(menu.ParentId)
+		}
+	}
+	return nil
+}
+
+// GetHostSSHConfig 获取连接主机的SSH配置, 凭证优先级: 分配的凭证 > 主机自身的密码和私钥 > 全局配置.
+// 返回的凭证为明文, 只能在内存中使用
+func GetHostSSHConfig(host *cmdb.VirtualMachine) (websocket.Config, error) {
+	config := websocket.Config{
+		IpAddress: host.PrivateAddr,
+		Port:      host.Port,
+		UserName:  host.UserName,
+	}
+	credentialId, err := hostCredentialId(host)
+	if err != nil {
+		return config, err
+	}
+	if credentialId != nil {
+		var cred cmdb.SSHCredential
+		if err := common.DB.First(&cred, *credentialId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return config, errors.New("主机分配的SSH凭证不存在")
+			}
+			return config, err
+		}
+		if cred.UserName != "" {
+			config.UserName = cred.UserName
+		}
+		if config.Password, err = decryptSecret(cred.Password); err != nil {
+			return config, err
+		}
+		if config.PrivateKey, err = decryptSecret(cred.PrivateKey); err != nil {
+			return config, err
+		}
+		if config.KeyPassphrase, err = decryptSecret(cred.Passphrase); err != nil {
+			return config, err
+		}
+		config.Certificate = cred.Certificate
+	} else if host.Password != "" || host.PrivateKey != "" {
+		if config.Password, err = decryptSecret(host.Password); err != nil {
+			return config, err
+		}
+		if config.PrivateKey, err = decryptSecret(host.PrivateKey); err != nil {
+			return config, err
+		}
+	}
+
+	var globalConfig cmdb.SSHGlobalConfig
+	if err := common.DB.Limit(1).Find(&globalConfig).Error; err != nil {
+		return config, err
+	}
+	if config.Password == "" && config.PrivateKey == "" {
+		if config.Password, err = decryptSecret(globalConfig.Password); err != nil {
+			return config, err
+		}
+		if config.PrivateKey, err = decryptSecret(globalConfig.PrivateKey); err != nil {
+			return config, err
+		}
+	}
+	if config.UserName == "" {
+		config.UserName = globalConfig.UserName
+	}
+	if config.Port == "" {
+		config.Port = globalConfig.Port
+	}
+	if config.Port == "" {
+		config.Port = "22"
+	}
+	return config, nil
+}
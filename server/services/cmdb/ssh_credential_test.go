@@ -0,0 +1,92 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"kubespace/server/models/cmdb"
+	"kubespace/server/models/request"
+	"kubespace/server/pkg/utils"
+	"testing"
+)
+
+func TestGroupCredentialId(t *testing.T) {
+	id := func(v uint) *uint { return &v }
+	menus := []cmdb.TreeMenu{
+		{ID: 1, ParentId: 0, CredentialId: id(10)},
+		{ID: 2, ParentId: 1},
+		{ID: 3, ParentId: 2, CredentialId: id(30)},
+		{ID: 4, ParentId: 5}, // 上级分组不存在
+		{ID: 6, ParentId: 7},
+		{ID: 7, ParentId: 6}, // 错误数据形成环
+	}
+	cases := []struct {
+		groups []int
+		want   *uint
+	}{
+		{groups: []int{2}, want: id(10)},    // 继承上级分组
+		{groups: []int{3, 2}, want: id(10)}, // 多个分组按Id顺序
+		{groups: []int{3}, want: id(30)},
+		{groups: []int{4, 6}, want: nil},
+	}
+	for i, c := range cases {
+		groups := make([]*cmdb.TreeMenu, 0, len(c.groups))
+		for _, g := range c.groups {
+			groups = append(groups, &cmdb.TreeMenu{ID: g})
+		}
+		got := groupCredentialId(menus, groups)
+		if (got == nil) != (c.want == nil) || (got != nil && *got != *c.want) {
+			t.Errorf("case %d: want %v, got %v", i, c.want, got)
+		}
+	}
+}
+
+func TestSSHCredentialSecret(t *testing.T) {
+	if err := utils.SetSecretKey("test-secret-key-0123456789"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 旧版本AES-CBC加密的密文使用当前密钥重新加密
+	password, empty := "9d940f25497bec844cfb31044d9c4802", ""
+	changed, err := reencryptSecrets(&password, &empty)
+	if err != nil || !changed || empty != "" || utils.IsLegacySecret(password) {
+		t.Fatalf("reencrypt: changed %v, err %v, %q", changed, err, password)
+	}
+	if plain, _ := decryptSecret(password); plain != "legacy-secret" {
+		t.Errorf("want legacy-secret, got %q", plain)
+	}
+	if changed, _ := reencryptSecrets(&password); changed {
+		t.Error("current secret should not be reencrypted")
+	}
+
+	// 更新时沿用原密码, 原密码无法解密时返回错误而不是保存空密码
+	cred := &cmdb.SSHCredential{Password: password}
+	form := &request.SSHCredentialForm{Name: "root", Type: cmdb.SSHCredentialPassword}
+	if err := setSSHCredential(cred, form); err != nil {
+		t.Fatal(err)
+	}
+	if plain, _ := decryptSecret(cred.Password); plain != "legacy-secret" {
+		t.Errorf("want legacy-secret, got %q", plain)
+	}
+	cred.Password = "gcm:00"
+	if err := setSSHCredential(cred, form); err == nil {
+		t.Error("undecryptable password should fail")
+	}
+	form.Password = "new-password"
+	if err := setSSHCredential(cred, form); err != nil {
+		t.Errorf("new password should replace undecryptable one: %v", err)
+	}
+}
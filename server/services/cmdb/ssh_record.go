@@ -0,0 +1,142 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"io/ioutil"
+	"kubespace/server/common"
+	"kubespace/server/models"
+	"kubespace/server/models/cmdb"
+	"kubespace/server/models/request"
+	"kubespace/server/pkg/recordstore"
+	"kubespace/server/pkg/utils"
+	"time"
+)
+
+// ListSSHRecord SSH会话录像列表, 按接入时间倒序, 不返回录像内容
+func ListSSHRecord(query request.SSHRecordQuery) (err error, list []cmdb.SSHRecord, total int64) {
+	limit := query.PageSize
+	offset := query.PageSize * (query.Page - 1)
+
+	db := common.DB.Model(&cmdb.SSHRecord{}).Omit("records")
+	if query.UserName != "" {
+		db = db.Where("user_name = ?", query.UserName)
+	}
+	if query.Operator != "" {
+		db = db.Where("operator = ?", query.Operator)
+	}
+	if query.HostId != 0 {
+		db = db.Where("host_id = ?", query.HostId)
+	}
+	if query.HostName != "" {
+		db = db.Where("host_name LIKE ?", "%"+query.HostName+"%")
+	}
+	if db, err = whereTimeRange(db, "connect_time", query.StartTime, query.EndTime); err != nil {
+		return err, nil, 0
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return err, nil, 0
+	}
+	err = db.Order("connect_time desc").Limit(limit).Offset(offset).Find(&list).Error
+	return err, list, total
+}
+
+// ListSSHCommand SSH命令审计列表, 按执行时间倒序
+func ListSSHCommand(query request.SSHCommandQuery) (err error, list []cmdb.SSHCommand, total int64) {
+	limit := query.PageSize
+	offset := query.PageSize * (query.Page - 1)
+
+	db := common.DB.Model(&cmdb.SSHCommand{})
+	if query.RecordId != 0 {
+		db = db.Where("record_id = ?", query.RecordId)
+	}
+	if query.UserName != "" {
+		db = db.Where("user_name = ?", query.UserName)
+	}
+	if query.Operator != "" {
+		db = db.Where("operator = ?", query.Operator)
+	}
+	if query.HostId != 0 {
+		db = db.Where("host_id = ?", query.HostId)
+	}
+	if query.Command != "" {
+		db = db.Where("command LIKE ?", "%"+query.Command+"%")
+	}
+	if db, err = whereTimeRange(db, "exec_time", query.StartTime, query.EndTime); err != nil {
+		return err, nil, 0
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return err, nil, 0
+	}
+	err = db.Order("exec_time desc").Limit(limit).Offset(offset).Find(&list).Error
+	return err, list, total
+}
+
+// whereTimeRange 按时间范围过滤, 时间格式为2006-01-02 15:04:05
+func whereTimeRange(db *gorm.DB, column, start, end string) (*gorm.DB, error) {
+	if start != "" {
+		t, err := time.ParseInLocation(models.SecLocalTimeFormat, start, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("开始时间格式错误: %v", err)
+		}
+		db = db.Where(column+" >= ?", t)
+	}
+	if end != "" {
+		t, err := time.ParseInLocation(models.SecLocalTimeFormat, end, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("结束时间格式错误: %v", err)
+		}
+		db = db.Where(column+" <= ?", t)
+	}
+	return db, nil
+}
+
+// GetSSHRecordCast 读取SSH会话录像并解压为asciicast v2内容
+func GetSSHRecordCast(id uint) (*cmdb.SSHRecord, []byte, error) {
+	var record cmdb.SSHRecord
+	if err := common.DB.First(&record, id).Error; err != nil {
+		return nil, nil, err
+	}
+	data := record.Records
+	if record.StoragePath != "" {
+		store, err := recordstore.Default()
+		if err != nil {
+			return nil, nil, err
+		}
+		if store == nil {
+			return nil, nil, fmt.Errorf("录像存储在%s中, 当前未配置录像存储", record.StoragePath)
+		}
+		r, err := store.Open(record.StoragePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer r.Close()
+		if data, err = ioutil.ReadAll(r); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("录像内容为空")
+	}
+	cast, err := utils.ZlibUnCompress(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解压录像失败: %v", err)
+	}
+	return &record, cast, nil
+}
@@ -0,0 +1,203 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"kubespace/server/common"
+	"kubespace/server/models"
+	"kubespace/server/models/cmdb"
+	"kubespace/server/models/request"
+	"kubespace/server/pkg/websocket"
+	"regexp"
+)
+
+// ListSSHCommandRule 命令黑名单规则列表
+func ListSSHCommandRule(query request.PageInfo) (err error, list []cmdb.SSHCommandRule, total int64) {
+	limit := query.PageSize
+	offset := query.PageSize * (query.Page - 1)
+
+	db := common.DB.Model(&cmdb.SSHCommandRule{})
+	if err = db.Count(&total).Error; err != nil {
+		return err, nil, 0
+	}
+	err = db.Preload("Groups").Preload("Roles").Order("id desc").Limit(limit).Offset(offset).Find(&list).Error
+	return err, list, total
+}
+
+// setSSHCommandRule 校验正则并填充规则的分组和角色
+func setSSHCommandRule(rule *cmdb.SSHCommandRule, form *request.SSHCommandRuleForm) error {
+	if _, err := regexp.Compile(form.Pattern); err != nil {
+		return fmt.Errorf("正则表达式不合法: %v", err)
+	}
+	rule.Name = form.Name
+	rule.Pattern = form.Pattern
+	rule.Remark = form.Remark
+	if form.Enable != nil {
+		rule.Enable = *form.Enable
+	}
+	rule.Groups = nil
+	if len(form.GroupIds) > 0 {
+		if err := common.DB.Where("id IN ?", form.GroupIds).Find(&rule.Groups).Error; err != nil {
+			return err
+		}
+		if len(rule.Groups) != len(form.GroupIds) {
+			return errors.New("主机分组不存在")
+		}
+	}
+	rule.Roles = nil
+	if len(form.RoleIds) > 0 {
+		if err := common.DB.Where("id IN ?", form.RoleIds).Find(&rule.Roles).Error; err != nil {
+			return err
+		}
+		if len(rule.Roles) != len(form.RoleIds) {
+			return errors.New("角色不存在")
+		}
+	}
+	return nil
+}
+
+// CreateSSHCommandRule 创建命令黑名单规则
+func CreateSSHCommandRule(form *request.SSHCommandRuleForm) (*cmdb.SSHCommandRule, error) {
+	rule := &cmdb.SSHCommandRule{Enable: true}
+	if err := setSSHCommandRule(rule, form); err != nil {
+		return nil, err
+	}
+	if err := common.DB.Create(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateSSHCommandRule 更新命令黑名单规则, 分组和角色整体替换, 对之后建立的会话生效
+func UpdateSSHCommandRule(form *request.SSHCommandRuleForm) error {
+	var rule cmdb.SSHCommandRule
+	if err := common.DB.First(&rule, form.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("规则不存在")
+		}
+		return err
+	}
+	if err := setSSHCommandRule(&rule, form); err != nil {
+		return err
+	}
+	return common.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&rule).Select("name", "pattern", "enable", "remark").Updates(&rule).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&rule).Association("Groups").Replace(rule.Groups); err != nil {
+			return err
+		}
+		return tx.Model(&rule).Association("Roles").Replace(rule.Roles)
+	})
+}
+
+// DeleteSSHCommandRules 批量删除命令黑名单规则
+func DeleteSSHCommandRules(ids []uint) error {
+	var rules []cmdb.SSHCommandRule
+	if err := common.DB.Where("id IN ?", ids).Find(&rules).Error; err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return common.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range rules {
+			if err := tx.Model(&rules[i]).Association("Groups").Clear(); err != nil {
+				return err
+			}
+			if err := tx.Model(&rules[i]).Association("Roles").Clear(); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&rules).Error
+	})
+}
+
+// GetSessionCommandRules 获取对角色和主机分组生效的黑名单规则, 建立SSH会话时加载
+func GetSessionCommandRules(role string, groups []*cmdb.TreeMenu) ([]websocket.CommandRule, error) {
+	var rules []cmdb.SSHCommandRule
+	if err := common.DB.Preload("Groups").Preload("Roles").Where("enable = ?", true).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	result := make([]websocket.CommandRule, 0)
+	for _, rule := range rules {
+		if !ruleMatchRole(rule.Roles, role) || !ruleMatchGroup(rule.Groups, groups) {
+			continue
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			common.LOG.Warn(fmt.Sprintf("命令黑名单规则%s的正则表达式不合法: %v", rule.Name, err))
+			continue
+		}
+		result = append(result, websocket.CommandRule{ID: rule.ID, Name: rule.Name, Pattern: pattern})
+	}
+	return result, nil
+}
+
+func ruleMatchRole(roles []models.Role, role string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		if r.Name == role {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleMatchGroup(ruleGroups, hostGroups []*cmdb.TreeMenu) bool {
+	if len(ruleGroups) == 0 {
+		return true
+	}
+	for _, rg := range ruleGroups {
+		for _, hg := range hostGroups {
+			if rg.ID == hg.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ListSSHCommandBlock 命令拦截记录, 按拦截时间倒序
+func ListSSHCommandBlock(query request.SSHCommandBlockQuery) (err error, list []cmdb.SSHCommandBlock, total int64) {
+	limit := query.PageSize
+	offset := query.PageSize * (query.Page - 1)
+
+	db := common.DB.Model(&cmdb.SSHCommandBlock{})
+	if query.Operator != "" {
+		db = db.Where("operator = ?", query.Operator)
+	}
+	if query.HostId != 0 {
+		db = db.Where("host_id = ?", query.HostId)
+	}
+	if query.RuleId != 0 {
+		db = db.Where("rule_id = ?", query.RuleId)
+	}
+	if db, err = whereTimeRange(db, "block_time", query.StartTime, query.EndTime); err != nil {
+		return err, nil, 0
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return err, nil, 0
+	}
+	err = db.Order("block_time desc").Limit(limit).Offset(offset).Find(&list).Error
+	return err, list, total
+}
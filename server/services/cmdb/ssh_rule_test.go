@@ -0,0 +1,118 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"io"
+	"kubespace/server/common"
+	"kubespace/server/models/cmdb"
+	"kubespace/server/models/request"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var insertColumns = regexp.MustCompile("\\(((?:`\\w+`,?)+)\\) VALUES")
+
+// ruleTable 模拟保存命令黑名单规则的表, 与已部署的表结构一致, enable列的默认值为true
+type ruleTable struct {
+	row map[string]driver.Value
+}
+
+func (f *ruleTable) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *ruleTable) Driver() driver.Driver                        { return nil }
+func (f *ruleTable) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (f *ruleTable) Close() error              { return nil }
+func (f *ruleTable) Begin() (driver.Tx, error) { return f, nil }
+func (f *ruleTable) Commit() error             { return nil }
+func (f *ruleTable) Rollback() error           { return nil }
+
+func (f *ruleTable) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	m := insertColumns.FindStringSubmatch(query)
+	if m == nil {
+		return nil, errors.New("unexpected exec: " + query)
+	}
+	f.row = map[string]driver.Value{"id": int64(1), "enable": true}
+	for i, column := range strings.Split(m[1], ",") {
+		f.row[strings.Trim(column, "`")] = args[i].Value
+	}
+	return ruleResult{}, nil
+}
+
+type ruleResult struct{}
+
+func (ruleResult) LastInsertId() (int64, error) { return 1, nil }
+func (ruleResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (f *ruleTable) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	rows := &ruleRows{}
+	if f.row != nil {
+		for column, value := range f.row {
+			rows.columns = append(rows.columns, column)
+			rows.values = append(rows.values, value)
+		}
+	}
+	return rows, nil
+}
+
+type ruleRows struct {
+	columns []string
+	values  []driver.Value
+	read    bool
+}
+
+func (r *ruleRows) Columns() []string { return r.columns }
+func (r *ruleRows) Close() error      { return nil }
+func (r *ruleRows) Next(dest []driver.Value) error {
+	if r.read || r.values == nil {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.values)
+	return nil
+}
+
+func TestCreateDisabledSSHCommandRule(t *testing.T) {
+	table := &ruleTable{}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(table), SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	common.DB = db
+
+	enable := false
+	if _, err := CreateSSHCommandRule(&request.SSHCommandRuleForm{Name: "rm", Pattern: `^rm\s`, Enable: &enable}); err != nil {
+		t.Fatal(err)
+	}
+	var rule cmdb.SSHCommandRule
+	if err := common.DB.First(&rule, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rule.Enable {
+		t.Error("rule created as disabled is stored enabled")
+	}
+}
@@ -18,11 +18,42 @@ package tasks
 
 import (
 	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
 	"kubespace/server/common"
+	"kubespace/server/inner/cloud/cloudsync"
 	"kubespace/server/models/cmdb"
 	"log"
+	"time"
 )
 
+const (
+	// defaultCloudSyncCron 账号和配置文件都未指定周期时的默认同步周期
+	defaultCloudSyncCron = "00 */2 * * *"
+	// reconcileInterval 定时对账云账号调度, 账号变更时通过NotifyCloudAccountChanged立即对账
+	reconcileInterval = time.Minute
+)
+
+var accountChanged = make(chan struct{}, 1)
+
+// NotifyCloudAccountChanged 云账号新增、修改、删除后调用, 触发调度对账
+func NotifyCloudAccountChanged() {
+	select {
+	case accountChanged <- struct{}{}:
+	default:
+	}
+}
+
+// scheduleRegistry 调度注册接口, 由asynq.Scheduler实现
+type scheduleRegistry interface {
+	Register(cronspec string, task *asynq.Task, opts ...asynq.Option) (string, error)
+	Unregister(entryID string) error
+}
+
+type accountEntry struct {
+	entryID  string
+	cronSpec string
+}
+
 func TaskBeta() {
 
 	config := common.CONFIG
@@ -35,18 +66,77 @@ func TaskBeta() {
 			DB:       config.Redis.DB,
 		}, nil)
 
-	// 每隔5分钟同步一次
-	var account cmdb.CloudPlatform
-	common.DB.Table("cloud_platform").Where("enable != ? and type = ?", 0, "aliyun").Find(&account)
-	syncResource := NewAliCloudTask(&account)
-	entryID, err := scheduler.Register(config.Crontab.AliYun, syncResource)
+	if err := scheduler.Start(); err != nil {
+		common.LOG.Error("启动任务调度失败", zap.Any("err", err))
+		return
+	}
+
+	// 每个启用的云账号一个调度
+	entries := make(map[int]accountEntry)
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+	for {
+		var accounts []cmdb.CloudPlatform
+		if err := common.DB.Where("enable = ?", true).Find(&accounts).Error; err != nil {
+			common.LOG.Error("查询云账号失败", zap.Any("err", err))
+		} else {
+			reconcileAccountSchedules(scheduler, entries, accounts)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-accountChanged:
+		}
+	}
+}
+
+// reconcileAccountSchedules 按启用的云账号注册调度, 移除已禁用、已删除账号的调度, 周期变化时重新注册
+func reconcileAccountSchedules(registry scheduleRegistry, entries map[int]accountEntry, accounts []cmdb.CloudPlatform) {
+	wanted := make(map[int]string, len(accounts))
+	for i := range accounts {
+		wanted[accounts[i].ID] = accountCronSpec(&accounts[i])
+	}
 
-	if err != nil {
-		log.Fatal(err)
+	for id, entry := range entries {
+		if spec, ok := wanted[id]; ok && spec == entry.cronSpec {
+			continue
+		}
+		if err := registry.Unregister(entry.entryID); err != nil {
+			common.LOG.Error("移除云账号同步调度失败", zap.Any("err", err))
+			continue
+		}
+		log.Printf("unregistered an entry: %q\n", entry.entryID)
+		delete(entries, id)
 	}
-	log.Printf("registered an entry: %q\n", entryID)
 
-	if err := scheduler.Run(); err != nil {
-		log.Fatal(err)
+	for id, spec := range wanted {
+		if _, ok := entries[id]; ok {
+			continue
+		}
+		entryID, err := registry.Register(spec, NewCloudAccountTask(id, cloudsync.TriggerSchedule), cloudSyncOptions()...)
+		if err != nil {
+			common.LOG.Error("注册云账号同步调度失败", zap.Any("platform", id), zap.Any("err", err))
+			continue
+		}
+		log.Printf("registered an entry: %q\n", entryID)
+		entries[id] = accountEntry{entryID: entryID, cronSpec: spec}
+	}
+}
+
+// accountCronSpec 账号的同步周期, 优先使用账号配置, 其次是配置文件中对应云厂商的周期
+func accountCronSpec(account *cmdb.CloudPlatform) string {
+	if account.SyncCron != "" {
+		return account.SyncCron
+	}
+	var spec string
+	switch account.Type {
+	case cmdb.AliYun:
+		spec = common.CONFIG.Crontab.AliYun
+	case cmdb.Tencent:
+		spec = common.CONFIG.Crontab.Tencent
+	}
+	if spec == "" {
+		spec = defaultCloudSyncCron
 	}
+	return spec
 }
@@ -0,0 +1,94 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"kubespace/server/models/cmdb"
+	"testing"
+)
+
+type fakeRegistry struct {
+	seq     int
+	entries map[string]string // entryID -> cronspec
+	tasks   map[string]CloudAccountPayload
+}
+
+func (f *fakeRegistry) Register(cronspec string, task *asynq.Task, opts ...asynq.Option) (string, error) {
+	f.seq++
+	entryID := fmt.Sprintf("entry-%d", f.seq)
+	var p CloudAccountPayload
+	if err := json.Unmarshal(task.Payload(), &p); err != nil {
+		return "", err
+	}
+	f.entries[entryID] = cronspec
+	f.tasks[entryID] = p
+	return entryID, nil
+}
+
+func (f *fakeRegistry) Unregister(entryID string) error {
+	delete(f.entries, entryID)
+	delete(f.tasks, entryID)
+	return nil
+}
+
+func TestReconcileAccountSchedules(t *testing.T) {
+	registry := &fakeRegistry{entries: map[string]string{}, tasks: map[string]CloudAccountPayload{}}
+	entries := make(map[int]accountEntry)
+
+	reconcileAccountSchedules(registry, entries, []cmdb.CloudPlatform{
+		{ID: 1, Type: cmdb.AliYun},
+		{ID: 2, Type: cmdb.Tencent, SyncCron: "*/30 * * * *"},
+	})
+	if len(registry.entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", registry.entries)
+	}
+	if spec := registry.entries[entries[1].entryID]; spec != defaultCloudSyncCron {
+		t.Fatalf("expected default cron for account 1, got %q", spec)
+	}
+	if p := registry.tasks[entries[2].entryID]; p.PlatformId != 2 || p.Trigger != "schedule" {
+		t.Fatalf("unexpected task payload: %#v", p)
+	}
+
+	// 账号1禁用、账号2修改周期、新增账号3
+	first := entries[2].entryID
+	reconcileAccountSchedules(registry, entries, []cmdb.CloudPlatform{
+		{ID: 2, Type: cmdb.Tencent, SyncCron: "00 * * * *"},
+		{ID: 3, Type: cmdb.AWS},
+	})
+	if len(registry.entries) != 2 || len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", registry.entries)
+	}
+	if _, ok := entries[1]; ok {
+		t.Fatal("disabled account should be unregistered")
+	}
+	if entries[2].entryID == first || registry.entries[entries[2].entryID] != "00 * * * *" {
+		t.Fatalf("account 2 should be re-registered with new cron, got %#v", entries[2])
+	}
+
+	// 没有变化时不重复注册
+	seq := registry.seq
+	reconcileAccountSchedules(registry, entries, []cmdb.CloudPlatform{
+		{ID: 2, Type: cmdb.Tencent, SyncCron: "00 * * * *"},
+		{ID: 3, Type: cmdb.AWS},
+	})
+	if registry.seq != seq {
+		t.Fatal("unchanged accounts should not be re-registered")
+	}
+}
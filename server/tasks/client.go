@@ -0,0 +1,64 @@
+/*
+
+
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"errors"
+	"github.com/hibiken/asynq"
+	"kubespace/server/common"
+	"sync"
+	"time"
+)
+
+// ErrTaskQueued 同一云账号的同步任务还未执行完
+var ErrTaskQueued = errors.New("该云账号已有同步任务在队列中")
+
+var (
+	client     *asynq.Client
+	clientOnce sync.Once
+)
+
+func getClient() *asynq.Client {
+	clientOnce.Do(func() {
+		config := common.CONFIG
+		client = asynq.NewClient(asynq.RedisClientOpt{
+			Addr:     config.Redis.Host,
+			Username: config.Redis.UserName,
+			Password: config.Redis.PassWord,
+			DB:       config.Redis.DB,
+		})
+	})
+	return client
+}
+
+// cloudSyncOptions 同步失败时记录在同步记录中, 不重试; 任务执行完之前同一账号不会重复入队
+func cloudSyncOptions() []asynq.Option {
+	return []asynq.Option{
+		asynq.MaxRetry(0),
+		asynq.Timeout(time.Hour),
+		asynq.Unique(time.Hour),
+	}
+}
+
+// EnqueueCloudSync 投递云账号同步任务
+func EnqueueCloudSync(platformId int, trigger string) error {
+	_, err := getClient().Enqueue(NewCloudAccountTask(platformId, trigger), cloudSyncOptions()...)
+	if errors.Is(err, asynq.ErrDuplicateTask) {
+		return ErrTaskQueued
+	}
+	return err
+}
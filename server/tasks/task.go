@@ -19,51 +19,65 @@ package tasks
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+	"kubespace/server/common"
 	"kubespace/server/inner/cloud/cloudsync"
-	"kubespace/server/inner/cloud/cloudvendor"
 	"kubespace/server/models/cmdb"
 	"log"
 )
 
 const (
-	SyncAliYunCloud  = "cmdb:aliyun"
-	SyncTencentCloud = "cmdb:tencent"
+	SyncCloudAccount = "cmdb:account"
 )
 
-// NewAliCloudTask 同步阿里云资产同步任务
-func NewAliCloudTask(conf *cmdb.CloudPlatform) *asynq.Task {
-	payload, err := json.Marshal(conf)
+// CloudAccountPayload 云账号同步任务参数, 只传递账号ID, 执行时从数据库读取最新的凭证
+type CloudAccountPayload struct {
+	PlatformId int    `json:"platform_id"`
+	Trigger    string `json:"trigger"`
+}
+
+// NewCloudAccountTask 云账号资产同步任务
+func NewCloudAccountTask(platformId int, trigger string) *asynq.Task {
+	payload, err := json.Marshal(CloudAccountPayload{PlatformId: platformId, Trigger: trigger})
 	if err != nil {
 		panic(err)
 	}
-	return asynq.NewTask(SyncAliYunCloud, payload)
+	return asynq.NewTask(SyncCloudAccount, payload)
 }
 
-func HandleAliCloudTask(ctx context.Context, t *asynq.Task) error {
+func HandleCloudAccountTask(ctx context.Context, t *asynq.Task) error {
 
-	var a cmdb.CloudPlatform
-	if err := json.Unmarshal(t.Payload(), &a); err != nil {
+	var p CloudAccountPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
 		return err
 	}
 
-	_, err := cloudvendor.GetVendorClient(&a)
-	if err != nil {
-		log.Fatalf("AccountVerify GetVendorClient failed，%v", err)
+	var account cmdb.CloudPlatform
+	if err := common.DB.Where("id = ?", p.PlatformId).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 账号已删除, 等待调度对账时移除
+			log.Printf("cloud account %d not found, skip", p.PlatformId)
+			return nil
+		}
 		return err
 	}
+	if !account.Enable {
+		log.Printf("cloud account %d is disabled, skip", p.PlatformId)
+		return nil
+	}
 
-	cloudsync.SyncAliYunHost(&a)
+	record, err := cloudsync.SyncAccount(&account, p.Trigger)
+	if err != nil {
+		if errors.Is(err, cloudsync.ErrSyncInProgress) {
+			log.Printf("cloud account %d is syncing, skip", p.PlatformId)
+			return nil
+		}
+		return err
+	}
 
-	log.Printf("Aliyun Cloud assets are successfully synchronized...")
+	log.Printf("%s cloud account %d synchronized: %s, added %d, updated %d, removed %d, errors %d",
+		account.Type, account.ID, record.Status, record.Added, record.Updated, record.Removed, record.Errors)
 	return nil
 }
-
-// NewTencentCloudTask 腾讯云资产同步任务
-func NewTencentCloudTask() *asynq.Task {
-	//payload, err := json.Marshal(&AK{})
-	//if err != nil {
-	//	panic(err)
-	//}
-	return asynq.NewTask(SyncTencentCloud, nil)
-}
@@ -18,6 +18,7 @@ package tasks
 
 import (
 	"context"
+	"fmt"
 	"kubespace/server/common"
 	"time"
 
@@ -54,11 +55,11 @@ func TaskWorker() {
 	mux := asynq.NewServeMux()
 	mux.Use(loggingMiddleware)
 	//
-	mux.HandleFunc(SyncAliYunCloud, HandleAliCloudTask)
+	mux.HandleFunc(SyncCloudAccount, HandleCloudAccountTask)
 
 	// start server
 	if err := srv.Run(mux); err != nil {
-		log.Fatalf("could not start server: %v", err)
+		common.LOG.Error(fmt.Sprintf("could not start server: %v", err))
 	}
 
 	// Wait for termination signal.